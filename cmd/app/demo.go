@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/Coiiap5e/photographer/internal/model"
+	"github.com/Coiiap5e/photographer/internal/repository"
+)
+
+// seedDemo fills the repositories of the demo mode with sample clients and
+// shoots around today, some of them paid
+func seedDemo(ctx context.Context, clientRepo repository.Client, shootRepo repository.Shoot,
+	paymentRepo repository.Payment) error {
+	clients := []*model.Client{
+		{FirstName: "Анна", LastName: "Смирнова", Phone: "+7(916)123-45-67",
+			SocialNetworkUrl: "https://vk.com/anna.smirnova"},
+		{FirstName: "Иван", LastName: "Петров", Phone: "+7(903)555-12-34"},
+		{FirstName: "Мария", LastName: "Кузнецова", Phone: "+7(925)700-80-90",
+			SocialNetworkUrl: "https://t.me/mkuznetsova"},
+	}
+	if err := clientRepo.AddClients(ctx, clients); err != nil {
+		return err
+	}
+
+	today := time.Now()
+	clock := func(hour, minute int) time.Time {
+		return time.Date(0, 0, 0, hour, minute, 0, 0, time.UTC)
+	}
+
+	shoots := []*model.Shoot{
+		{ClientId: clients[0].Id, ShootDate: today.AddDate(0, 0, -7), StartTime: clock(11, 0), EndTime: clock(13, 0),
+			ShootPrice: model.NewMoney(1500000, "RUB"), ShootLocation: "Парк Горького", ShootType: "love story"},
+		{ClientId: clients[1].Id, ShootDate: today.AddDate(0, 0, 3), StartTime: clock(15, 0), EndTime: clock(16, 30),
+			ShootPrice: model.NewMoney(800000, "RUB"), ShootLocation: "Студия на Таганке", ShootType: "портрет",
+			Notes: "нужен белый фон"},
+		{ClientId: clients[2].Id, ShootDate: today.AddDate(0, 0, 10), StartTime: clock(10, 0), EndTime: clock(18, 0),
+			ShootPrice: model.NewMoney(6000000, "RUB"), ShootLocation: "Усадьба Кусково", ShootType: "свадьба"},
+		{ClientId: clients[0].Id, ShootDate: today.AddDate(0, 0, 24), StartTime: clock(12, 0), EndTime: clock(13, 0),
+			ShootPrice: model.NewMoney(50000, "EUR"), ShootLocation: "Патриаршие пруды", ShootType: "семейная"},
+	}
+	for _, shoot := range shoots {
+		if err := shootRepo.AddShoot(ctx, shoot); err != nil {
+			return err
+		}
+	}
+
+	payments := []*model.Payment{
+		{ShootId: shoots[0].Id, Kind: model.PaymentDeposit, Amount: model.NewMoney(500000, "RUB"),
+			PaidAt: today.AddDate(0, 0, -20)},
+		{ShootId: shoots[0].Id, Kind: model.PaymentFinal, Amount: model.NewMoney(1000000, "RUB"),
+			PaidAt: today.AddDate(0, 0, -7)},
+		{ShootId: shoots[2].Id, Kind: model.PaymentDeposit, Amount: model.NewMoney(2000000, "RUB"),
+			PaidAt: today.AddDate(0, 0, -2), Notes: "перевод"},
+	}
+	for _, payment := range payments {
+		if err := paymentRepo.AddPayment(ctx, payment); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -2,16 +2,23 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"os"
 	"os/signal"
 	"syscall"
 
 	cliapp "github.com/Coiiap5e/photographer/internal/app"
+	"github.com/Coiiap5e/photographer/internal/audit"
+	"github.com/Coiiap5e/photographer/internal/cli"
 	"github.com/Coiiap5e/photographer/internal/config"
 	"github.com/Coiiap5e/photographer/internal/database"
+	"github.com/Coiiap5e/photographer/internal/errors"
+	"github.com/Coiiap5e/photographer/internal/migrate"
 	"github.com/Coiiap5e/photographer/internal/repository"
 	"github.com/Coiiap5e/photographer/internal/service"
+	"github.com/Coiiap5e/photographer/internal/tui"
+	"github.com/Coiiap5e/photographer/internal/utils"
 )
 
 func main() {
@@ -24,22 +31,142 @@ func main() {
 	done := make(chan bool, 1)
 	signal.Notify(signalChan, syscall.SIGINT, syscall.SIGTERM)
 
-	dbConfig, err := config.LoadDBConfig()
+	args := os.Args[1:]
+	if len(args) > 0 && (args[0] == "help" || args[0] == "-h" || args[0] == "--help") {
+		fmt.Println(cli.Usage())
+		return
+	}
+
+	// --demo runs on sample data in memory, without a database, --tui shows
+	// the full-screen interface instead of the menu
+	var demo, fullScreen bool
+	for len(args) > 0 && (args[0] == "--demo" || args[0] == "--tui") {
+		demo = demo || args[0] == "--demo"
+		fullScreen = fullScreen || args[0] == "--tui"
+		args = args[1:]
+	}
+
+	scheduleConfig, err := config.LoadScheduleConfig()
 	if err != nil {
-		log.Fatal("configuration error: ", err)
+		exit(len(args) > 0, fmt.Errorf("configuration error: %w", err))
 	}
 
-	db, err := database.NewClient(ctx, dbConfig)
+	moneyConfig, err := config.LoadMoneyConfig()
 	if err != nil {
-		log.Fatal(err)
+		exit(len(args) > 0, fmt.Errorf("configuration error: %w", err))
+	}
+
+	trashConfig, err := config.LoadTrashConfig()
+	if err != nil {
+		exit(len(args) > 0, fmt.Errorf("configuration error: %w", err))
 	}
-	defer db.Close()
 
-	clientRepo := repository.NewClient(db)
-	shootRepo := repository.NewShoot(db)
+	ctx = audit.WithActor(ctx, config.LoadAuditConfig().Actor)
+
+	var transactor database.Transactor
+	var clientRepo repository.Client
+	var shootRepo repository.Shoot
+	var paymentRepo repository.Payment
+	var auditRepo repository.Audit
+
+	if demo {
+		if len(args) > 0 && args[0] == "migrate" {
+			exit(true, errors.New(errors.ErrCodeInvalidInput, "migrate works on the database, not in demo mode"))
+		}
+
+		store := repository.NewMemoryStore()
+		transactor = store
+		clientRepo = repository.NewMemoryClient(store)
+		shootRepo = repository.NewMemoryShoot(store)
+		paymentRepo = repository.NewMemoryPayment(store)
+		auditRepo = repository.NewMemoryAudit(store)
+
+		if err := seedDemo(ctx, clientRepo, shootRepo, paymentRepo); err != nil {
+			exit(len(args) > 0, fmt.Errorf("failed to fill demo data: %w", err))
+		}
+	} else {
+		dbConfig, err := config.LoadDBConfig()
+		if err != nil {
+			exit(len(args) > 0, fmt.Errorf("configuration error: %w", err))
+		}
+
+		var migrator *migrate.Migrator
+		if dbConfig.Driver == config.DriverSQLite {
+			db, err := database.NewSQLite(ctx, dbConfig.Path)
+			if err != nil {
+				exit(len(args) > 0, err)
+			}
+			defer db.Close()
+
+			if migrator, err = migrate.NewSQLite(db); err != nil {
+				exit(len(args) > 0, err)
+			}
+
+			transactor = db
+			clientRepo = repository.NewSQLiteClient(db)
+			shootRepo = repository.NewSQLiteShoot(db)
+			paymentRepo = repository.NewSQLitePayment(db)
+			auditRepo = repository.NewSQLiteAudit(db)
+		} else {
+			db, err := database.NewClient(ctx, dbConfig)
+			if err != nil {
+				exit(len(args) > 0, err)
+			}
+			defer db.Close()
+
+			if migrator, err = migrate.New(db); err != nil {
+				exit(len(args) > 0, err)
+			}
+
+			transactor = db
+			clientRepo = repository.NewClient(db)
+			shootRepo = repository.NewShoot(db)
+			paymentRepo = repository.NewPayment(db)
+			auditRepo = repository.NewAudit(db)
+		}
+
+		if len(args) > 0 && args[0] == "migrate" {
+			if err := runMigrate(ctx, migrator, args[1:]); err != nil {
+				exit(true, err)
+			}
+			return
+		}
+
+		if dbConfig.AutoMigrate {
+			if err := migrator.Up(ctx); err != nil {
+				exit(len(args) > 0, err)
+			}
+		}
+	}
 
 	clientService := service.NewClient(clientRepo)
-	shootService := service.NewShoot(shootRepo, clientRepo)
+	shootService := service.NewShoot(shootRepo, clientRepo, scheduleConfig, moneyConfig)
+	paymentService := service.NewPayment(transactor, paymentRepo)
+	trashService := service.NewTrash(transactor, clientRepo, shootRepo, trashConfig)
+	auditService := service.NewAudit(auditRepo)
+
+	if len(args) > 0 && args[0] == "serve" {
+		if err := runServe(ctx, args[1:], signalChan, clientService, shootService); err != nil {
+			exit(true, err)
+		}
+		return
+	}
+
+	if len(args) > 0 {
+		if err := cli.New(clientService, shootService, trashService, auditService, os.Stdout).Run(ctx, args); err != nil {
+			exit(true, err)
+		}
+		return
+	}
+
+	if fullScreen {
+		// the terminal is in raw mode, so Ctrl+C is read as a key and the UI
+		// restores the terminal before returning
+		if err := tui.New(clientService, shootService).Run(ctx, os.Stdin, os.Stdout); err != nil {
+			exit(false, err)
+		}
+		return
+	}
 
 	go func() {
 		sig := <-signalChan
@@ -48,7 +175,8 @@ func main() {
 		done <- true
 	}()
 
-	app := cliapp.NewApp(clientService, shootService)
+	app := cliapp.NewApp(clientService, shootService, paymentService, trashService, auditService,
+		utils.NewPrompter(os.Stdin, os.Stdout))
 	go func() {
 		app.RunMenu(ctx)
 		done <- true
@@ -56,3 +184,14 @@ func main() {
 
 	<-done
 }
+
+// exit reports err and terminates. Subcommands print to stderr and use an
+// exit code derived from the error code so scripts can react to failures.
+func exit(subcommand bool, err error) {
+	if !subcommand {
+		log.Fatal(err)
+	}
+
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(cli.ExitCode(err))
+}
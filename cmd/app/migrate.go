@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Coiiap5e/photographer/internal/errors"
+	"github.com/Coiiap5e/photographer/internal/migrate"
+)
+
+const migrateUsage = "usage: migrate up | down N | status | goto VERSION"
+
+func runMigrate(ctx context.Context, migrator *migrate.Migrator, args []string) error {
+	if len(args) == 0 {
+		return errors.New(errors.ErrCodeInvalidInput, migrateUsage)
+	}
+
+	switch args[0] {
+	case "up":
+		return migrator.Up(ctx)
+	case "down":
+		n := 1
+		if len(args) > 1 {
+			var err error
+			n, err = strconv.Atoi(args[1])
+			if err != nil {
+				return errors.New(errors.ErrCodeInvalidInput, "N must be an integer")
+			}
+		}
+		return migrator.Down(ctx, n)
+	case "goto":
+		if len(args) < 2 {
+			return errors.New(errors.ErrCodeInvalidInput, migrateUsage)
+		}
+		version, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return errors.New(errors.ErrCodeInvalidInput, "VERSION must be an integer")
+		}
+		return migrator.Goto(ctx, version)
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		showMigrations(statuses)
+		return nil
+	default:
+		return errors.New(errors.ErrCodeInvalidInput, migrateUsage)
+	}
+}
+
+func showMigrations(statuses []migrate.Status) {
+	fmt.Printf("%-12s %-25s %-8s %-17s\n", "Version", "Name", "Applied", "Applied at")
+	fmt.Println(strings.Repeat("-", 65))
+
+	anyApplied := false
+	for _, status := range statuses {
+		applied, appliedAt := "no", ""
+		if status.Applied {
+			anyApplied = true
+			applied, appliedAt = "yes", status.AppliedAt.Format("02.01.2006 15:04")
+		}
+		fmt.Printf("%-12d %-25s %-8s %-17s\n", status.Version, status.Name, applied, appliedAt)
+	}
+
+	if !anyApplied {
+		fmt.Println("no migrations applied")
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/Coiiap5e/photographer/internal/errors"
+	"github.com/Coiiap5e/photographer/internal/httpapi"
+	"github.com/Coiiap5e/photographer/internal/service"
+)
+
+const shutdownTimeout = 10 * time.Second
+
+// runServe serves the HTTP API until a signal arrives on signalChan, then
+// waits up to shutdownTimeout for in-flight requests to finish.
+func runServe(ctx context.Context, args []string, signalChan <-chan os.Signal,
+	clientService service.Client, shootService service.Shoot) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	addr := fs.String("addr", getEnv("APP_HTTP_ADDR", ":8080"), "listen address")
+	calendarToken := fs.String("calendar-token", os.Getenv("APP_CALENDAR_TOKEN"),
+		"secret token of the iCal feed, empty disables the feed")
+
+	if err := fs.Parse(args); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInvalidInput, "invalid arguments for serve")
+	}
+
+	server := &http.Server{
+		Addr:              *addr,
+		Handler:           httpapi.NewServer(clientService, shootService, *calendarToken),
+		ReadHeaderTimeout: 5 * time.Second,
+		BaseContext: func(net.Listener) context.Context {
+			return ctx
+		},
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		log.Printf("listening on %s", *addr)
+		serveErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		return errors.Wrap(err, errors.ErrCodeInternal, "http server failed")
+	case sig := <-signalChan:
+		log.Println("got signal:", sig)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "http server shutdown failed")
+	}
+
+	log.Println("http server stopped")
+
+	return nil
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
@@ -0,0 +1,54 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/Coiiap5e/photographer/internal/calendar"
+)
+
+// showCalendar draws the month with today and lets the user page through
+// months and weeks
+func (a *App) showCalendar(ctx context.Context) error {
+	period, day := calendar.Month, time.Now()
+
+	for {
+		from, to := period.Range(day)
+		shoots, err := a.shootService.ListShoots(ctx, from, to)
+		if err != nil {
+			a.prompt.Printf("Error getting shoots: %v\n", err)
+			return nil
+		}
+
+		query := a.shootService.DefaultSlotQuery(from, to)
+		calendar.Print(a.prompt.Writer(), period, day, shoots, calendar.Options{
+			Color:    calendar.Colorful(a.prompt.Writer()),
+			Today:    time.Now(),
+			DayStart: query.DayStart,
+			DayEnd:   query.DayEnd,
+		})
+
+		choice, err := a.prompt.Line("n next, p previous, t today, m month, w week, Enter to go back")
+		if err != nil {
+			return err
+		}
+
+		switch strings.ToLower(strings.TrimSpace(choice)) {
+		case "":
+			return nil
+		case "n":
+			day = period.Shift(day, 1)
+		case "p":
+			day = period.Shift(day, -1)
+		case "t":
+			day = time.Now()
+		case "m":
+			period = calendar.Month
+		case "w":
+			period = calendar.Week
+		default:
+			a.prompt.Println("Invalid choice")
+		}
+	}
+}
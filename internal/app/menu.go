@@ -1,203 +1,996 @@
 package app
 
 import (
-	"bufio"
 	"context"
 	"fmt"
-	"log"
 	"os"
+	"strings"
+	"time"
 
+	"github.com/Coiiap5e/photographer/internal/audit"
 	"github.com/Coiiap5e/photographer/internal/errors"
+	"github.com/Coiiap5e/photographer/internal/export"
+	"github.com/Coiiap5e/photographer/internal/ical"
+	"github.com/Coiiap5e/photographer/internal/importer"
 	"github.com/Coiiap5e/photographer/internal/model"
 	"github.com/Coiiap5e/photographer/internal/service"
 	"github.com/Coiiap5e/photographer/internal/utils"
+	"github.com/Coiiap5e/photographer/internal/validation"
 )
 
 type App struct {
-	clientService service.Client
-	shootService  service.Shoot
+	clientService  service.Client
+	shootService   service.Shoot
+	paymentService service.Payment
+	trashService   service.Trash
+	auditService   service.Audit
+	prompt         *utils.Prompter
+	present        *presenter
 }
 
-func NewApp(clientService service.Client, shootService service.Shoot) *App {
+// NewApp returns the interactive menu, it asks and answers through prompt
+func NewApp(clientService service.Client, shootService service.Shoot,
+	paymentService service.Payment, trashService service.Trash, auditService service.Audit,
+	prompt *utils.Prompter) *App {
 	return &App{
-		clientService: clientService,
-		shootService:  shootService,
+		clientService:  clientService,
+		shootService:   shootService,
+		paymentService: paymentService,
+		trashService:   trashService,
+		auditService:   auditService,
+		prompt:         prompt,
+		present:        newPresenter(prompt),
 	}
 }
 
+// RunMenu shows the menu until the user exits or the input ends
 func (a *App) RunMenu(ctx context.Context) {
-	scanner := bufio.NewScanner(os.Stdin)
-
 	for {
 		a.showMenu()
-		fmt.Print("Select a menu item: ")
-		scanner.Scan()
-		choice := scanner.Text()
+		choice, err := a.prompt.Line("Select a menu item")
+		if err != nil {
+			return
+		}
+		if choice == "7" {
+			a.prompt.Println("Goodbye!")
+			return
+		}
 
-		switch choice {
-		case "1":
-			client := &model.Client{
-				FirstName:        utils.InputStringRequired("First name"),
-				LastName:         utils.InputStringRequired("Last name"),
-				Phone:            utils.InputStringRequired("Phone number"),
-				SocialNetworkUrl: utils.InputString("Social network url"),
-			}
-			err := a.clientService.CreateClient(ctx, client)
+		if err := a.runItem(ctx, choice); err != nil {
+			return
+		}
+		a.prompt.Println("")
+	}
+}
 
-			if err != nil {
-				fmt.Printf("Error creating client: %v\n", err)
-			}
+// runItem runs the menu item the user chose. Items report their errors to
+// the user, they return one only when the input fails, e.g. with
+// utils.ErrInputClosed.
+func (a *App) runItem(ctx context.Context, choice string) error {
+	switch choice {
+	case "1":
+		return a.addClient(ctx)
+	case "2":
+		return a.deleteClient(ctx)
+	case "3":
+		return a.addShoot(ctx)
+	case "4":
+		return a.deleteShoot(ctx)
+	case "5":
+		return a.showClients(ctx)
+	case "6":
+		return a.showShoots(ctx)
+	case "8":
+		return a.editClient(ctx)
+	case "9":
+		return a.editShoot(ctx)
+	case "10":
+		return a.findFreeSlots(ctx)
+	case "11":
+		return a.recordPayment(ctx)
+	case "12":
+		a.showBalances(ctx)
+	case "13":
+		return a.exportCalendar(ctx)
+	case "14":
+		return a.importClients(ctx)
+	case "15":
+		return a.exportSpreadsheet(ctx)
+	case "16":
+		return a.showTrash(ctx)
+	case "17":
+		return a.showHistory(ctx)
+	case "18":
+		return a.showCalendar(ctx)
+	default:
+		a.prompt.Println("Invalid choice")
+	}
+	return nil
+}
 
-			log.Printf("client added with ID: %d, created at: %v",
-				client.Id, client.CreatedAt)
+func (a *App) showMenu() {
+	a.prompt.Println("1. Add client")
+	a.prompt.Println("2. Delete client")
+	a.prompt.Println("3. Add shoot")
+	a.prompt.Println("4. Delete shoot")
+	a.prompt.Println("5. Show list of clients")
+	a.prompt.Println("6. Show list of shoots")
+	a.prompt.Println("7. Exit")
+	a.prompt.Println("8. Edit client")
+	a.prompt.Println("9. Edit shoot")
+	a.prompt.Println("10. Find free slots")
+	a.prompt.Println("11. Record payment")
+	a.prompt.Println("12. Show outstanding balances")
+	a.prompt.Println("13. Export shoots to calendar (.ics)")
+	a.prompt.Println("14. Import clients from CSV")
+	a.prompt.Println("15. Export clients or shoots to CSV/XLSX")
+	a.prompt.Println("16. Trash")
+	a.prompt.Println("17. Show change history")
+	a.prompt.Println("18. Show calendar")
+}
 
-		case "2":
-			var id int
-			var client *model.Client
-			var err error
-
-			for {
-				id = utils.InputId("ID of the client")
-				client, err = a.clientService.GetClientByID(ctx, id)
-
-				if err != nil {
-					if errors.IsErrorCode(err, errors.ErrCodeClientNotFound) {
-						fmt.Println("Client not found. Try again")
-						continue
-					} else {
-						fmt.Printf("Failed to get client: %v\n", err)
-					}
-				}
-
-				break
-			}
-
-			fmt.Printf("Confirm deleting client: %s %s\n", client.FirstName, client.LastName)
-			fmt.Printf("Phone number: %s\n", client.Phone)
-			if client.SocialNetworkUrl != "" {
-				fmt.Printf("Social network url: %s\n", client.SocialNetworkUrl)
-			}
-
-			err = a.clientService.DeleteClient(ctx, id)
-			if err != nil {
-				fmt.Printf("Error deleting client: %v\n", err)
-			}
-
-			log.Printf("client %s %s with ID: %d deleted successfully \n",
-				client.FirstName, client.LastName, id)
+func (a *App) addClient(ctx context.Context) error {
+	client := &model.Client{}
+	prompts := a.clientPrompts(client)
+	for _, field := range []string{validation.FieldFirstName, validation.FieldLastName,
+		validation.FieldPhone, validation.FieldSocialNetworkUrl} {
+		if err := prompts[field](); err != nil {
+			return err
+		}
+	}
 
-		case "3":
-			var shoot *model.Shoot
-
-			var clientId int
-			var clientFirstName, clientLastName string
-
-			shootDate, startTime, endTime := utils.InputShootDate()
-
-			for {
-				client, err := a.clientService.GetClientByID(ctx, utils.InputId("Client_id"))
-				if err != nil {
-					if errors.IsErrorCode(err, errors.ErrCodeClientNotFound) {
-						fmt.Println("Client not found. Try again")
-						continue
-					} else {
-						fmt.Printf("Failed to get client: %v\n", err)
-					}
-				}
-				clientId = client.Id
-				clientFirstName = client.FirstName
-				clientLastName = client.LastName
-				break
-			}
-
-			shoot = &model.Shoot{
-				ClientId:        clientId,
-				ShootDate:       shootDate,
-				StartTime:       startTime,
-				EndTime:         endTime,
-				ShootPrice:      utils.InputInt("Shoot price"),
-				ShootLocation:   utils.InputStringRequired("Location"),
-				ClientFirstName: clientFirstName,
-				ClientLastName:  clientLastName,
-				ShootType:       utils.InputStringRequired("Shoot type"),
-				Notes:           utils.InputString("Notes"),
-			}
-
-			err := a.shootService.CreateShoot(ctx, shoot)
-			if err != nil {
-				fmt.Printf("Error creating shoot: %v\n", err)
-			}
-
-			log.Printf("shoot added successfully")
-
-		case "4":
-			var id int
-			var shoot *model.Shoot
-			var err error
-
-			for {
-				id = utils.InputId("ID of the shoot")
-				shoot, err = a.shootService.GetShootByID(ctx, id)
-
-				if err != nil {
-					if errors.IsErrorCode(err, errors.ErrCodeShootNotFound) {
-						fmt.Println("Shoot not found. Try again")
-						continue
-					} else {
-						fmt.Printf("Failed to get shoot: %v\n", err)
-					}
-				}
-
-				break
-			}
-
-			fmt.Printf("Confirm deleting shoot: %s start: %s end: %s\n",
-				shoot.ShootDate.Format("02.01.2006"),
-				shoot.StartTime.Format("15:04"),
-				shoot.EndTime.Format("15:04"))
-			fmt.Printf("Location: %s. ShootType: %s. Price: %d\n",
-				shoot.ShootLocation, shoot.ShootType, shoot.ShootPrice)
-			fmt.Printf("Client id: %d name: %s %s\n", shoot.ClientId,
-				shoot.ClientFirstName, shoot.ClientLastName)
-			if shoot.Notes != "" {
-				fmt.Printf("Notes: %s\n", shoot.Notes)
-			}
-
-			err = a.shootService.DeleteShoot(ctx, id)
-			if err != nil {
-				fmt.Printf("Error deleting shoot: %v\n", err)
-			}
-
-			log.Printf("shoot %s with %s %s deleted successfully \n",
-				shoot.StartTime.Format("02.01.2006 15:04"),
-				shoot.ClientFirstName, shoot.ClientLastName)
-
-		case "5":
-			err := a.clientService.GetClients(ctx)
-			if err != nil {
-				fmt.Printf("Error getting clients: %v\n", err)
-			}
-		case "6":
-			err := a.shootService.GetShoots(ctx)
-			if err != nil {
-				fmt.Printf("Error getting shoots: %v\n", err)
-			}
-		case "7":
-			fmt.Println("Goodbye!")
-			return
-		default:
-			fmt.Println("Invalid choice")
+	for {
+		err := a.clientService.CreateClient(ctx, client)
+		if err == nil {
+			a.prompt.Printf("client added with ID: %d\n", client.Id)
+			return nil
+		}
+		fixed, inputErr := a.fixFields(err, prompts)
+		if inputErr != nil {
+			return inputErr
+		}
+		if !fixed {
+			a.prompt.Printf("Error creating client: %v\n", err)
+			return nil
 		}
-		fmt.Println("")
 	}
 }
 
-func (a *App) showMenu() {
-	fmt.Println("1. Add client")
-	fmt.Println("2. Delete client")
-	fmt.Println("3. Add shoot")
-	fmt.Println("4. Delete shoot")
-	fmt.Println("5. Show list of clients")
-	fmt.Println("6. Show list of shoots")
-	fmt.Println("7. Exit")
+func (a *App) deleteClient(ctx context.Context) error {
+	client, err := a.pickClient(ctx)
+	if client == nil {
+		return err
+	}
+	id := client.Id
+
+	confirmed, err := a.present.confirmDeleteClient(client)
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		a.prompt.Println("Deletion cancelled")
+		return nil
+	}
+
+	err = a.clientService.DeleteClient(ctx, id)
+	if err != nil {
+		a.prompt.Printf("Error deleting client: %v\n", err)
+		return nil
+	}
+
+	a.prompt.Printf("client %s %s with ID: %d moved to trash, restore it with item 16\n",
+		client.FirstName, client.LastName, id)
+	return nil
+}
+
+func (a *App) addShoot(ctx context.Context) error {
+	client, err := a.pickClient(ctx)
+	if client == nil {
+		return err
+	}
+
+	shoot := &model.Shoot{ClientId: client.Id}
+	if shoot.ShootDate, shoot.StartTime, shoot.EndTime, err = a.prompt.ShootDate(); err != nil {
+		return err
+	}
+	prompts := a.shootPrompts(shoot)
+	for _, field := range []string{validation.FieldShootPrice, validation.FieldLocation,
+		validation.FieldShootType} {
+		if err := prompts[field](); err != nil {
+			return err
+		}
+	}
+	if shoot.Notes, err = a.prompt.Line("Notes"); err != nil {
+		return err
+	}
+
+	for {
+		err := a.shootService.CreateShoot(ctx, shoot)
+		if err == nil {
+			a.prompt.Printf("shoot added successfully\n")
+			return nil
+		}
+		fixed, inputErr := a.fixFields(err, prompts)
+		if inputErr != nil {
+			return inputErr
+		}
+		if fixed {
+			continue
+		}
+		if !errors.IsErrorCode(err, errors.ErrCodeShootConflict) {
+			a.prompt.Printf("Error creating shoot: %v\n", err)
+			return nil
+		}
+
+		choice, inputErr := a.resolveConflict(err)
+		if inputErr != nil {
+			return inputErr
+		}
+		if choice == conflictCancel {
+			a.prompt.Println("Shoot is not added")
+			return nil
+		}
+		if choice == conflictBookAnyway {
+			shoot.AllowOverlap = true
+			continue
+		}
+		if shoot.ShootDate, shoot.StartTime, shoot.EndTime, err = a.prompt.ShootDate(); err != nil {
+			return err
+		}
+	}
+}
+
+func (a *App) deleteShoot(ctx context.Context) error {
+	var id int
+	var shoot *model.Shoot
+	var err error
+
+	for {
+		if id, err = a.prompt.Id("ID of the shoot"); err != nil {
+			return err
+		}
+		shoot, err = a.shootService.GetShootByID(ctx, id)
+		if err == nil {
+			break
+		}
+		if errors.IsErrorCode(err, errors.ErrCodeShootNotFound) {
+			a.prompt.Println("Shoot not found. Try again")
+			continue
+		}
+		a.prompt.Printf("Failed to get shoot: %v\n", err)
+		return nil
+	}
+
+	confirmed, err := a.present.confirmDeleteShoot(shoot)
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		a.prompt.Println("Deletion cancelled")
+		return nil
+	}
+
+	err = a.shootService.DeleteShoot(ctx, id)
+	if err != nil {
+		a.prompt.Printf("Error deleting shoot: %v\n", err)
+		return nil
+	}
+
+	a.prompt.Printf("shoot %s with %s %s moved to trash, restore it with item 16\n",
+		shoot.StartTime.Format("02.01.2006 15:04"),
+		shoot.Client.FirstName, shoot.Client.LastName)
+	return nil
+}
+
+func (a *App) editClient(ctx context.Context) error {
+	client, err := a.pickClient(ctx)
+	if client == nil {
+		return err
+	}
+
+	a.prompt.Println("Press Enter to keep the current value")
+
+	update := &model.ClientUpdate{}
+	firstName, err := a.prompt.Default("First name", client.FirstName)
+	if err != nil {
+		return err
+	}
+	if firstName != client.FirstName {
+		update.FirstName = &firstName
+	}
+	lastName, err := a.prompt.Default("Last name", client.LastName)
+	if err != nil {
+		return err
+	}
+	if lastName != client.LastName {
+		update.LastName = &lastName
+	}
+	phone, err := a.prompt.Default("Phone number", client.Phone)
+	if err != nil {
+		return err
+	}
+	if phone != client.Phone {
+		update.Phone = &phone
+	}
+	url, err := a.prompt.Clearable("Social network url", client.SocialNetworkUrl)
+	if err != nil {
+		return err
+	}
+	if url != client.SocialNetworkUrl {
+		update.SocialNetworkUrl = &url
+	}
+
+	if *update == (model.ClientUpdate{}) {
+		a.prompt.Println("Nothing to change")
+		return nil
+	}
+
+	id, version := client.Id, client.Version
+	for {
+		client, err = a.clientService.UpdateClient(ctx, id, version, update)
+		fixed, inputErr := a.fixFields(err, a.clientUpdatePrompts(update))
+		if inputErr != nil {
+			return inputErr
+		}
+		if !fixed {
+			break
+		}
+	}
+	if err != nil {
+		if errors.IsErrorCode(err, errors.ErrCodeVersionConflict) {
+			a.prompt.Println("Client was changed by someone else. Try again")
+			return nil
+		}
+		a.prompt.Printf("Error updating client: %v\n", err)
+		return nil
+	}
+
+	a.prompt.Printf("client %s %s with ID: %d updated successfully \n",
+		client.FirstName, client.LastName, client.Id)
+	return nil
+}
+
+func (a *App) editShoot(ctx context.Context) error {
+	var shoot *model.Shoot
+
+	for {
+		id, err := a.prompt.Id("ID of the shoot")
+		if err != nil {
+			return err
+		}
+		shoot, err = a.shootService.GetShootByID(ctx, id)
+		if err == nil {
+			break
+		}
+		if errors.IsErrorCode(err, errors.ErrCodeShootNotFound) {
+			a.prompt.Println("Shoot not found. Try again")
+			continue
+		}
+		a.prompt.Printf("Failed to get shoot: %v\n", err)
+		return nil
+	}
+
+	a.prompt.Println("Press Enter to keep the current value")
+
+	update := &model.ShootUpdate{}
+	a.prompt.Printf("Client: %s %s\n", shoot.Client.FirstName, shoot.Client.LastName)
+	changeClient, err := a.prompt.Confirm("Change client?")
+	if err != nil {
+		return err
+	}
+	if changeClient {
+		client, err := a.pickClient(ctx)
+		if err != nil {
+			return err
+		}
+		if client != nil && client.Id != shoot.ClientId {
+			update.ClientId = &client.Id
+		}
+	}
+
+	date, err := a.prompt.DateDefault("Shoot date", shoot.ShootDate)
+	if err != nil {
+		return err
+	}
+	if !date.Equal(shoot.ShootDate) {
+		update.ShootDate = &date
+	}
+
+	startTime, err := a.prompt.TimeDefault("Start time of date", date, shoot.StartTime)
+	if err != nil {
+		return err
+	}
+	if startTime.Format("15:04") != shoot.StartTime.Format("15:04") {
+		update.StartTime = &startTime
+	}
+
+	endTime, err := a.prompt.TimeDefault("End time of date", date, shoot.EndTime)
+	if err != nil {
+		return err
+	}
+	if endTime.Format("15:04") != shoot.EndTime.Format("15:04") {
+		update.EndTime = &endTime
+	}
+
+	price, err := a.prompt.MoneyDefault("Shoot price", shoot.ShootPrice)
+	if err != nil {
+		return err
+	}
+	if price != shoot.ShootPrice {
+		update.ShootPrice = &price
+	}
+	location, err := a.prompt.Default("Location", shoot.ShootLocation)
+	if err != nil {
+		return err
+	}
+	if location != shoot.ShootLocation {
+		update.ShootLocation = &location
+	}
+	shootType, err := a.prompt.Default("Shoot type", shoot.ShootType)
+	if err != nil {
+		return err
+	}
+	if shootType != shoot.ShootType {
+		update.ShootType = &shootType
+	}
+	notes, err := a.prompt.Clearable("Notes", shoot.Notes)
+	if err != nil {
+		return err
+	}
+	if notes != shoot.Notes {
+		update.Notes = &notes
+	}
+
+	if *update == (model.ShootUpdate{}) {
+		a.prompt.Println("Nothing to change")
+		return nil
+	}
+
+	for {
+		var updated *model.Shoot
+		updated, err = a.shootService.UpdateShoot(ctx, shoot.Id, shoot.Version, update)
+		fixed, inputErr := a.fixFields(err, a.shootUpdatePrompts(update, date))
+		if inputErr != nil {
+			return inputErr
+		}
+		if fixed {
+			continue
+		}
+		if !errors.IsErrorCode(err, errors.ErrCodeShootConflict) {
+			shoot = updated
+			break
+		}
+
+		choice, inputErr := a.resolveConflict(err)
+		if inputErr != nil {
+			return inputErr
+		}
+		if choice == conflictCancel {
+			a.prompt.Println("Shoot is not changed")
+			return nil
+		}
+		if choice == conflictBookAnyway {
+			allowOverlap := true
+			update.AllowOverlap = &allowOverlap
+			continue
+		}
+		// the outer date is assigned, shootUpdatePrompts asks again for times on it
+		if date, startTime, endTime, inputErr = a.prompt.ShootDate(); inputErr != nil {
+			return inputErr
+		}
+		update.ShootDate, update.StartTime, update.EndTime = &date, &startTime, &endTime
+	}
+
+	if err != nil {
+		if errors.IsErrorCode(err, errors.ErrCodeVersionConflict) {
+			a.prompt.Println("Shoot was changed by someone else. Try again")
+			return nil
+		}
+		if errors.IsErrorCode(err, errors.ErrCodeClientNotFound) {
+			a.prompt.Println("Client not found. Shoot is not changed")
+			return nil
+		}
+		a.prompt.Printf("Error updating shoot: %v\n", err)
+		return nil
+	}
+
+	a.prompt.Printf("shoot %s with %s %s updated successfully \n",
+		shoot.ShootDate.Format("02.01.2006")+" "+shoot.StartTime.Format("15:04"),
+		shoot.Client.FirstName, shoot.Client.LastName)
+	return nil
+}
+
+func (a *App) findFreeSlots(ctx context.Context) error {
+	from, err := a.prompt.Date("First day")
+	if err != nil {
+		return err
+	}
+	to, err := a.prompt.Date("Last day")
+	if err != nil {
+		return err
+	}
+
+	query := a.shootService.DefaultSlotQuery(from, to)
+	if query.Duration, err = a.prompt.DurationDefault("Shoot duration (for example: 1h30m)", 0); err != nil {
+		return err
+	}
+	if query.DayStart, err = a.prompt.ClockDefault("Working day starts at", query.DayStart); err != nil {
+		return err
+	}
+	if query.DayEnd, err = a.prompt.ClockDefault("Working day ends at", query.DayEnd); err != nil {
+		return err
+	}
+	if query.Buffer, err = a.prompt.DurationDefault("Break between shoots", query.Buffer); err != nil {
+		return err
+	}
+	if query.DaysOff, err = a.prompt.WeekdaysDefault("Weekly days off", query.DaysOff); err != nil {
+		return err
+	}
+	if query.DatesOff, err = a.prompt.DateList("Other days off (dd.mm.yyyy, comma separated)"); err != nil {
+		return err
+	}
+
+	slots, err := a.shootService.FindFreeSlots(ctx, query)
+	if err != nil {
+		a.prompt.Printf("Error finding free slots: %v\n", err)
+		return nil
+	}
+
+	if len(slots) == 0 {
+		a.prompt.Println("No free slots found")
+		return nil
+	}
+
+	for _, slot := range slots {
+		a.prompt.Println(slot)
+	}
+
+	path, err := a.prompt.Line("Save to file (Enter to skip)")
+	if path == "" {
+		return err
+	}
+
+	if err := saveSlots(path, slots); err != nil {
+		a.prompt.Printf("Error saving free slots: %v\n", err)
+		return nil
+	}
+
+	a.prompt.Printf("free slots saved to %s\n", path)
+	return nil
+}
+
+func saveSlots(path string, slots []model.Slot) error {
+	var b strings.Builder
+	for _, slot := range slots {
+		b.WriteString(slot.String())
+		b.WriteString("\n")
+	}
+
+	return os.WriteFile(path, []byte(b.String()), 0o644)
+}
+
+func (a *App) recordPayment(ctx context.Context) error {
+	var balance *model.ShootBalance
+
+	for {
+		id, err := a.prompt.Id("ID of the shoot")
+		if err != nil {
+			return err
+		}
+		balance, err = a.paymentService.GetShootBalance(ctx, id)
+		if err == nil {
+			break
+		}
+		if errors.IsErrorCode(err, errors.ErrCodeShootNotFound) {
+			a.prompt.Println("Shoot not found. Try again")
+			continue
+		}
+		a.prompt.Printf("Failed to get shoot: %v\n", err)
+		return nil
+	}
+
+	a.prompt.Printf("Shoot %s, %s %s. Price: %s, paid: %s, due: %s\n",
+		balance.ShootDate.Format("02.01.2006"), balance.ClientFirstName, balance.ClientLastName,
+		balance.Price, balance.Paid, balance.Due())
+
+	payment := &model.Payment{ShootId: balance.ShootId}
+	for {
+		kind, err := a.prompt.Default("Kind (deposit, final, refund)", string(model.PaymentDeposit))
+		if err != nil {
+			return err
+		}
+		if payment.Kind = model.PaymentKind(kind); payment.Kind.Valid() {
+			break
+		}
+		a.prompt.Println("Unknown payment kind. Try again")
+	}
+
+	var err error
+	if payment.Amount, err = a.prompt.Money("Amount", balance.Price.Currency); err != nil {
+		return err
+	}
+	if payment.PaidAt, err = a.prompt.DateDefault("Paid at", time.Now()); err != nil {
+		return err
+	}
+	if payment.Notes, err = a.prompt.Line("Notes"); err != nil {
+		return err
+	}
+
+	if err := a.paymentService.RecordPayment(ctx, payment); err != nil {
+		a.prompt.Printf("Error recording payment: %v\n", err)
+		return nil
+	}
+
+	a.prompt.Printf("payment %s of %s for shoot %d recorded with ID: %d\n",
+		payment.Kind, payment.Amount, payment.ShootId, payment.Id)
+	return nil
+}
+
+func (a *App) showBalances(ctx context.Context) {
+	shoots, err := a.paymentService.OutstandingBalances(ctx)
+	if err != nil {
+		a.prompt.Printf("Error getting balances: %v\n", err)
+		return
+	}
+
+	clients, err := a.paymentService.ClientBalances(ctx)
+	if err != nil {
+		a.prompt.Printf("Error getting client balances: %v\n", err)
+		return
+	}
+
+	a.present.balances(shoots, clients)
+}
+
+// askPeriod asks for the optional first and last day of a period
+func (a *App) askPeriod() (time.Time, time.Time, error) {
+	from, err := a.prompt.DateOptional("First day")
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	to, err := a.prompt.DateOptional("Last day")
+	return from, to, err
+}
+
+func (a *App) exportCalendar(ctx context.Context) error {
+	from, to, err := a.askPeriod()
+	if err != nil {
+		return err
+	}
+
+	shoots, err := a.shootService.ListShoots(ctx, from, to)
+	if err != nil {
+		a.prompt.Printf("Error getting shoots: %v\n", err)
+		return nil
+	}
+
+	path, err := a.prompt.Default("Save to file", "shoots.ics")
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		a.prompt.Printf("Error creating file: %v\n", err)
+		return nil
+	}
+	defer file.Close()
+
+	if err := ical.Encode(file, "Shoots", shoots); err != nil {
+		a.prompt.Printf("Error exporting shoots: %v\n", err)
+		return nil
+	}
+
+	a.prompt.Printf("%d shoots exported to %s\n", len(shoots), path)
+	return nil
+}
+
+// pageSize is how many clients or shoots the menu shows at once
+const pageSize = 20
+
+// showClients lists clients by name a page at a time
+func (a *App) showClients(ctx context.Context) error {
+	filter := &model.ClientFilter{Limit: pageSize}
+	for filter != nil {
+		page, err := a.clientService.FindClients(ctx, *filter)
+		if err != nil {
+			a.prompt.Printf("Error getting clients: %v\n", err)
+			return nil
+		}
+		a.present.clients(page.Clients)
+		if filter = page.Next; filter == nil {
+			break
+		}
+		more, err := a.prompt.Confirm("Show more?")
+		if !more {
+			return err
+		}
+	}
+	return nil
+}
+
+// showShoots asks which shoots to list and shows them a page at a time
+func (a *App) showShoots(ctx context.Context) error {
+	a.prompt.Println("1. Upcoming shoots")
+	a.prompt.Println("2. Past shoots")
+	a.prompt.Println("3. Shoots for client")
+	a.prompt.Println("4. Search shoots")
+	a.prompt.Println("5. All shoots")
+
+	today := time.Now()
+	today = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+
+	choice, err := a.prompt.Line("Choose list")
+	if err != nil {
+		return err
+	}
+
+	filter := &model.ShootFilter{Limit: pageSize}
+	switch choice {
+	case "1":
+		filter.From = today
+	case "2":
+		filter.To = today.AddDate(0, 0, -1)
+		filter.Desc = true
+	case "3":
+		client, err := a.pickClient(ctx)
+		if client == nil {
+			return err
+		}
+		filter.ClientId = client.Id
+	case "4":
+		if err := a.askShootSearch(filter); err != nil {
+			return err
+		}
+	case "5":
+	default:
+		a.prompt.Println("Invalid choice")
+		return nil
+	}
+
+	for filter != nil {
+		page, err := a.shootService.FindShoots(ctx, *filter)
+		if err != nil {
+			a.prompt.Printf("Error getting shoots: %v\n", err)
+			return nil
+		}
+		a.present.shoots(page.Shoots)
+		if filter = page.Next; filter == nil {
+			break
+		}
+		more, err := a.prompt.Confirm("Show more?")
+		if !more {
+			return err
+		}
+	}
+	return nil
+}
+
+// askShootSearch asks for the conditions of a shoot search
+func (a *App) askShootSearch(filter *model.ShootFilter) error {
+	currency := a.shootService.DefaultCurrency()
+
+	var err error
+	if filter.From, filter.To, err = a.askPeriod(); err != nil {
+		return err
+	}
+	if filter.ShootType, err = a.prompt.Line("Type (Enter to skip)"); err != nil {
+		return err
+	}
+	if filter.Location, err = a.prompt.Line("Location contains (Enter to skip)"); err != nil {
+		return err
+	}
+	filter.ShootType = strings.TrimSpace(filter.ShootType)
+	filter.Location = strings.TrimSpace(filter.Location)
+	if filter.MinPrice, err = a.prompt.MoneyOptional("Minimum price", currency); err != nil {
+		return err
+	}
+	if filter.MaxPrice, err = a.prompt.MoneyOptional("Maximum price", currency); err != nil {
+		return err
+	}
+
+	byPrice, err := a.prompt.Confirm("Sort by price?")
+	if byPrice {
+		filter.Sort = model.ShootSortPrice
+	}
+	return err
+}
+
+// showHistory prints who changed a client or shoot and how. IDs are asked
+// for directly, so items in the trash or purged can be looked up too.
+func (a *App) showHistory(ctx context.Context) error {
+	a.prompt.Println("1. Client history with their shoots")
+	a.prompt.Println("2. Shoot history with its payments")
+
+	choice, err := a.prompt.Line("Choose history")
+	if err != nil {
+		return err
+	}
+
+	var entity model.AuditEntity
+	switch choice {
+	case "1":
+		entity = model.AuditClient
+	case "2":
+		entity = model.AuditShoot
+	default:
+		a.prompt.Println("Invalid choice")
+		return nil
+	}
+
+	id, err := a.prompt.Id("ID of the " + string(entity))
+	if err != nil {
+		return err
+	}
+
+	entries, err := a.auditService.History(ctx, entity, id)
+	if err != nil {
+		a.prompt.Printf("Error getting history: %v\n", err)
+		return nil
+	}
+
+	audit.Print(a.prompt.Writer(), entries)
+	return nil
+}
+
+// exportSpreadsheet writes clients or shoots with their client details to a
+// CSV or XLSX file, the format is taken from the file extension
+func (a *App) exportSpreadsheet(ctx context.Context) error {
+	what, err := a.prompt.Default("Export clients or shoots", "shoots")
+	if err != nil {
+		return err
+	}
+	if what != "clients" && what != "shoots" {
+		a.prompt.Println("Invalid choice")
+		return nil
+	}
+
+	from, to, err := a.askPeriod()
+	if err != nil {
+		return err
+	}
+
+	var table *export.Table
+	if what == "clients" {
+		var clients []model.Client
+		if clients, err = a.clientService.ListClients(ctx); err == nil {
+			table, err = export.Clients(clients, nil, from, to)
+		}
+	} else {
+		var shoots []model.Shoot
+		if shoots, err = a.shootService.ListShoots(ctx, from, to); err == nil {
+			table, err = export.Shoots(shoots, nil)
+		}
+	}
+	if err != nil {
+		a.prompt.Printf("Error getting %s: %v\n", what, err)
+		return nil
+	}
+
+	path, err := a.prompt.Default("Save to file (.csv or .xlsx)", what+".xlsx")
+	if err != nil {
+		return err
+	}
+
+	format, err := export.DetectFormat("", path)
+	if err != nil {
+		a.prompt.Printf("Error: %v\n", err)
+		return nil
+	}
+
+	locale, _ := export.LookupLocale(export.DefaultLocale)
+
+	file, err := os.Create(path)
+	if err != nil {
+		a.prompt.Printf("Error creating file: %v\n", err)
+		return nil
+	}
+	defer file.Close()
+
+	if err := export.Write(file, format, table, locale); err != nil {
+		a.prompt.Printf("Error exporting %s: %v\n", what, err)
+		return nil
+	}
+
+	a.prompt.Printf("%d %s exported to %s\n", len(table.Rows), what, path)
+	return nil
+}
+
+// importClients shows a dry run report first and imports after confirmation
+func (a *App) importClients(ctx context.Context) error {
+	path, err := a.prompt.Required("CSV file")
+	if err != nil {
+		return err
+	}
+
+	var mapping importer.Mapping
+	for {
+		value, err := a.prompt.Line(
+			"Column mapping, e.g. first_name=Имя,phone=Телефон (Enter for defaults)")
+		if err != nil {
+			return err
+		}
+		if mapping, err = importer.ParseMapping(value); err == nil {
+			break
+		}
+		a.prompt.Printf("Error: %v\n", err)
+	}
+
+	opts := importer.Options{Mapping: mapping}
+
+	run := func(dryRun bool) *importer.Report {
+		file, err := os.Open(path)
+		if err != nil {
+			a.prompt.Printf("Error opening file: %v\n", err)
+			return nil
+		}
+		defer file.Close()
+
+		report, err := a.clientService.ImportClients(ctx, file, opts, dryRun)
+		if err != nil {
+			a.prompt.Printf("Error importing clients: %v\n", err)
+			return nil
+		}
+		return report
+	}
+
+	report := run(true)
+	if report == nil {
+		return nil
+	}
+
+	report.Print(a.prompt.Writer())
+
+	if len(report.New) == 0 {
+		a.prompt.Println("Nothing to import")
+		return nil
+	}
+
+	confirmed, err := a.prompt.Confirm(fmt.Sprintf("Import %d new clients?", len(report.New)))
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		a.prompt.Println("Import cancelled")
+		return nil
+	}
+
+	if report = run(false); report != nil {
+		a.prompt.Printf("%d clients imported from %s\n", len(report.New), path)
+	}
+	return nil
+}
+
+type conflictChoice int
+
+const (
+	conflictCancel conflictChoice = iota
+	conflictBookAnyway
+	conflictPickAnother
+)
+
+// resolveConflict shows the shoots that clash with the one being saved and
+// asks what to do about it
+func (a *App) resolveConflict(err error) (conflictChoice, error) {
+	a.present.conflicts(err)
+
+	for {
+		a.prompt.Println("1. Book anyway")
+		a.prompt.Println("2. Pick another slot")
+		a.prompt.Println("3. Cancel")
+		choice, err := a.prompt.Required("Select an option")
+		if err != nil {
+			return conflictCancel, err
+		}
+		switch choice {
+		case "1":
+			return conflictBookAnyway, nil
+		case "2":
+			return conflictPickAnother, nil
+		case "3":
+			return conflictCancel, nil
+		default:
+			a.prompt.Println("Invalid choice")
+		}
+	}
 }
@@ -0,0 +1,109 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Coiiap5e/photographer/internal/config"
+	"github.com/Coiiap5e/photographer/internal/repository"
+	"github.com/Coiiap5e/photographer/internal/service"
+	"github.com/Coiiap5e/photographer/internal/utils"
+	"github.com/stretchr/testify/suite"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files of the menu sessions")
+
+func TestMenuTestSuit(t *testing.T) {
+	suite.Run(t, new(MenuTestSuit))
+}
+
+// MenuTestSuit drives whole menu sessions from testdata/<name>.input and
+// compares what the menu printed with testdata/<name>.golden
+type MenuTestSuit struct {
+	suite.Suite
+}
+
+// session runs the menu on the input of the named session against empty
+// in-memory storage and returns the output
+func (suite *MenuTestSuit) session(name string) string {
+	input, err := os.ReadFile(filepath.Join("testdata", name+".input"))
+	suite.Require().NoError(err, "should read session input")
+
+	store := repository.NewMemoryStore()
+	clientRepo := repository.NewMemoryClient(store)
+	shootRepo := repository.NewMemoryShoot(store)
+
+	var out bytes.Buffer
+	app := NewApp(
+		service.NewClient(clientRepo),
+		service.NewShoot(shootRepo, clientRepo, config.ScheduleConfig{},
+			config.MoneyConfig{DefaultCurrency: "RUB"}),
+		service.NewPayment(store, repository.NewMemoryPayment(store)),
+		service.NewTrash(store, clientRepo, shootRepo, config.TrashConfig{Retention: 30 * 24 * time.Hour}),
+		service.NewAudit(repository.NewMemoryAudit(store)),
+		utils.NewPrompter(bytes.NewReader(input), &out),
+	)
+
+	app.RunMenu(context.Background())
+
+	// created dates are today, keep the golden files stable
+	return strings.ReplaceAll(out.String(), time.Now().Format("02.01.2006"), "TODAY")
+}
+
+func (suite *MenuTestSuit) assertGolden(name, output string) {
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		suite.Require().NoError(os.WriteFile(path, []byte(output), 0o644), "should write golden file")
+	}
+
+	golden, err := os.ReadFile(path)
+	suite.Require().NoError(err, "should read golden file, run with -update to create it")
+	suite.Equal(string(golden), output, "should print the same session as %s", path)
+}
+
+func (suite *MenuTestSuit) TestSessions() {
+	sessions := map[string]string{
+		"add_client":            "should add a client and list it",
+		"delete_client_cancel":  "should keep the client when deletion is not confirmed",
+		"shoot_conflict_cancel": "should show the conflicting shoot and not add the new one",
+		"eof_mid_prompt":        "should exit when the input ends in the middle of a prompt",
+		"invalid_choice":        "should report an invalid menu item and keep asking",
+		"invalid_fields":        "should ask again only for the invalid fields",
+	}
+
+	for name, description := range sessions {
+		suite.T().Run(description, func(t *testing.T) {
+			// When
+			output := suite.session(name)
+
+			// Then
+			suite.assertGolden(name, output)
+		})
+	}
+}
+
+func (suite *MenuTestSuit) TestPipedInput() {
+	// Given
+	input := "1\nAnna\nIvanova\n+79990001122\n\n1\nOleg\nPetrov\n+79990003344\n\n7\n"
+	store := repository.NewMemoryStore()
+	clientService := service.NewClient(repository.NewMemoryClient(store))
+
+	var out bytes.Buffer
+	app := NewApp(clientService, nil, nil, nil, nil,
+		utils.NewPrompter(strings.NewReader(input), &out))
+
+	// When
+	app.RunMenu(context.Background())
+
+	// Then
+	clients, err := clientService.SearchClients(context.Background(), "+7999000")
+	suite.NoError(err, "should not return error")
+	suite.Len(clients, 2, "should read every line piped in ahead of the prompts")
+	suite.Contains(out.String(), "Goodbye!", "should reach the exit item")
+}
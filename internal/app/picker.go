@@ -0,0 +1,80 @@
+package app
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/Coiiap5e/photographer/internal/errors"
+	"github.com/Coiiap5e/photographer/internal/model"
+)
+
+// pickClient searches clients by name, phone or social URL and lets the
+// user choose one of the matches. #ID picks a client by ID. It returns a
+// nil client if the user cancels with empty input.
+func (a *App) pickClient(ctx context.Context) (*model.Client, error) {
+	for {
+		query, err := a.prompt.Line(
+			"Find client by name, phone or social network, or #ID (Enter to cancel)")
+		if err != nil {
+			return nil, err
+		}
+		if query = strings.TrimSpace(query); query == "" {
+			return nil, nil
+		}
+
+		if id, ok := strings.CutPrefix(query, "#"); ok {
+			if client := a.clientByID(ctx, id); client != nil {
+				return client, nil
+			}
+			continue
+		}
+
+		clients, err := a.clientService.SearchClients(ctx, query)
+		if err != nil {
+			a.prompt.Printf("Error searching clients: %v\n", err)
+			continue
+		}
+
+		if len(clients) == 0 {
+			a.prompt.Println("No clients found. Try again")
+			continue
+		}
+
+		for i, client := range clients {
+			a.prompt.Printf("%2d. %s %s, %s", i+1, client.FirstName, client.LastName, client.Phone)
+			if client.SocialNetworkUrl != "" {
+				a.prompt.Printf(", %s", client.SocialNetworkUrl)
+			}
+			a.prompt.Printf(" (#%d)\n", client.Id)
+		}
+
+		choice, err := a.prompt.Int("Choose client (0 to search again)")
+		if err != nil {
+			return nil, err
+		}
+		if choice >= 1 && choice <= len(clients) {
+			return &clients[choice-1], nil
+		}
+	}
+}
+
+func (a *App) clientByID(ctx context.Context, value string) *model.Client {
+	id, err := strconv.Atoi(value)
+	if err != nil || id <= 0 {
+		a.prompt.Println("Error: ID must be a number > 0")
+		return nil
+	}
+
+	client, err := a.clientService.GetClientByID(ctx, id)
+	if err != nil {
+		if errors.IsErrorCode(err, errors.ErrCodeClientNotFound) {
+			a.prompt.Println("Client not found. Try again")
+		} else {
+			a.prompt.Printf("Failed to get client: %v\n", err)
+		}
+		return nil
+	}
+
+	return client
+}
@@ -0,0 +1,203 @@
+package app
+
+import (
+	stderrors "errors"
+	"strings"
+	"time"
+
+	"github.com/Coiiap5e/photographer/internal/model"
+	"github.com/Coiiap5e/photographer/internal/service"
+	"github.com/Coiiap5e/photographer/internal/utils"
+)
+
+// presenter renders what the services return for the menu and asks the
+// user to confirm what they are about to change. The services only return
+// data, so they can be used without a terminal.
+type presenter struct {
+	prompt *utils.Prompter
+}
+
+func newPresenter(prompt *utils.Prompter) *presenter {
+	return &presenter{prompt: prompt}
+}
+
+func (p *presenter) printf(format string, args ...any) {
+	p.prompt.Printf(format, args...)
+}
+
+func (p *presenter) println(args ...any) {
+	p.prompt.Println(args...)
+}
+
+func (p *presenter) clients(clients []model.Client) {
+	if len(clients) == 0 {
+		p.println("No clients found")
+		return
+	}
+
+	p.printf("%-4s %-15s %-15s %-20s %-25s %-12s\n",
+		"ID", "First Name", "Last Name", "Phone", "Social Network", "Created")
+	p.println(strings.Repeat("-", 95))
+
+	for _, client := range clients {
+		p.printf("%-4d %-15s %-15s %-20s %-25s %-12s\n",
+			client.Id,
+			client.FirstName,
+			client.LastName,
+			client.Phone,
+			client.SocialNetworkUrl,
+			client.CreatedAt.Format("02.01.2006"),
+		)
+	}
+}
+
+func (p *presenter) shoots(shoots []model.Shoot) {
+	if len(shoots) == 0 {
+		p.println("No shoots found")
+		return
+	}
+
+	p.printf("%-3s %-9s %-10s %-8s %-8s %-14s %-25s %-12s %-12s %-10s %-25s %-10s\n",
+		"ID", "Client ID", "Date", "Start", "End", "Price", "Location",
+		"First name", "Last name", "Type", "Notes", "Created")
+
+	p.println(strings.Repeat("-", 156))
+
+	for _, shoot := range shoots {
+		p.printf("%-3d %-9d %-10s %-8s %-8s %-14s %-25s %-12s %-12s %-10s %-25s %-10s\n",
+			shoot.Id,
+			shoot.ClientId,
+			shoot.ShootDate.Format("02.01.2006"),
+			shoot.StartTime.Format("15:04"),
+			shoot.EndTime.Format("15:04"),
+			shoot.ShootPrice.String(),
+			shoot.ShootLocation,
+			shoot.Client.FirstName,
+			shoot.Client.LastName,
+			shoot.ShootType,
+			shoot.Notes,
+			shoot.CreatedAt.Format("02.01.2006"),
+		)
+	}
+}
+
+func (p *presenter) trash(trash *model.Trash) {
+	if len(trash.Clients) == 0 && len(trash.Shoots) == 0 {
+		p.println("Trash is empty")
+		return
+	}
+
+	if len(trash.Clients) > 0 {
+		p.println("Clients:")
+		p.printf("%-4s %-15s %-15s %-20s %-16s\n",
+			"ID", "First Name", "Last Name", "Phone", "Deleted")
+		p.println(strings.Repeat("-", 74))
+		for _, client := range trash.Clients {
+			p.printf("%-4d %-15s %-15s %-20s %-16s\n",
+				client.Id, client.FirstName, client.LastName, client.Phone,
+				deletedAt(client.DeletedAt))
+		}
+	}
+
+	if len(trash.Shoots) > 0 {
+		p.println("Shoots:")
+		p.printf("%-4s %-10s %-11s %-25s %-12s %-16s\n",
+			"ID", "Date", "Time", "Client", "Type", "Deleted")
+		p.println(strings.Repeat("-", 83))
+		for _, shoot := range trash.Shoots {
+			p.printf("%-4d %-10s %-11s %-25s %-12s %-16s\n",
+				shoot.Id, shoot.ShootDate.Format("02.01.2006"),
+				shoot.StartTime.Format("15:04")+"-"+shoot.EndTime.Format("15:04"),
+				shoot.ClientName(), shoot.ShootType, deletedAt(shoot.DeletedAt))
+		}
+	}
+}
+
+func deletedAt(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format("02.01.2006 15:04")
+}
+
+// balances shows the shoots that are not fully paid and what each client
+// owes in total
+func (p *presenter) balances(shoots []model.ShootBalance, clients []model.ClientBalance) {
+	if len(shoots) == 0 {
+		p.println("All shoots are paid")
+		return
+	}
+
+	p.printf("%-4s %-12s %-25s %-14s %-14s %-14s\n",
+		"ID", "Date", "Client", "Price", "Paid", "Due")
+	p.println(strings.Repeat("-", 88))
+
+	for _, balance := range shoots {
+		p.printf("%-4d %-12s %-25s %-14s %-14s %-14s\n",
+			balance.ShootId,
+			balance.ShootDate.Format("02.01.2006"),
+			balance.ClientFirstName+" "+balance.ClientLastName,
+			balance.Price.String(),
+			balance.Paid.String(),
+			balance.Due().String(),
+		)
+	}
+
+	p.println("")
+	p.println("Owed by clients:")
+	for _, balance := range clients {
+		if balance.Due().Amount <= 0 {
+			continue
+		}
+		p.printf("  #%d %s %s: %s\n", balance.ClientId, balance.FirstName, balance.LastName, balance.Due())
+	}
+}
+
+// confirmDeleteClient shows the client and asks whether to move it to the
+// trash
+func (p *presenter) confirmDeleteClient(client *model.Client) (bool, error) {
+	p.printf("Confirm deleting client: %s %s\n", client.FirstName, client.LastName)
+	p.printf("Phone number: %s\n", client.Phone)
+	if client.SocialNetworkUrl != "" {
+		p.printf("Social network url: %s\n", client.SocialNetworkUrl)
+	}
+
+	p.println("The client and all their shoots will be moved to the trash")
+	return p.prompt.Confirm("Are you sure you want to delete the client?")
+}
+
+// confirmDeleteShoot shows the shoot and asks whether to move it to the
+// trash
+func (p *presenter) confirmDeleteShoot(shoot *model.Shoot) (bool, error) {
+	p.printf("Confirm deleting shoot: %s start: %s end: %s\n",
+		shoot.ShootDate.Format("02.01.2006"),
+		shoot.StartTime.Format("15:04"),
+		shoot.EndTime.Format("15:04"))
+	p.printf("Location: %s. ShootType: %s. Price: %s\n",
+		shoot.ShootLocation, shoot.ShootType, shoot.ShootPrice)
+	p.printf("Client id: %d name: %s %s\n", shoot.ClientId,
+		shoot.Client.FirstName, shoot.Client.LastName)
+	if shoot.Notes != "" {
+		p.printf("Notes: %s\n", shoot.Notes)
+	}
+
+	return p.prompt.Confirm("Are you sure you want to delete the shoot?")
+}
+
+// conflicts shows the shoots a shoot being saved clashes with
+func (p *presenter) conflicts(err error) {
+	p.println("The shoot overlaps with:")
+
+	var conflictErr *service.ConflictError
+	if !stderrors.As(err, &conflictErr) {
+		p.println("  another shoot at the same time")
+		return
+	}
+
+	for _, shoot := range conflictErr.Shoots {
+		p.printf("  #%d %s %s-%s %s %s, %s\n", shoot.Id,
+			shoot.ShootDate.Format("02.01.2006"),
+			shoot.StartTime.Format("15:04"), shoot.EndTime.Format("15:04"),
+			shoot.Client.FirstName, shoot.Client.LastName, shoot.ShootLocation)
+	}
+}
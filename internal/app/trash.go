@@ -0,0 +1,86 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Coiiap5e/photographer/internal/errors"
+)
+
+// showTrash lists deleted clients and shoots and lets the user restore them
+// or empty the trash
+func (a *App) showTrash(ctx context.Context) error {
+	trash, err := a.trashService.List(ctx)
+	if err != nil {
+		a.prompt.Printf("Error getting trash: %v\n", err)
+		return nil
+	}
+
+	a.present.trash(trash)
+	if len(trash.Clients) == 0 && len(trash.Shoots) == 0 {
+		return nil
+	}
+
+	a.prompt.Println("1. Restore client")
+	a.prompt.Println("2. Restore shoot")
+	a.prompt.Println("3. Purge old items")
+	a.prompt.Println("0. Back")
+
+	choice, err := a.prompt.Line("Choose action")
+	if err != nil {
+		return err
+	}
+
+	switch choice {
+	case "1":
+		id, err := a.prompt.Id("ID of the client")
+		if err != nil {
+			return err
+		}
+		if err := a.trashService.RestoreClient(ctx, id); err != nil {
+			a.prompt.Printf("Error restoring client: %v\n", err)
+			return nil
+		}
+		a.prompt.Printf("client with ID: %d restored with their shoots\n", id)
+	case "2":
+		id, err := a.prompt.Id("ID of the shoot")
+		if err != nil {
+			return err
+		}
+		if err := a.trashService.RestoreShoot(ctx, id); err != nil {
+			if errors.IsErrorCode(err, errors.ErrCodeShootConflict) {
+				a.prompt.Println("The shoot overlaps a shoot booked since it was deleted")
+				return nil
+			}
+			a.prompt.Printf("Error restoring shoot: %v\n", err)
+			return nil
+		}
+		a.prompt.Printf("shoot with ID: %d restored\n", id)
+	case "3":
+		days, err := a.prompt.IntDefault("Purge items deleted more than N days ago",
+			int(a.trashService.Retention()/(24*time.Hour)))
+		if err != nil {
+			return err
+		}
+		confirmed, err := a.prompt.Confirm(fmt.Sprintf(
+			"Permanently remove items deleted more than %d days ago?", days))
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			a.prompt.Println("Purge cancelled")
+			return nil
+		}
+		clients, shoots, err := a.trashService.Purge(ctx, time.Duration(days)*24*time.Hour)
+		if err != nil {
+			a.prompt.Printf("Error purging trash: %v\n", err)
+			return nil
+		}
+		a.prompt.Printf("%d clients and %d shoots removed permanently\n", clients, shoots)
+	case "0":
+	default:
+		a.prompt.Println("Invalid choice")
+	}
+	return nil
+}
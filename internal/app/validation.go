@@ -0,0 +1,141 @@
+package app
+
+import (
+	"strings"
+	"time"
+
+	"github.com/Coiiap5e/photographer/internal/model"
+	"github.com/Coiiap5e/photographer/internal/validation"
+)
+
+// fixFields prints what is wrong with the fields of a validation error and
+// asks again for each of them with its prompt. It returns false when err is
+// not a validation error or a field has no prompt, the caller then reports
+// err as is.
+func (a *App) fixFields(err error, prompts map[string]func() error) (bool, error) {
+	fields := validation.Fields(err)
+	if len(fields) == 0 {
+		return false, nil
+	}
+	for _, field := range fields {
+		if prompts[field.Field] == nil {
+			return false, nil
+		}
+	}
+
+	for _, field := range fields {
+		a.prompt.Printf("Error: %s %s\n", strings.ReplaceAll(field.Field, "_", " "), field.Message)
+		if err := prompts[field.Field](); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// clientPrompts ask again for the fields of a new client
+func (a *App) clientPrompts(client *model.Client) map[string]func() error {
+	return map[string]func() error{
+		validation.FieldFirstName: func() (err error) {
+			client.FirstName, err = a.prompt.Required("First name")
+			return err
+		},
+		validation.FieldLastName: func() (err error) {
+			client.LastName, err = a.prompt.Required("Last name")
+			return err
+		},
+		validation.FieldPhone: func() (err error) {
+			client.Phone, err = a.prompt.Required("Phone number")
+			return err
+		},
+		validation.FieldSocialNetworkUrl: func() (err error) {
+			client.SocialNetworkUrl, err = a.prompt.Line("Social network url")
+			return err
+		},
+	}
+}
+
+// clientUpdatePrompts ask again for the changed fields of a client
+func (a *App) clientUpdatePrompts(update *model.ClientUpdate) map[string]func() error {
+	client := &model.Client{}
+	prompts := a.clientPrompts(client)
+	return map[string]func() error{
+		validation.FieldFirstName: func() error {
+			update.FirstName = &client.FirstName
+			return prompts[validation.FieldFirstName]()
+		},
+		validation.FieldLastName: func() error {
+			update.LastName = &client.LastName
+			return prompts[validation.FieldLastName]()
+		},
+		validation.FieldPhone: func() error {
+			update.Phone = &client.Phone
+			return prompts[validation.FieldPhone]()
+		},
+		validation.FieldSocialNetworkUrl: func() error {
+			update.SocialNetworkUrl = &client.SocialNetworkUrl
+			return prompts[validation.FieldSocialNetworkUrl]()
+		},
+	}
+}
+
+// shootPrompts ask again for the fields of a new shoot
+func (a *App) shootPrompts(shoot *model.Shoot) map[string]func() error {
+	return map[string]func() error{
+		validation.FieldDate: func() (err error) {
+			shoot.ShootDate, err = a.prompt.Date("Shoot date")
+			return err
+		},
+		validation.FieldStartTime: func() (err error) {
+			shoot.StartTime, err = a.prompt.Time("Start time of date", shoot.ShootDate)
+			return err
+		},
+		validation.FieldEndTime: func() (err error) {
+			shoot.EndTime, err = a.prompt.Time("End time of date", shoot.ShootDate)
+			return err
+		},
+		validation.FieldShootPrice: func() (err error) {
+			shoot.ShootPrice, err = a.prompt.Money("Shoot price", a.shootService.DefaultCurrency())
+			return err
+		},
+		validation.FieldLocation: func() (err error) {
+			shoot.ShootLocation, err = a.prompt.Required("Location")
+			return err
+		},
+		validation.FieldShootType: func() (err error) {
+			shoot.ShootType, err = a.prompt.Required("Shoot type")
+			return err
+		},
+	}
+}
+
+// shootUpdatePrompts ask again for the changed fields of a shoot on date
+func (a *App) shootUpdatePrompts(update *model.ShootUpdate, date time.Time) map[string]func() error {
+	shoot := &model.Shoot{ShootDate: date}
+	prompts := a.shootPrompts(shoot)
+	return map[string]func() error{
+		validation.FieldDate: func() error {
+			update.ShootDate = &shoot.ShootDate
+			return prompts[validation.FieldDate]()
+		},
+		validation.FieldStartTime: func() error {
+			update.StartTime = &shoot.StartTime
+			return prompts[validation.FieldStartTime]()
+		},
+		validation.FieldEndTime: func() error {
+			update.EndTime = &shoot.EndTime
+			return prompts[validation.FieldEndTime]()
+		},
+		validation.FieldShootPrice: func() error {
+			update.ShootPrice = &shoot.ShootPrice
+			return prompts[validation.FieldShootPrice]()
+		},
+		validation.FieldLocation: func() error {
+			update.ShootLocation = &shoot.ShootLocation
+			return prompts[validation.FieldLocation]()
+		},
+		validation.FieldShootType: func() error {
+			update.ShootType = &shoot.ShootType
+			return prompts[validation.FieldShootType]()
+		},
+	}
+}
@@ -0,0 +1,21 @@
+package audit
+
+import "context"
+
+type actorKey struct{}
+
+// Unknown is the actor of changes made without one in the context
+const Unknown = "unknown"
+
+// WithActor returns a context whose changes are recorded as made by actor
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// Actor returns who makes the changes in ctx
+func Actor(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey{}).(string); ok && actor != "" {
+		return actor
+	}
+	return Unknown
+}
@@ -0,0 +1,88 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/Coiiap5e/photographer/internal/errors"
+	"github.com/Coiiap5e/photographer/internal/model"
+)
+
+// Change is a column that differs between the row before and after a change
+type Change struct {
+	Field  string
+	Before string
+	After  string
+}
+
+// bookkeeping columns change with every update and are left out of Changes
+var bookkeeping = map[string]bool{
+	"id":         true,
+	"created_at": true,
+	"updated_at": true,
+	"version":    true,
+}
+
+// Changes lists the columns entry changed ordered by name. A created row
+// has all its columns changed from empty, a purged row all to empty.
+func Changes(entry model.AuditEntry) ([]Change, error) {
+	before, err := decodeRow(entry.Before)
+	if err != nil {
+		return nil, err
+	}
+	after, err := decodeRow(entry.After)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]bool)
+	for field := range before {
+		fields[field] = true
+	}
+	for field := range after {
+		fields[field] = true
+	}
+
+	var changes []Change
+	for field := range fields {
+		if bookkeeping[field] {
+			continue
+		}
+		old, updated := formatValue(before[field]), formatValue(after[field])
+		if old != updated {
+			changes = append(changes, Change{Field: field, Before: old, After: updated})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+
+	return changes, nil
+}
+
+func decodeRow(data json.RawMessage) (map[string]any, error) {
+	row := make(map[string]any)
+	if len(data) == 0 || string(data) == "null" {
+		return row, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&row); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to decode audit row")
+	}
+
+	return row, nil
+}
+
+func formatValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
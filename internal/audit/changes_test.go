@@ -0,0 +1,82 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Coiiap5e/photographer/internal/model"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestAuditTestSuit(t *testing.T) {
+	suite.Run(t, new(AuditTestSuit))
+}
+
+type AuditTestSuit struct {
+	suite.Suite
+}
+
+func (suite *AuditTestSuit) TestChanges() {
+	suite.T().Run("should list changed columns only", func(t *testing.T) {
+		// Given
+		entry := model.AuditEntry{
+			Action: model.AuditUpdate,
+			Before: []byte(`{"id": 3, "first_name": "Ivan", "phone": "+7(900)000-00-00", "version": 1}`),
+			After:  []byte(`{"id": 3, "first_name": "Ivan", "phone": "+7(901)111-11-11", "version": 2}`),
+		}
+
+		// When
+		changes, err := Changes(entry)
+
+		// Then
+		suite.NoError(err, "should not return error")
+		suite.Equal([]Change{{Field: "phone", Before: "+7(900)000-00-00", After: "+7(901)111-11-11"}},
+			changes, "should skip unchanged and bookkeeping columns")
+	})
+
+	suite.T().Run("should list all columns of created row", func(t *testing.T) {
+		// Given
+		entry := model.AuditEntry{
+			Action: model.AuditCreate,
+			After:  []byte(`{"id": 7, "shoot_price": 150050, "notes": null, "location": "Park"}`),
+		}
+
+		// When
+		changes, err := Changes(entry)
+
+		// Then
+		suite.NoError(err, "should not return error")
+		suite.Equal([]Change{
+			{Field: "location", After: "Park"},
+			{Field: "shoot_price", After: "150050"},
+		}, changes, "should compare against an empty row and keep numbers exact")
+	})
+}
+
+func (suite *AuditTestSuit) TestActor() {
+	suite.Equal(Unknown, Actor(context.Background()), "should default to unknown actor")
+	suite.Equal("anna", Actor(WithActor(context.Background(), "anna")), "should return actor from context")
+}
+
+func (suite *AuditTestSuit) TestPrint() {
+	// Given
+	entries := []model.AuditEntry{{
+		Entity:    model.AuditShoot,
+		EntityId:  4,
+		Action:    model.AuditDelete,
+		Before:    []byte(`{"id": 4, "deleted_at": null}`),
+		After:     []byte(`{"id": 4, "deleted_at": "2025-03-01T10:00:00"}`),
+		Actor:     "assistant",
+		CreatedAt: time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC),
+	}}
+	var buf bytes.Buffer
+
+	// When
+	Print(&buf, entries)
+
+	// Then
+	suite.Equal("01.03.2025 10:00:00  delete   shoot #4 by assistant\n"+
+		"    deleted_at:  -> 2025-03-01T10:00:00\n", buf.String(), "should show who changed what")
+}
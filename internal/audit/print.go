@@ -0,0 +1,38 @@
+package audit
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/Coiiap5e/photographer/internal/model"
+)
+
+// Print writes the entries with the columns each of them changed
+func Print(w io.Writer, entries []model.AuditEntry) {
+	if len(entries) == 0 {
+		fmt.Fprintln(w, "No changes recorded")
+		return
+	}
+
+	for _, entry := range entries {
+		fmt.Fprintf(w, "%s  %-8s %s #%d by %s\n", entry.CreatedAt.Format("02.01.2006 15:04:05"),
+			entry.Action, entry.Entity, entry.EntityId, entry.Actor)
+
+		changes, err := Changes(entry)
+		if err != nil {
+			fmt.Fprintf(w, "    %v\n", err)
+			continue
+		}
+
+		for _, change := range changes {
+			switch entry.Action {
+			case model.AuditCreate:
+				fmt.Fprintf(w, "    %s: %s\n", change.Field, change.After)
+			case model.AuditPurge:
+				fmt.Fprintf(w, "    %s: %s\n", change.Field, change.Before)
+			default:
+				fmt.Fprintf(w, "    %s: %s -> %s\n", change.Field, change.Before, change.After)
+			}
+		}
+	}
+}
@@ -0,0 +1,161 @@
+// Package calendar draws shoots in the terminal as a month grid or a week
+// timeline. Shoots are coloured by their type, the same type always gets
+// the same colour.
+package calendar
+
+import (
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Coiiap5e/photographer/internal/model"
+	"golang.org/x/term"
+)
+
+// Period is how much of the schedule a calendar shows
+type Period string
+
+const (
+	Month Period = "month"
+	Week  Period = "week"
+)
+
+// Range returns the first and last day of the period that contains day.
+// Weeks start on Monday.
+func (p Period) Range(day time.Time) (time.Time, time.Time) {
+	day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+	if p == Week {
+		from := day.AddDate(0, 0, -weekday(day))
+		return from, from.AddDate(0, 0, 6)
+	}
+	from := day.AddDate(0, 0, 1-day.Day())
+	return from, from.AddDate(0, 1, -1)
+}
+
+// Shift moves day by n periods, a month from the 31st ends on the last day
+// of the shorter month
+func (p Period) Shift(day time.Time, n int) time.Time {
+	if p == Week {
+		return day.AddDate(0, 0, 7*n)
+	}
+	first := time.Date(day.Year(), day.Month()+time.Month(n), 1, 0, 0, 0, 0, time.UTC)
+	last := first.AddDate(0, 1, -1).Day()
+	return first.AddDate(0, 0, min(day.Day(), last)-1)
+}
+
+// Options changes how a calendar is drawn
+type Options struct {
+	// Color paints shoots in the colour of their type. Without it the week
+	// timeline marks a shoot with the first letter of its type.
+	Color bool
+	// Today is marked in the month grid, zero marks nothing
+	Today time.Time
+	// DayStart and DayEnd are offsets from midnight the week timeline
+	// covers at least, it grows to fit earlier and later shoots
+	DayStart time.Duration
+	DayEnd   time.Duration
+}
+
+// Colorful reports whether w is a terminal that should get colours. Setting
+// NO_COLOR turns them off.
+func Colorful(w io.Writer) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	f, ok := w.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
+
+// Print draws the period that contains day with the shoots in it
+func Print(w io.Writer, period Period, day time.Time, shoots []model.Shoot, opts Options) {
+	shoots = append([]model.Shoot(nil), shoots...)
+	sort.SliceStable(shoots, func(i, j int) bool {
+		si, _ := shoots[i].Interval()
+		sj, _ := shoots[j].Interval()
+		return si.Before(sj)
+	})
+
+	if period == Week {
+		printWeek(w, day, shoots, opts)
+	} else {
+		printMonth(w, day, shoots, opts)
+	}
+
+	if opts.Color {
+		printLegend(w, shoots)
+	}
+}
+
+// weekday returns the day of the week counting from Monday as 0
+func weekday(day time.Time) int {
+	return (int(day.Weekday()) + 6) % 7
+}
+
+var weekdays = []string{"Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun"}
+
+// colors are the ANSI foreground colours shoot types are spread over
+var colors = []int{31, 32, 33, 34, 35, 36, 91, 92, 93, 94, 95, 96}
+
+// paint colours s by shootType when colours are on
+func paint(s, shootType string, opts Options) string {
+	if !opts.Color || s == "" {
+		return s
+	}
+	h := fnv.New32a()
+	h.Write([]byte(strings.ToLower(strings.TrimSpace(shootType))))
+	return fmt.Sprintf("\x1b[%dm%s\x1b[0m", colors[h.Sum32()%uint32(len(colors))], s)
+}
+
+func printLegend(w io.Writer, shoots []model.Shoot) {
+	seen := map[string]bool{}
+	var types []string
+	for _, shoot := range shoots {
+		key := strings.ToLower(strings.TrimSpace(shoot.ShootType))
+		if !seen[key] {
+			seen[key] = true
+			types = append(types, shoot.ShootType)
+		}
+	}
+	if len(types) == 0 {
+		return
+	}
+
+	sort.Strings(types)
+	parts := make([]string, len(types))
+	for i, shootType := range types {
+		parts[i] = paint("■", shootType, Options{Color: true}) + " " + shootType
+	}
+	fmt.Fprintln(w, strings.Join(parts, "  "))
+}
+
+// byDate groups shoots by their date
+func byDate(shoots []model.Shoot) map[string][]model.Shoot {
+	days := map[string][]model.Shoot{}
+	for _, shoot := range shoots {
+		key := shoot.ShootDate.Format(time.DateOnly)
+		days[key] = append(days[key], shoot)
+	}
+	return days
+}
+
+// pad cuts s to width runes, marking the cut with an ellipsis, and fills
+// the rest with spaces
+func pad(s string, width int) string {
+	if n := utf8.RuneCountInString(s); n > width {
+		s = string([]rune(s)[:width-1]) + "…"
+	}
+	return s + strings.Repeat(" ", width-utf8.RuneCountInString(s))
+}
+
+func center(s string, width int) string {
+	n := utf8.RuneCountInString(s)
+	if n >= width {
+		return s
+	}
+	return strings.Repeat(" ", (width-n)/2) + s
+}
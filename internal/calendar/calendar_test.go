@@ -0,0 +1,149 @@
+package calendar
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Coiiap5e/photographer/internal/model"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestCalendarTestSuit(t *testing.T) {
+	suite.Run(t, new(CalendarTestSuit))
+}
+
+type CalendarTestSuit struct {
+	suite.Suite
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func shoot(day time.Time, start, end, shootType string) model.Shoot {
+	startTime, _ := time.Parse("15:04", start)
+	endTime, _ := time.Parse("15:04", end)
+	return model.Shoot{ShootDate: day, StartTime: startTime, EndTime: endTime, ShootType: shootType,
+		ShootLocation: "Park", Client: model.Client{FirstName: "Anna", LastName: "Ivanova"}}
+}
+
+func (suite *CalendarTestSuit) TestRange() {
+	suite.T().Run("should return the month of the day", func(t *testing.T) {
+		// When
+		from, to := Month.Range(date(2024, time.February, 14))
+
+		// Then
+		suite.Equal(date(2024, time.February, 1), from, "should start on the 1st")
+		suite.Equal(date(2024, time.February, 29), to, "should end on the last day of a leap February")
+	})
+	suite.T().Run("should return the week from Monday", func(t *testing.T) {
+		// When
+		from, to := Week.Range(date(2031, time.June, 22))
+
+		// Then
+		suite.Equal(date(2031, time.June, 16), from, "should start on Monday")
+		suite.Equal(date(2031, time.June, 22), to, "should end on Sunday")
+	})
+}
+
+func (suite *CalendarTestSuit) TestShift() {
+	suite.Equal(date(2025, time.February, 28), Month.Shift(date(2025, time.January, 31), 1),
+		"should keep the next month when it is shorter")
+	suite.Equal(date(2024, time.December, 15), Month.Shift(date(2025, time.January, 15), -1),
+		"should go back across the year")
+	suite.Equal(date(2031, time.June, 27), Week.Shift(date(2031, time.June, 20), 1),
+		"should move a week")
+}
+
+func (suite *CalendarTestSuit) TestMonth() {
+	// Given
+	day := date(2031, time.June, 20)
+	shoots := []model.Shoot{
+		shoot(day, "17:00", "18:00", "portrait"),
+		shoot(day, "10:00", "12:00", "portrait"),
+		shoot(day, "13:00", "14:00", "family"),
+		shoot(day, "15:00", "16:00", "love story"),
+		shoot(date(2031, time.June, 18), "19:00", "01:00", "wedding"),
+	}
+	var buf bytes.Buffer
+
+	// When
+	Print(&buf, Month, day, shoots, Options{Today: date(2031, time.June, 18)})
+
+	// Then
+	output := buf.String()
+	suite.Contains(output, "June 2031", "should name the month")
+	suite.Contains(output, "│          │          │          │          │          │          │ 1        │",
+		"should start June 2031 on Sunday")
+	suite.Contains(output, "│[18]      │", "should mark today")
+	suite.Contains(output, "│19:00 wed…│          │10:00 por…│", "should list shoots in time order")
+	suite.Contains(output, "│ +2 more  │", "should count shoots that do not fit")
+	suite.Contains(output, "│ 30       │", "should end with the last day")
+	suite.NotContains(output, "\x1b[", "should not colour without Color")
+}
+
+func (suite *CalendarTestSuit) TestWeek() {
+	suite.T().Run("should draw shoots on a timeline", func(t *testing.T) {
+		// Given
+		day := date(2031, time.June, 20)
+		shoots := []model.Shoot{
+			shoot(day, "10:00", "12:00", "portrait"),
+			shoot(day, "11:30", "13:00", "family"),
+			shoot(date(2031, time.June, 22), "06:00", "07:00", "sunrise"),
+		}
+		var buf bytes.Buffer
+
+		// When
+		Print(&buf, Week, day, shoots, Options{DayStart: 9 * time.Hour, DayEnd: 18 * time.Hour})
+
+		// Then
+		lines := strings.Split(buf.String(), "\n")
+		suite.Equal("Week 25: 16.06.2031 - 22.06.2031", lines[0], "should name the week")
+		suite.True(strings.HasPrefix(lines[1], "          06  07  08  09"),
+			"should start the timeline at the earliest shoot")
+		suite.Equal("Fri 20.06 ·   ·   ·   ·   PPPPPP!!FFFF·   ·   ·   ·   ·", lines[6],
+			"should mark shoots by type and the overlap with !")
+		suite.Equal("Sun 22.06 SSSS·   ·   ·   ·   ·   ·   ·   ·   ·   ·   ·", lines[8],
+			"should draw the early shoot")
+		suite.Contains(buf.String(), "Fri 20.06  11:30-13:00  family  Anna Ivanova, Park",
+			"should list the shoots in full")
+	})
+	suite.T().Run("should say when the week is free", func(t *testing.T) {
+		// Given
+		var buf bytes.Buffer
+
+		// When
+		Print(&buf, Week, date(2031, time.June, 20), nil, Options{})
+
+		// Then
+		suite.Contains(buf.String(), "          08  09  10  11  12  13  14  15  16  17  18  19\n",
+			"should show 8 to 20 without working hours")
+		suite.Contains(buf.String(), "No shoots this week", "should report the free week")
+	})
+}
+
+func (suite *CalendarTestSuit) TestColor() {
+	// Given
+	day := date(2031, time.June, 20)
+	shoots := []model.Shoot{
+		shoot(day, "10:00", "11:00", "Portrait"),
+		shoot(day.AddDate(0, 0, 1), "10:00", "11:00", "portrait "),
+		shoot(day, "12:00", "13:00", "wedding"),
+	}
+	var buf bytes.Buffer
+
+	// When
+	Print(&buf, Week, day, shoots, Options{Color: true})
+
+	// Then
+	portrait := paint("x", "portrait", Options{Color: true})
+	suite.Equal(portrait, paint("x", " PORTRAIT", Options{Color: true}),
+		"should give a type the same colour whatever its case")
+	suite.Contains(buf.String(), "████", "should fill shoots with blocks")
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	legend := lines[len(lines)-1]
+	suite.Contains(legend, "■\x1b[0m wedding", "should add a legend")
+	suite.Equal(1, strings.Count(strings.ToLower(legend), "portrait"), "should list a type once in the legend")
+}
@@ -0,0 +1,100 @@
+package calendar
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/Coiiap5e/photographer/internal/model"
+)
+
+const (
+	// cellWidth fits a start time and the beginning of the type
+	cellWidth = 10
+	// cellShoots is how many shoots a day of the month shows, the last
+	// line counts the rest when there are more
+	cellShoots = 3
+)
+
+// printMonth draws the month of day as a grid of weeks, every day lists
+// the start time and type of its shoots
+func printMonth(w io.Writer, day time.Time, shoots []model.Shoot, opts Options) {
+	from, to := Month.Range(day)
+	start := from.AddDate(0, 0, -weekday(from))
+	days := byDate(shoots)
+	width := 7*(cellWidth+1) + 1
+
+	fmt.Fprintln(w, center(from.Format("January 2006"), width))
+
+	header := make([]string, len(weekdays))
+	for i, name := range weekdays {
+		header[i] = pad(" "+name, cellWidth)
+	}
+	fmt.Fprintln(w, " "+strings.Join(header, " "))
+	fmt.Fprintln(w, border("┌", "┬", "┐"))
+
+	for week := start; !week.After(to); week = week.AddDate(0, 0, 7) {
+		lines := 1
+		for i := 0; i < 7; i++ {
+			lines = max(lines, min(len(days[week.AddDate(0, 0, i).Format(time.DateOnly)]), cellShoots))
+		}
+
+		row := make([]string, 7)
+		for i := range row {
+			date := week.AddDate(0, 0, i)
+			if date.Month() == from.Month() {
+				row[i] = pad(dayNumber(date, opts.Today), cellWidth)
+			} else {
+				row[i] = pad("", cellWidth)
+			}
+		}
+		fmt.Fprintln(w, "│"+strings.Join(row, "│")+"│")
+
+		for line := 0; line < lines; line++ {
+			for i := range row {
+				date := week.AddDate(0, 0, i)
+				row[i] = pad("", cellWidth)
+				if date.Month() == from.Month() {
+					row[i] = cellLine(days[date.Format(time.DateOnly)], line, opts)
+				}
+			}
+			fmt.Fprintln(w, "│"+strings.Join(row, "│")+"│")
+		}
+
+		if week.AddDate(0, 0, 7).After(to) {
+			fmt.Fprintln(w, border("└", "┴", "┘"))
+		} else {
+			fmt.Fprintln(w, border("├", "┼", "┤"))
+		}
+	}
+}
+
+func border(left, middle, right string) string {
+	cells := make([]string, 7)
+	for i := range cells {
+		cells[i] = strings.Repeat("─", cellWidth)
+	}
+	return left + strings.Join(cells, middle) + right
+}
+
+// dayNumber marks today with brackets
+func dayNumber(date, today time.Time) string {
+	if !today.IsZero() && date.Format(time.DateOnly) == today.Format(time.DateOnly) {
+		return fmt.Sprintf("[%d]", date.Day())
+	}
+	return fmt.Sprintf(" %d", date.Day())
+}
+
+// cellLine returns line of a day cell with shoots
+func cellLine(shoots []model.Shoot, line int, opts Options) string {
+	if len(shoots) > cellShoots && line == cellShoots-1 {
+		return pad(fmt.Sprintf(" +%d more", len(shoots)-line), cellWidth)
+	}
+	if line >= len(shoots) {
+		return pad("", cellWidth)
+	}
+
+	shoot := shoots[line]
+	return paint(pad(shoot.StartTime.Format("15:04")+" "+shoot.ShootType, cellWidth), shoot.ShootType, opts)
+}
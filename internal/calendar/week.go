@@ -0,0 +1,148 @@
+package calendar
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/Coiiap5e/photographer/internal/model"
+)
+
+const (
+	// slotMinutes is how long one character of the timeline is
+	slotMinutes  = 15
+	slotsPerHour = 60 / slotMinutes
+	// labelWidth fits "Mon 20.01 "
+	labelWidth = 10
+)
+
+// printWeek draws the week of day as one timeline per day and lists its
+// shoots below. Overlapping shoots are marked with !.
+func printWeek(w io.Writer, day time.Time, shoots []model.Shoot, opts Options) {
+	from, to := Week.Range(day)
+	_, number := from.ISOWeek()
+	days := byDate(shoots)
+
+	fmt.Fprintf(w, "Week %d: %s - %s\n", number, from.Format("02.01.2006"), to.Format("02.01.2006"))
+
+	first, last := hours(shoots, opts)
+
+	var header strings.Builder
+	header.WriteString(strings.Repeat(" ", labelWidth))
+	for hour := first; hour < last; hour++ {
+		header.WriteString(pad(fmt.Sprintf("%02d", hour), slotsPerHour))
+	}
+	fmt.Fprintln(w, strings.TrimRight(header.String(), " "))
+
+	for date := from; !date.After(to); date = date.AddDate(0, 0, 1) {
+		label := pad(weekdays[weekday(date)]+" "+date.Format("02.01"), labelWidth)
+		fmt.Fprintln(w, label+timeline(days[date.Format(time.DateOnly)], first, last, opts))
+	}
+
+	fmt.Fprintln(w)
+	if len(shoots) == 0 {
+		fmt.Fprintln(w, "No shoots this week")
+		return
+	}
+
+	for _, shoot := range shoots {
+		where := shoot.ClientName()
+		if shoot.ShootLocation != "" {
+			where += ", " + shoot.ShootLocation
+		}
+		fmt.Fprintf(w, "%s %s  %s-%s  %s  %s\n",
+			weekdays[weekday(shoot.ShootDate)], shoot.ShootDate.Format("02.01"),
+			shoot.StartTime.Format("15:04"), shoot.EndTime.Format("15:04"),
+			paint(shoot.ShootType, shoot.ShootType, opts), where)
+	}
+}
+
+// hours returns the first and the end hour of the timeline, wide enough
+// for the working day and every shoot
+func hours(shoots []model.Shoot, opts Options) (int, int) {
+	first, last := int(opts.DayStart/time.Hour), int((opts.DayEnd+time.Hour-1)/time.Hour)
+	if last <= first {
+		first, last = 8, 20
+	}
+
+	for _, shoot := range shoots {
+		start, end := minutes(shoot)
+		first = min(first, start/60)
+		last = max(last, (end+59)/60)
+	}
+
+	return first, min(last, 24)
+}
+
+// minutes returns when shoot starts and ends in minutes from midnight of
+// its date, a shoot that goes on after midnight ends at midnight
+func minutes(shoot model.Shoot) (int, int) {
+	start := shoot.StartTime.Hour()*60 + shoot.StartTime.Minute()
+	end := shoot.EndTime.Hour()*60 + shoot.EndTime.Minute()
+	if end <= start {
+		end = 24 * 60
+	}
+	return start, end
+}
+
+// timeline draws the shoots of one day from hour first to last
+func timeline(shoots []model.Shoot, first, last int, opts Options) string {
+	slots := make([]int, (last-first)*slotsPerHour)
+	for i := range slots {
+		slots[i] = -1
+	}
+
+	const overlap = -2
+	for i, shoot := range shoots {
+		start, end := minutes(shoot)
+		from := max((start-first*60)/slotMinutes, 0)
+		to := min((end-first*60+slotMinutes-1)/slotMinutes, len(slots))
+		for slot := from; slot < to; slot++ {
+			if slots[slot] == -1 {
+				slots[slot] = i
+			} else {
+				slots[slot] = overlap
+			}
+		}
+	}
+
+	var b strings.Builder
+	for start := 0; start < len(slots); {
+		end := start + 1
+		for end < len(slots) && slots[end] == slots[start] {
+			end++
+		}
+
+		switch owner := slots[start]; owner {
+		case -1:
+			for slot := start; slot < end; slot++ {
+				if slot%slotsPerHour == 0 {
+					b.WriteString("·")
+				} else {
+					b.WriteString(" ")
+				}
+			}
+		case overlap:
+			b.WriteString(strings.Repeat("!", end-start))
+		default:
+			shootType := shoots[owner].ShootType
+			b.WriteString(paint(strings.Repeat(mark(shootType, opts), end-start), shootType, opts))
+		}
+		start = end
+	}
+
+	return strings.TrimRight(b.String(), " ")
+}
+
+// mark is the character a shoot fills the timeline with
+func mark(shootType string, opts Options) string {
+	if opts.Color {
+		return "█"
+	}
+	for _, r := range strings.TrimSpace(shootType) {
+		return string(unicode.ToUpper(r))
+	}
+	return "#"
+}
@@ -0,0 +1,830 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Coiiap5e/photographer/internal/audit"
+	"github.com/Coiiap5e/photographer/internal/calendar"
+	"github.com/Coiiap5e/photographer/internal/errors"
+	"github.com/Coiiap5e/photographer/internal/export"
+	"github.com/Coiiap5e/photographer/internal/ical"
+	"github.com/Coiiap5e/photographer/internal/importer"
+	"github.com/Coiiap5e/photographer/internal/model"
+	"github.com/Coiiap5e/photographer/internal/service"
+	"github.com/Coiiap5e/photographer/internal/utils"
+)
+
+// Exit codes returned by the non-interactive commands
+const (
+	ExitOK       = 0
+	ExitError    = 1
+	ExitUsage    = 2
+	ExitNotFound = 3
+	ExitDB       = 4
+	ExitConfig   = 5
+	ExitConflict = 6
+)
+
+const usage = `usage:
+  client add --first-name NAME --last-name NAME --phone PHONE [--social-url URL]
+  client rm ID --yes
+  client restore ID
+  client history ID
+  client ls
+  client import FILE.csv [--map field=column,...] [--delimiter ;] [--dry-run]
+  client export [--format csv|xlsx] [--columns KEY,...] [--from DD.MM.YYYY]
+                [--to DD.MM.YYYY] [--locale ru|en|iso] [--out FILE]
+  shoot add --client-id ID --date DD.MM.YYYY --start HH:MM --end HH:MM
+            --price AMOUNT[ CUR] --location TEXT --type TEXT [--notes TEXT] [--force]
+  shoot rm ID --yes
+  shoot restore ID
+  shoot history ID
+  shoot ls [--from DD.MM.YYYY] [--to DD.MM.YYYY]
+  shoot export [--format ics|csv|xlsx] [--columns KEY,...] [--from DD.MM.YYYY]
+               [--to DD.MM.YYYY] [--locale ru|en|iso] [--out FILE]
+  trash ls
+  trash purge [--older-than DAYS] --yes
+  slots --from DD.MM.YYYY --to DD.MM.YYYY --duration 1h30m [--day-start HH:MM]
+        [--day-end HH:MM] [--buffer 30m] [--days-off sat,sun]
+        [--dates-off DD.MM.YYYY,...] [--out FILE]
+  calendar [--week] [--date DD.MM.YYYY] [--no-color]
+  migrate up | down N | status | goto VERSION
+  serve [--addr HOST:PORT] [--calendar-token TOKEN]
+
+--demo before a command, or alone for the menu, works on sample data kept in
+memory instead of the database. --tui alone opens the full-screen interface
+instead of the menu.`
+
+type CLI struct {
+	clientService service.Client
+	shootService  service.Shoot
+	trashService  service.Trash
+	auditService  service.Audit
+	out           io.Writer
+}
+
+func New(clientService service.Client, shootService service.Shoot, trashService service.Trash,
+	auditService service.Audit, out io.Writer) *CLI {
+	return &CLI{
+		clientService: clientService,
+		shootService:  shootService,
+		trashService:  trashService,
+		auditService:  auditService,
+		out:           out,
+	}
+}
+
+// ExitCode maps the error returned by Run to a process exit code
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+
+	switch errors.Code(err) {
+	case errors.ErrCodeValidation, errors.ErrCodeInvalidInput:
+		return ExitUsage
+	case errors.ErrCodeClientNotFound, errors.ErrCodeShootNotFound:
+		return ExitNotFound
+	case errors.ErrCodeDBConnection, errors.ErrCodeDBQuery, errors.ErrCodeDBInsert,
+		errors.ErrCodeDBDelete, errors.ErrCodeDBSelect, errors.ErrCodeMigration:
+		return ExitDB
+	case errors.ErrCodeConfig:
+		return ExitConfig
+	case errors.ErrCodeShootConflict, errors.ErrCodeVersionConflict:
+		return ExitConflict
+	default:
+		return ExitError
+	}
+}
+
+func Usage() string {
+	return usage
+}
+
+func (c *CLI) Run(ctx context.Context, args []string) error {
+	if len(args) > 0 && args[0] == "slots" {
+		return c.findFreeSlots(ctx, args[1:])
+	}
+
+	if len(args) > 0 && args[0] == "calendar" {
+		return c.showCalendar(ctx, args[1:])
+	}
+
+	if len(args) < 2 {
+		return usageError()
+	}
+
+	switch args[0] + " " + args[1] {
+	case "client add":
+		return c.addClient(ctx, args[2:])
+	case "client rm":
+		return c.deleteClient(ctx, args[2:])
+	case "client restore":
+		return c.restoreClient(ctx, args[2:])
+	case "client history":
+		return c.showHistory(ctx, model.AuditClient, args[2:])
+	case "client ls":
+		return c.listClients(ctx)
+	case "client import":
+		return c.importClients(ctx, args[2:])
+	case "client export":
+		return c.exportClients(ctx, args[2:])
+	case "shoot add":
+		return c.addShoot(ctx, args[2:])
+	case "shoot rm":
+		return c.deleteShoot(ctx, args[2:])
+	case "shoot restore":
+		return c.restoreShoot(ctx, args[2:])
+	case "shoot history":
+		return c.showHistory(ctx, model.AuditShoot, args[2:])
+	case "shoot ls":
+		return c.listShoots(ctx, args[2:])
+	case "shoot export":
+		return c.exportShoots(ctx, args[2:])
+	case "trash ls":
+		return c.listTrash(ctx)
+	case "trash purge":
+		return c.purgeTrash(ctx, args[2:])
+	default:
+		return usageError()
+	}
+}
+
+func (c *CLI) findFreeSlots(ctx context.Context, args []string) error {
+	fs := newFlagSet("slots")
+	fromFlag := fs.String("from", "", "first day (dd.mm.yyyy)")
+	toFlag := fs.String("to", "", "last day (dd.mm.yyyy)")
+	duration := fs.Duration("duration", 0, "shoot duration")
+	dayStart := fs.String("day-start", "", "working day start (hh:mm)")
+	dayEnd := fs.String("day-end", "", "working day end (hh:mm)")
+	buffer := fs.String("buffer", "", "break between shoots")
+	daysOff := fs.String("days-off", "", "weekly days off, e.g. sat,sun")
+	datesOff := fs.String("dates-off", "", "other days off (dd.mm.yyyy, comma separated)")
+	out := fs.String("out", "", "write slots to file instead of stdout")
+
+	if _, err := parse(fs, args); err != nil {
+		return err
+	}
+
+	if err := required(map[string]string{"from": *fromFlag, "to": *toFlag}); err != nil {
+		return err
+	}
+
+	from, err := utils.ParseDate(*fromFlag)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInvalidInput, "--from must use format dd.mm.yyyy")
+	}
+
+	to, err := utils.ParseDate(*toFlag)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInvalidInput, "--to must use format dd.mm.yyyy")
+	}
+
+	query := c.shootService.DefaultSlotQuery(from, to)
+	query.Duration = *duration
+
+	if *dayStart != "" {
+		if query.DayStart, err = utils.ParseClock(*dayStart); err != nil {
+			return errors.Wrap(err, errors.ErrCodeInvalidInput, "--day-start must use format hh:mm")
+		}
+	}
+
+	if *dayEnd != "" {
+		if query.DayEnd, err = utils.ParseClock(*dayEnd); err != nil {
+			return errors.Wrap(err, errors.ErrCodeInvalidInput, "--day-end must use format hh:mm")
+		}
+	}
+
+	if *buffer != "" {
+		if query.Buffer, err = time.ParseDuration(*buffer); err != nil {
+			return errors.Wrap(err, errors.ErrCodeInvalidInput, "--buffer must be a duration like 30m")
+		}
+	}
+
+	if *daysOff != "" {
+		if query.DaysOff, err = utils.ParseWeekdays(*daysOff); err != nil {
+			return errors.Wrap(err, errors.ErrCodeInvalidInput, "--days-off must list weekdays")
+		}
+	}
+
+	if query.DatesOff, err = utils.ParseDateList(*datesOff); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInvalidInput, "--dates-off must use format dd.mm.yyyy")
+	}
+
+	slots, err := c.shootService.FindFreeSlots(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	return c.writeOutput(*out, func(w io.Writer) error {
+		for _, slot := range slots {
+			if _, err := fmt.Fprintln(w, slot); err != nil {
+				return errors.Wrap(err, errors.ErrCodeInternal, "failed to write free slots")
+			}
+		}
+		return nil
+	})
+}
+
+func (c *CLI) addClient(ctx context.Context, args []string) error {
+	fs := newFlagSet("client add")
+	firstName := fs.String("first-name", "", "client first name")
+	lastName := fs.String("last-name", "", "client last name")
+	phone := fs.String("phone", "", "client phone number")
+	socialUrl := fs.String("social-url", "", "client social network url")
+
+	if _, err := parse(fs, args); err != nil {
+		return err
+	}
+
+	if err := required(map[string]string{
+		"first-name": *firstName, "last-name": *lastName, "phone": *phone,
+	}); err != nil {
+		return err
+	}
+
+	client := &model.Client{
+		FirstName:        *firstName,
+		LastName:         *lastName,
+		Phone:            *phone,
+		SocialNetworkUrl: *socialUrl,
+	}
+
+	if err := c.clientService.CreateClient(ctx, client); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.out, "client added with ID: %d\n", client.Id)
+
+	return nil
+}
+
+func (c *CLI) deleteClient(ctx context.Context, args []string) error {
+	fs := newFlagSet("client rm")
+	yes := fs.Bool("yes", false, "confirm deletion")
+
+	id, err := parseId(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if !*yes {
+		return errors.New(errors.ErrCodeValidation, "deletion not confirmed: pass --yes")
+	}
+
+	if err := c.clientService.DeleteClient(ctx, id); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.out, "client %d moved to trash\n", id)
+
+	return nil
+}
+
+func (c *CLI) restoreClient(ctx context.Context, args []string) error {
+	id, err := parseId(newFlagSet("client restore"), args)
+	if err != nil {
+		return err
+	}
+
+	if err := c.trashService.RestoreClient(ctx, id); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.out, "client %d restored\n", id)
+
+	return nil
+}
+
+func (c *CLI) importClients(ctx context.Context, args []string) error {
+	fs := newFlagSet("client import")
+	mappingFlag := fs.String("map", "", "column mapping, e.g. first_name=Имя,phone=Телефон")
+	delimiter := fs.String("delimiter", "", "column delimiter, detected from the header by default")
+	dryRun := fs.Bool("dry-run", false, "only report what would be imported")
+
+	positional, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if len(positional) != 1 {
+		return errors.New(errors.ErrCodeInvalidInput, "client import expects exactly one file")
+	}
+
+	mapping, err := importer.ParseMapping(*mappingFlag)
+	if err != nil {
+		return err
+	}
+
+	opts := importer.Options{Mapping: mapping}
+	if *delimiter != "" {
+		runes := []rune(*delimiter)
+		if len(runes) != 1 {
+			return errors.New(errors.ErrCodeInvalidInput, "--delimiter must be a single character")
+		}
+		opts.Delimiter = runes[0]
+	}
+
+	file, err := os.Open(positional[0])
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInvalidInput, "failed to open CSV file")
+	}
+	defer file.Close()
+
+	report, err := c.clientService.ImportClients(ctx, file, opts, *dryRun)
+	if err != nil {
+		return err
+	}
+
+	report.Print(c.out)
+
+	if *dryRun {
+		fmt.Fprintln(c.out, "dry run, no clients added")
+	} else {
+		fmt.Fprintf(c.out, "%d clients added\n", len(report.New))
+	}
+
+	return nil
+}
+
+func (c *CLI) listClients(ctx context.Context) error {
+	clients, err := c.clientService.ListClients(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(c.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tFirst Name\tLast Name\tPhone\tSocial Network\tCreated")
+	for _, client := range clients {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n",
+			client.Id, client.FirstName, client.LastName, client.Phone,
+			client.SocialNetworkUrl, client.CreatedAt.Format("02.01.2006"))
+	}
+
+	return w.Flush()
+}
+
+func (c *CLI) addShoot(ctx context.Context, args []string) error {
+	fs := newFlagSet("shoot add")
+	clientId := fs.Int("client-id", 0, "client ID")
+	date := fs.String("date", "", "shoot date (dd.mm.yyyy)")
+	start := fs.String("start", "", "start time (hh:mm)")
+	end := fs.String("end", "", "end time (hh:mm)")
+	price := fs.String("price", "0", "shoot price, e.g. 1500.50 or \"20 USD\"")
+	location := fs.String("location", "", "shoot location")
+	shootType := fs.String("type", "", "shoot type")
+	notes := fs.String("notes", "", "notes")
+	force := fs.Bool("force", false, "book even if the shoot overlaps another one")
+
+	if _, err := parse(fs, args); err != nil {
+		return err
+	}
+
+	if err := required(map[string]string{
+		"date": *date, "start": *start, "end": *end,
+		"location": *location, "type": *shootType,
+	}); err != nil {
+		return err
+	}
+
+	if *clientId <= 0 {
+		return errors.New(errors.ErrCodeInvalidInput, "--client-id must be > 0")
+	}
+
+	shootDate, err := utils.ParseDate(*date)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInvalidInput, "--date must use format dd.mm.yyyy")
+	}
+
+	startTime, err := utils.ParseTime(shootDate, *start)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInvalidInput, "--start must use format hh:mm")
+	}
+
+	endTime, err := utils.ParseTime(shootDate, *end)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInvalidInput, "--end must use format hh:mm")
+	}
+
+	shootPrice, err := model.ParseMoney(*price, c.shootService.DefaultCurrency())
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInvalidInput, "--price must be an amount, e.g. 1500.50 or \"20 USD\"")
+	}
+
+	shoot := &model.Shoot{
+		ClientId:      *clientId,
+		ShootDate:     shootDate,
+		StartTime:     startTime,
+		EndTime:       endTime,
+		ShootPrice:    shootPrice,
+		ShootLocation: *location,
+		ShootType:     *shootType,
+		Notes:         *notes,
+		AllowOverlap:  *force,
+	}
+
+	if err := c.shootService.CreateShoot(ctx, shoot); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.out, "shoot added with ID: %d\n", shoot.Id)
+
+	return nil
+}
+
+func (c *CLI) deleteShoot(ctx context.Context, args []string) error {
+	fs := newFlagSet("shoot rm")
+	yes := fs.Bool("yes", false, "confirm deletion")
+
+	id, err := parseId(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if !*yes {
+		return errors.New(errors.ErrCodeValidation, "deletion not confirmed: pass --yes")
+	}
+
+	if err := c.shootService.DeleteShoot(ctx, id); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.out, "shoot %d moved to trash\n", id)
+
+	return nil
+}
+
+func (c *CLI) restoreShoot(ctx context.Context, args []string) error {
+	id, err := parseId(newFlagSet("shoot restore"), args)
+	if err != nil {
+		return err
+	}
+
+	if err := c.trashService.RestoreShoot(ctx, id); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.out, "shoot %d restored\n", id)
+
+	return nil
+}
+
+func (c *CLI) listShoots(ctx context.Context, args []string) error {
+	fs := newFlagSet("shoot ls")
+	fromFlag := fs.String("from", "", "first date (dd.mm.yyyy)")
+	toFlag := fs.String("to", "", "last date (dd.mm.yyyy)")
+
+	if _, err := parse(fs, args); err != nil {
+		return err
+	}
+
+	from, to, err := parseRange(*fromFlag, *toFlag)
+	if err != nil {
+		return err
+	}
+
+	shoots, err := c.shootService.ListShoots(ctx, from, to)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(c.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tClient ID\tDate\tStart\tEnd\tPrice\tLocation\tFirst name\tLast name\tType\tNotes")
+	for _, shoot := range shoots {
+		fmt.Fprintf(w, "%d\t%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			shoot.Id, shoot.ClientId, shoot.ShootDate.Format("02.01.2006"),
+			shoot.StartTime.Format("15:04"), shoot.EndTime.Format("15:04"),
+			shoot.ShootPrice, shoot.ShootLocation, shoot.Client.FirstName,
+			shoot.Client.LastName, shoot.ShootType, shoot.Notes)
+	}
+
+	return w.Flush()
+}
+
+// showCalendar draws the month or the week that contains --date, today by
+// default. Colours are used only when writing to a terminal.
+func (c *CLI) showCalendar(ctx context.Context, args []string) error {
+	fs := newFlagSet("calendar")
+	week := fs.Bool("week", false, "show a week timeline instead of the month")
+	dateFlag := fs.String("date", "", "a day of the period to show (dd.mm.yyyy)")
+	noColor := fs.Bool("no-color", false, "do not colour shoots by type")
+
+	if _, err := parse(fs, args); err != nil {
+		return err
+	}
+
+	day := time.Now()
+	if *dateFlag != "" {
+		var err error
+		if day, err = utils.ParseDate(*dateFlag); err != nil {
+			return errors.Wrap(err, errors.ErrCodeInvalidInput, "--date must use format dd.mm.yyyy")
+		}
+	}
+
+	period := calendar.Month
+	if *week {
+		period = calendar.Week
+	}
+
+	from, to := period.Range(day)
+	shoots, err := c.shootService.ListShoots(ctx, from, to)
+	if err != nil {
+		return err
+	}
+
+	query := c.shootService.DefaultSlotQuery(from, to)
+	calendar.Print(c.out, period, day, shoots, calendar.Options{
+		Color:    !*noColor && calendar.Colorful(c.out),
+		Today:    time.Now(),
+		DayStart: query.DayStart,
+		DayEnd:   query.DayEnd,
+	})
+
+	return nil
+}
+
+// showHistory prints the audit log of a client or shoot, which is kept
+// after it is deleted
+func (c *CLI) showHistory(ctx context.Context, entity model.AuditEntity, args []string) error {
+	id, err := parseId(newFlagSet(string(entity)+" history"), args)
+	if err != nil {
+		return err
+	}
+
+	entries, err := c.auditService.History(ctx, entity, id)
+	if err != nil {
+		return err
+	}
+
+	audit.Print(c.out, entries)
+
+	return nil
+}
+
+func (c *CLI) listTrash(ctx context.Context) error {
+	trash, err := c.trashService.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(c.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Kind\tID\tName\tDate\tDeleted")
+	for _, client := range trash.Clients {
+		fmt.Fprintf(w, "client\t%d\t%s %s\t\t%s\n", client.Id, client.FirstName,
+			client.LastName, client.DeletedAt.Format("02.01.2006 15:04"))
+	}
+	for _, shoot := range trash.Shoots {
+		fmt.Fprintf(w, "shoot\t%d\t%s\t%s\t%s\n", shoot.Id, shoot.ClientName(),
+			shoot.ShootDate.Format("02.01.2006"), shoot.DeletedAt.Format("02.01.2006 15:04"))
+	}
+
+	return w.Flush()
+}
+
+func (c *CLI) purgeTrash(ctx context.Context, args []string) error {
+	fs := newFlagSet("trash purge")
+	days := fs.Int("older-than", int(c.trashService.Retention()/(24*time.Hour)),
+		"remove items deleted more than this many days ago")
+	yes := fs.Bool("yes", false, "confirm purge")
+
+	positional, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if len(positional) != 0 {
+		return errors.New(errors.ErrCodeInvalidInput, "trash purge takes no arguments")
+	}
+
+	if *days < 0 {
+		return errors.New(errors.ErrCodeInvalidInput, "--older-than must not be negative")
+	}
+
+	if !*yes {
+		return errors.New(errors.ErrCodeValidation, "purge not confirmed: pass --yes")
+	}
+
+	clients, shoots, err := c.trashService.Purge(ctx, time.Duration(*days)*24*time.Hour)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.out, "%d clients and %d shoots removed permanently\n", clients, shoots)
+
+	return nil
+}
+
+func (c *CLI) exportShoots(ctx context.Context, args []string) error {
+	fs := newFlagSet("shoot export")
+	format := fs.String("format", "", "ics, csv or xlsx, taken from --out by default")
+	columns := fs.String("columns", "", "comma separated columns for csv and xlsx: "+
+		strings.Join(export.ShootColumns(), ","))
+	fromFlag := fs.String("from", "", "first date (dd.mm.yyyy)")
+	toFlag := fs.String("to", "", "last date (dd.mm.yyyy)")
+	locale := fs.String("locale", "", "date and number format for csv and xlsx: ru, en or iso")
+	out := fs.String("out", "", "write to file instead of stdout")
+
+	if _, err := parse(fs, args); err != nil {
+		return err
+	}
+
+	from, to, err := parseRange(*fromFlag, *toFlag)
+	if err != nil {
+		return err
+	}
+
+	shoots, err := c.shootService.ListShoots(ctx, from, to)
+	if err != nil {
+		return err
+	}
+
+	calendar := strings.EqualFold(*format, "ics") ||
+		*format == "" && (*out == "" || strings.EqualFold(filepath.Ext(*out), ".ics"))
+	if calendar {
+		return c.writeOutput(*out, func(w io.Writer) error {
+			if err := ical.Encode(w, "Shoots", shoots); err != nil {
+				return errors.Wrap(err, errors.ErrCodeInternal, "failed to write calendar")
+			}
+			return nil
+		})
+	}
+
+	spreadsheet, err := export.DetectFormat(*format, *out)
+	if err != nil {
+		return err
+	}
+
+	loc, err := export.LookupLocale(*locale)
+	if err != nil {
+		return err
+	}
+
+	table, err := export.Shoots(shoots, export.ParseColumns(*columns))
+	if err != nil {
+		return err
+	}
+
+	return c.writeOutput(*out, func(w io.Writer) error {
+		return export.Write(w, spreadsheet, table, loc)
+	})
+}
+
+func (c *CLI) exportClients(ctx context.Context, args []string) error {
+	fs := newFlagSet("client export")
+	format := fs.String("format", "", "csv or xlsx, taken from --out by default")
+	columns := fs.String("columns", "", "comma separated columns: "+
+		strings.Join(export.ClientColumns(), ","))
+	fromFlag := fs.String("from", "", "first creation date (dd.mm.yyyy)")
+	toFlag := fs.String("to", "", "last creation date (dd.mm.yyyy)")
+	locale := fs.String("locale", "", "date and number format: ru, en or iso")
+	out := fs.String("out", "", "write to file instead of stdout")
+
+	if _, err := parse(fs, args); err != nil {
+		return err
+	}
+
+	from, to, err := parseRange(*fromFlag, *toFlag)
+	if err != nil {
+		return err
+	}
+
+	spreadsheet, err := export.DetectFormat(*format, *out)
+	if err != nil {
+		return err
+	}
+
+	loc, err := export.LookupLocale(*locale)
+	if err != nil {
+		return err
+	}
+
+	clients, err := c.clientService.ListClients(ctx)
+	if err != nil {
+		return err
+	}
+
+	table, err := export.Clients(clients, export.ParseColumns(*columns), from, to)
+	if err != nil {
+		return err
+	}
+
+	return c.writeOutput(*out, func(w io.Writer) error {
+		return export.Write(w, spreadsheet, table, loc)
+	})
+}
+
+// writeOutput calls write with the file at path, or with stdout if path
+// is empty
+func (c *CLI) writeOutput(path string, write func(w io.Writer) error) error {
+	if path == "" {
+		return write(c.out)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to create output file")
+	}
+	defer file.Close()
+
+	if err := write(file); err != nil {
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to write output file")
+	}
+
+	return nil
+}
+
+// parseRange parses optional --from and --to dates, a missing date leaves
+// that side of the range open
+func parseRange(fromFlag, toFlag string) (time.Time, time.Time, error) {
+	var from, to time.Time
+	var err error
+
+	if fromFlag != "" {
+		if from, err = utils.ParseDate(fromFlag); err != nil {
+			return from, to, errors.Wrap(err, errors.ErrCodeInvalidInput, "--from must use format dd.mm.yyyy")
+		}
+	}
+
+	if toFlag != "" {
+		if to, err = utils.ParseDate(toFlag); err != nil {
+			return from, to, errors.Wrap(err, errors.ErrCodeInvalidInput, "--to must use format dd.mm.yyyy")
+		}
+	}
+
+	return from, to, nil
+}
+
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	return fs
+}
+
+// parse parses flags that may be interleaved with positional arguments
+// (e.g. "rm 5 --yes") and returns the positional ones.
+func parse(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInvalidInput, "invalid arguments for "+fs.Name())
+		}
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+func parseId(fs *flag.FlagSet, args []string) (int, error) {
+	positional, err := parse(fs, args)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(positional) != 1 {
+		return 0, errors.New(errors.ErrCodeInvalidInput, fs.Name()+" expects exactly one ID")
+	}
+
+	id, err := strconv.Atoi(positional[0])
+	if err != nil || id <= 0 {
+		return 0, errors.New(errors.ErrCodeInvalidInput, "ID must be a number > 0")
+	}
+
+	return id, nil
+}
+
+func required(values map[string]string) error {
+	var missing []string
+	for name, value := range values {
+		if strings.TrimSpace(value) == "" {
+			missing = append(missing, "--"+name)
+		}
+	}
+
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return errors.New(errors.ErrCodeInvalidInput, "missing required flags: "+strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+func usageError() error {
+	return errors.New(errors.ErrCodeInvalidInput, "unknown command\n"+usage)
+}
@@ -0,0 +1,224 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Coiiap5e/photographer/internal/config"
+	"github.com/Coiiap5e/photographer/internal/errors"
+	"github.com/Coiiap5e/photographer/internal/repository"
+	"github.com/Coiiap5e/photographer/internal/service"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestCLITestSuit(t *testing.T) {
+	suite.Run(t, new(CLITestSuit))
+}
+
+type CLITestSuit struct {
+	suite.Suite
+	cli *CLI
+	out *bytes.Buffer
+	// day is the date of the shoot, a Monday a week or more ahead
+	day string
+}
+
+// SetupTest runs the commands on in-memory storage holding one client and
+// one shoot on day from 11:00 to 12:00
+func (suite *CLITestSuit) SetupTest() {
+	store := repository.NewMemoryStore()
+	clientRepo := repository.NewMemoryClient(store)
+	shootRepo := repository.NewMemoryShoot(store)
+
+	suite.out = &bytes.Buffer{}
+	suite.cli = New(
+		service.NewClient(clientRepo),
+		service.NewShoot(shootRepo, clientRepo,
+			config.ScheduleConfig{WorkDayStart: 10 * time.Hour, WorkDayEnd: 14 * time.Hour},
+			config.MoneyConfig{DefaultCurrency: "RUB"}),
+		service.NewTrash(store, clientRepo, shootRepo, config.TrashConfig{Retention: 30 * 24 * time.Hour}),
+		service.NewAudit(repository.NewMemoryAudit(store)),
+		suite.out,
+	)
+
+	monday := time.Now().AddDate(0, 0, 7+(8-int(time.Now().Weekday()))%7)
+	suite.day = monday.Format("02.01.2006")
+
+	ctx := context.Background()
+	suite.Require().NoError(suite.cli.Run(ctx, []string{"client", "add",
+		"--first-name", "Anna", "--last-name", "Ivanova", "--phone", "+79990001122"}),
+		"precondition: client should be added")
+	suite.Require().NoError(suite.cli.Run(ctx, []string{"shoot", "add", "--client-id", "1",
+		"--date", suite.day, "--start", "11:00", "--end", "12:00", "--price", "1000",
+		"--location", "Pushkin blvd", "--type", "love story"}),
+		"precondition: shoot should be added")
+	suite.out.Reset()
+}
+
+func (suite *CLITestSuit) TestRun() {
+	dir := suite.T().TempDir()
+	before := "Mon " + suite.day + " 10:00-11:00"
+	after := "Mon " + suite.day + " 12:00-14:00"
+
+	tests := []struct {
+		description string
+		args        []string
+		code        int
+		// output is checked in the file given by --out, or in stdout if empty
+		file     string
+		contains []string
+	}{
+		{
+			description: "should export shoots as a calendar to stdout",
+			args:        []string{"shoot", "export"},
+			contains:    []string{"BEGIN:VCALENDAR", "LOCATION:Pushkin blvd"},
+		},
+		{
+			description: "should export shoots as a calendar to --out",
+			args:        []string{"shoot", "export", "--out", filepath.Join(dir, "shoots.ics")},
+			file:        filepath.Join(dir, "shoots.ics"),
+			contains:    []string{"BEGIN:VCALENDAR", "LOCATION:Pushkin blvd"},
+		},
+		{
+			description: "should export shoots as CSV to --out",
+			args:        []string{"shoot", "export", "--out", filepath.Join(dir, "shoots.csv")},
+			file:        filepath.Join(dir, "shoots.csv"),
+			contains:    []string{"Pushkin blvd", "love story"},
+		},
+		{
+			description: "should print the free slots around the shoot",
+			args:        []string{"slots", "--from", suite.day, "--to", suite.day, "--duration", "1h"},
+			contains:    []string{before, after},
+		},
+		{
+			description: "should write the free slots to --out",
+			args: []string{"slots", "--from", suite.day, "--to", suite.day, "--duration", "1h",
+				"--out", filepath.Join(dir, "slots.txt")},
+			file:     filepath.Join(dir, "slots.txt"),
+			contains: []string{before, after},
+		},
+		{
+			description: "should exit with 2 when the slot duration is missing",
+			args:        []string{"slots", "--from", suite.day, "--to", suite.day},
+			code:        ExitUsage,
+		},
+		{
+			description: "should exit with 2 when a required flag is missing",
+			args:        []string{"client", "add", "--first-name", "Oleg", "--last-name", "Petrov"},
+			code:        ExitUsage,
+		},
+		{
+			description: "should exit with 2 when the shoot ends when it starts",
+			args: []string{"shoot", "add", "--client-id", "1", "--date", suite.day,
+				"--start", "11:00", "--end", "11:00", "--location", "Park", "--type", "portrait"},
+			code: ExitUsage,
+		},
+		{
+			description: "should exit with 6 when the shoot overlaps another one",
+			args: []string{"shoot", "add", "--client-id", "1", "--date", suite.day,
+				"--start", "11:30", "--end", "12:30", "--location", "Park", "--type", "portrait"},
+			code: ExitConflict,
+		},
+		{
+			description: "should exit with 2 on an unknown command",
+			args:        []string{"shoot", "move"},
+			code:        ExitUsage,
+		},
+	}
+
+	for _, test := range tests {
+		suite.T().Run(test.description, func(t *testing.T) {
+			// Given
+			suite.out.Reset()
+
+			// When
+			err := suite.cli.Run(context.Background(), test.args)
+
+			// Then
+			suite.Equal(test.code, ExitCode(err), "should exit with %d, error: %v", test.code, err)
+
+			output := suite.out.String()
+			if test.file != "" {
+				suite.Empty(output, "should not print to stdout")
+				content, err := os.ReadFile(test.file)
+				suite.Require().NoError(err, "should write the output file")
+				output = string(content)
+			}
+			for _, part := range test.contains {
+				suite.Contains(output, part, "should write %q", part)
+			}
+		})
+	}
+}
+
+func (suite *CLITestSuit) TestExitCode() {
+	suite.Equal(ExitOK, ExitCode(nil), "nil error should exit with 0")
+	suite.Equal(ExitNotFound, ExitCode(errors.Wrap(
+		errors.New(errors.ErrCodeClientNotFound, "client not found"),
+		errors.ErrCodeClientNotFound, "client not found")), "not found should exit with 3")
+	suite.Equal(ExitUsage, ExitCode(errors.New(errors.ErrCodeInvalidInput, "bad flag")),
+		"invalid input should exit with 2")
+	suite.Equal(ExitDB, ExitCode(errors.New(errors.ErrCodeDBSelect, "select failed")),
+		"database errors should exit with 4")
+	suite.Equal(ExitError, ExitCode(errors.New(errors.ErrCodeShootCreate, "insert failed")),
+		"other errors should exit with 1")
+}
+
+func (suite *CLITestSuit) TestParseId() {
+	suite.T().Run("should accept flags after the ID", func(t *testing.T) {
+		// Given
+		fs := newFlagSet("client rm")
+		yes := fs.Bool("yes", false, "")
+
+		// When
+		id, err := parseId(fs, []string{"5", "--yes"})
+
+		// Then
+		suite.NoError(err, "should not return error")
+		suite.Equal(5, id, "should parse ID")
+		suite.True(*yes, "should parse flag after ID")
+	})
+	suite.T().Run("should reject non-numeric ID", func(t *testing.T) {
+		// Given
+		fs := newFlagSet("client rm")
+
+		// When
+		_, err := parseId(fs, []string{"abc"})
+
+		// Then
+		suite.True(errors.IsErrorCode(err, errors.ErrCodeInvalidInput), "should return invalid input error")
+	})
+}
+
+func (suite *CLITestSuit) TestRequired() {
+	// When
+	err := required(map[string]string{"phone": "", "first-name": " ", "last-name": "Ivanov"})
+
+	// Then
+	suite.True(errors.IsErrorCode(err, errors.ErrCodeInvalidInput), "should return invalid input error")
+	suite.ErrorContains(err, "--first-name, --phone", "should list missing flags")
+}
+
+func (suite *CLITestSuit) TestParseRange() {
+	suite.T().Run("should leave missing dates open", func(t *testing.T) {
+		// When
+		from, to, err := parseRange("20.01.2025", "")
+
+		// Then
+		suite.NoError(err, "should not return error")
+		suite.Equal("2025-01-20", from.Format("2006-01-02"), "should parse from date")
+		suite.True(to.IsZero(), "should leave to date open")
+	})
+	suite.T().Run("should reject invalid date", func(t *testing.T) {
+		// When
+		_, _, err := parseRange("", "2025-01-20")
+
+		// Then
+		suite.True(errors.IsErrorCode(err, errors.ErrCodeInvalidInput), "should return invalid input error")
+		suite.ErrorContains(err, "--to", "should name the flag")
+	})
+}
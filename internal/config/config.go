@@ -3,17 +3,33 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/Coiiap5e/photographer/internal/errors"
+	"github.com/Coiiap5e/photographer/internal/model"
+	"github.com/Coiiap5e/photographer/internal/utils"
 	"github.com/joho/godotenv"
 )
 
+// Database drivers APP_DB_DRIVER selects
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
 type DbConfig struct {
+	// Driver is DriverPostgres or DriverSQLite
+	Driver   string `json:"driver"`
 	Host     string `json:"host"`
 	Port     int    `json:"port"`
 	Username string `json:"username"`
 	Password string `json:"password"`
 	Database string `json:"database"`
+	// Path is the database file of SQLite
+	Path string `json:"path"`
+	// AutoMigrate applies pending migrations on startup
+	AutoMigrate bool `json:"auto_migrate"`
 }
 
 func LoadDBConfig() (DbConfig, error) {
@@ -23,6 +39,27 @@ func LoadDBConfig() (DbConfig, error) {
 		)
 	}
 
+	autoMigrate, err := strconv.ParseBool(getEnv("APP_DB_AUTO_MIGRATE", "false"))
+	if err != nil {
+		return DbConfig{}, errors.New(
+			errors.ErrCodeConfig, "APP_DB_AUTO_MIGRATE must be a boolean",
+		)
+	}
+
+	switch getEnv("APP_DB_DRIVER", DriverPostgres) {
+	case DriverPostgres:
+	case DriverSQLite:
+		return DbConfig{
+			Driver:      DriverSQLite,
+			Path:        getEnv("APP_DB_PATH", "photographer.db"),
+			AutoMigrate: autoMigrate,
+		}, nil
+	default:
+		return DbConfig{}, errors.New(
+			errors.ErrCodeConfig, "APP_DB_DRIVER must be postgres or sqlite",
+		)
+	}
+
 	if os.Getenv("APP_DB_HOST") == "" {
 		return DbConfig{}, errors.New(
 			errors.ErrCodeConfig, "APP_DB_HOST is required",
@@ -57,14 +94,114 @@ func LoadDBConfig() (DbConfig, error) {
 	}
 
 	return DbConfig{
-		Host:     getEnv("APP_DB_HOST", "localhost"),
-		Port:     port,
-		Username: os.Getenv("APP_DB_USER"),
-		Password: os.Getenv("APP_DB_PASS"),
-		Database: os.Getenv("APP_DB_NAME"),
+		Driver:      DriverPostgres,
+		Host:        getEnv("APP_DB_HOST", "localhost"),
+		Port:        port,
+		Username:    os.Getenv("APP_DB_USER"),
+		Password:    os.Getenv("APP_DB_PASS"),
+		Database:    os.Getenv("APP_DB_NAME"),
+		AutoMigrate: autoMigrate,
 	}, nil
 }
 
+type ScheduleConfig struct {
+	// ShootBuffer is the minimum gap kept between two shoots,
+	// e.g. for travelling between locations
+	ShootBuffer time.Duration `json:"shoot_buffer"`
+	// WorkDayStart and WorkDayEnd are offsets from midnight
+	WorkDayStart time.Duration  `json:"work_day_start"`
+	WorkDayEnd   time.Duration  `json:"work_day_end"`
+	DaysOff      []time.Weekday `json:"days_off"`
+}
+
+// LoadScheduleConfig reads scheduling settings from the environment.
+// It expects the .env file to be loaded by LoadDBConfig already.
+func LoadScheduleConfig() (ScheduleConfig, error) {
+	bufferMinutes, err := strconv.Atoi(getEnv("APP_SHOOT_BUFFER_MINUTES", "0"))
+	if err != nil || bufferMinutes < 0 {
+		return ScheduleConfig{}, errors.New(
+			errors.ErrCodeConfig, "APP_SHOOT_BUFFER_MINUTES must be a non-negative integer",
+		)
+	}
+
+	dayStart, err := utils.ParseClock(getEnv("APP_WORK_DAY_START", "10:00"))
+	if err != nil {
+		return ScheduleConfig{}, errors.New(
+			errors.ErrCodeConfig, "APP_WORK_DAY_START must use format hh:mm",
+		)
+	}
+
+	dayEnd, err := utils.ParseClock(getEnv("APP_WORK_DAY_END", "20:00"))
+	if err != nil {
+		return ScheduleConfig{}, errors.New(
+			errors.ErrCodeConfig, "APP_WORK_DAY_END must use format hh:mm",
+		)
+	}
+
+	daysOff, err := utils.ParseWeekdays(os.Getenv("APP_DAYS_OFF"))
+	if err != nil {
+		return ScheduleConfig{}, errors.New(
+			errors.ErrCodeConfig, "APP_DAYS_OFF must list weekdays, e.g. sat,sun",
+		)
+	}
+
+	return ScheduleConfig{
+		ShootBuffer:  time.Duration(bufferMinutes) * time.Minute,
+		WorkDayStart: dayStart,
+		WorkDayEnd:   dayEnd,
+		DaysOff:      daysOff,
+	}, nil
+}
+
+type MoneyConfig struct {
+	// DefaultCurrency is the ISO 4217 code used for prices entered without one
+	DefaultCurrency string `json:"default_currency"`
+}
+
+// LoadMoneyConfig reads money settings from the environment.
+// It expects the .env file to be loaded by LoadDBConfig already.
+func LoadMoneyConfig() (MoneyConfig, error) {
+	currency := strings.ToUpper(getEnv("APP_CURRENCY", "RUB"))
+	if !model.ValidCurrency(currency) {
+		return MoneyConfig{}, errors.New(
+			errors.ErrCodeConfig, "APP_CURRENCY must be an ISO 4217 code, e.g. RUB",
+		)
+	}
+
+	return MoneyConfig{DefaultCurrency: currency}, nil
+}
+
+type TrashConfig struct {
+	// Retention is how long deleted clients and shoots are kept before a
+	// purge removes them for good
+	Retention time.Duration `json:"retention"`
+}
+
+// LoadTrashConfig reads trash settings from the environment.
+// It expects the .env file to be loaded by LoadDBConfig already.
+func LoadTrashConfig() (TrashConfig, error) {
+	days, err := strconv.Atoi(getEnv("APP_TRASH_RETENTION_DAYS", "30"))
+	if err != nil || days < 0 {
+		return TrashConfig{}, errors.New(
+			errors.ErrCodeConfig, "APP_TRASH_RETENTION_DAYS must be a non-negative integer",
+		)
+	}
+
+	return TrashConfig{Retention: time.Duration(days) * 24 * time.Hour}, nil
+}
+
+type AuditConfig struct {
+	// Actor is the name changes are recorded under in the audit log
+	Actor string `json:"actor"`
+}
+
+// LoadAuditConfig reads audit settings from the environment, the actor
+// defaults to the operating system user.
+// It expects the .env file to be loaded by LoadDBConfig already.
+func LoadAuditConfig() AuditConfig {
+	return AuditConfig{Actor: getEnv("APP_ACTOR", os.Getenv("USER"))}
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -0,0 +1,147 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Coiiap5e/photographer/internal/errors"
+	"modernc.org/sqlite"
+)
+
+func init() {
+	// lower() of SQLite only folds ASCII letters, unicode_lower folds all of
+	// them so Cyrillic names and places match whatever their case
+	sqlite.MustRegisterDeterministicScalarFunction("unicode_lower", 1,
+		func(ctx *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+			switch value := args[0].(type) {
+			case string:
+				return strings.ToLower(value), nil
+			case []byte:
+				return strings.ToLower(string(value)), nil
+			default:
+				return value, nil
+			}
+		})
+}
+
+// SQLite is a database kept in one file, for a single user without a
+// database server
+type SQLite struct {
+	DB *sql.DB
+}
+
+// SQLiteQuerier runs queries, it is either the database or the transaction
+// of a context
+type SQLiteQuerier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// NewSQLite opens the database file at path, creating it if needed
+func NewSQLite(ctx context.Context, path string) (*SQLite, error) {
+	dsn := "file:" + path +
+		"?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeDBConnection, "failed to open database")
+	}
+
+	// SQLite allows one writer at a time, a single connection also keeps
+	// a transaction and the queries joining it on the same connection
+	db.SetMaxOpenConns(1)
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, errors.Wrap(err, errors.ErrCodeDBConnection, "error opening database")
+	}
+
+	return &SQLite{DB: db}, nil
+}
+
+func (db *SQLite) Close() {
+	if db.DB != nil {
+		db.DB.Close()
+	}
+}
+
+type sqliteTxKey struct{}
+
+// sqliteTx is the transaction of a context and how many savepoints deep
+// the context is in it
+type sqliteTx struct {
+	tx    *sql.Tx
+	depth int
+}
+
+// WithTx runs fn in a transaction carried by the context fn gets, the same
+// way as DB.WithTx
+func (db *SQLite) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if outer, ok := ctx.Value(sqliteTxKey{}).(*sqliteTx); ok {
+		return outer.savepoint(ctx, fn)
+	}
+
+	tx, err := db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeDBQuery, "failed to begin transaction")
+	}
+
+	// a deferred rollback also runs while panicking and does nothing after
+	// a commit
+	defer tx.Rollback()
+
+	if err := fn(context.WithValue(ctx, sqliteTxKey{}, &sqliteTx{tx: tx})); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, errors.ErrCodeDBQuery, "failed to commit transaction")
+	}
+
+	return nil
+}
+
+// savepoint runs fn in a savepoint of t
+func (t *sqliteTx) savepoint(ctx context.Context, fn func(ctx context.Context) error) error {
+	inner := &sqliteTx{tx: t.tx, depth: t.depth + 1}
+	name := fmt.Sprintf("sp%d", inner.depth)
+
+	if _, err := t.tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return errors.Wrap(err, errors.ErrCodeDBQuery, "failed to begin transaction")
+	}
+
+	released := false
+	defer func() {
+		if !released {
+			t.tx.ExecContext(context.WithoutCancel(ctx), "ROLLBACK TO "+name+"; RELEASE "+name)
+		}
+	}()
+
+	if err := fn(context.WithValue(ctx, sqliteTxKey{}, inner)); err != nil {
+		return err
+	}
+
+	if _, err := t.tx.ExecContext(ctx, "RELEASE "+name); err != nil {
+		return errors.Wrap(err, errors.ErrCodeDBQuery, "failed to commit transaction")
+	}
+	released = true
+
+	return nil
+}
+
+// Conn returns the transaction of ctx started by WithTx, or the database
+// when there is none
+func (db *SQLite) Conn(ctx context.Context) SQLiteQuerier {
+	if tx, ok := ctx.Value(sqliteTxKey{}).(*sqliteTx); ok {
+		return tx.tx
+	}
+	return db.DB
+}
@@ -0,0 +1,64 @@
+package database
+
+import (
+	"context"
+
+	"github.com/Coiiap5e/photographer/internal/errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Querier runs queries, it is either the pool or the transaction of a
+// context
+type Querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// Transactor runs a unit of work in a transaction
+type Transactor interface {
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type txKey struct{}
+
+// WithTx runs fn in a transaction carried by the context fn gets, so
+// repositories called with that context join it. The transaction is
+// committed if fn returns nil and rolled back if it returns an error or
+// panics. Nested calls run in a savepoint of the outer transaction.
+func (db *DB) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	var tx pgx.Tx
+	var err error
+	if outer, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		tx, err = outer.Begin(ctx)
+	} else {
+		tx, err = db.Pool.Begin(ctx)
+	}
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeDBQuery, "failed to begin transaction")
+	}
+
+	// a deferred rollback also runs while panicking and does nothing after
+	// a commit
+	defer tx.Rollback(context.WithoutCancel(ctx))
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return errors.Wrap(err, errors.ErrCodeDBQuery, "failed to commit transaction")
+	}
+
+	return nil
+}
+
+// Conn returns the transaction of ctx started by WithTx, or the pool when
+// there is none
+func (db *DB) Conn(ctx context.Context) Querier {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return tx
+	}
+	return db.Pool
+}
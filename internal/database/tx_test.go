@@ -0,0 +1,128 @@
+package database_test
+
+import (
+	"context"
+	stderrors "errors"
+	"log"
+	"testing"
+
+	"github.com/Coiiap5e/photographer/internal/database"
+	"github.com/Coiiap5e/photographer/testutils"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestTxTestSuit(t *testing.T) {
+	suite.Run(t, new(TxTestSuit))
+}
+
+type TxTestSuit struct {
+	suite.Suite
+	ctx context.Context
+	tdb *testutils.TestDB
+	db  *database.DB
+}
+
+func (suite *TxTestSuit) SetupSuite() {
+	suite.ctx = context.Background()
+
+	var err error
+
+	suite.tdb, err = testutils.CreateTestDB(suite.ctx)
+	suite.Require().NoError(err, "Failed to setup test database")
+	suite.db = suite.tdb.GetDB()
+}
+
+func (suite *TxTestSuit) SetupTest() {
+	suite.Require().NoError(suite.tdb.CleanTables(suite.ctx))
+}
+
+func (suite *TxTestSuit) TearDownSuite() {
+	if suite.tdb != nil {
+		if err := suite.tdb.Cleanup(suite.ctx); err != nil {
+			log.Fatalf("failed to cleanup test database: %v", err)
+		}
+	}
+}
+
+func (suite *TxTestSuit) addClient(ctx context.Context, name string) error {
+	_, err := suite.db.Conn(ctx).Exec(ctx,
+		"INSERT INTO clients (first_name, last_name, phone) VALUES ($1, 'Test', '+7(900)000-00-00')", name)
+	return err
+}
+
+func (suite *TxTestSuit) clientNames() []string {
+	rows, err := suite.db.Pool.Query(suite.ctx, "SELECT first_name FROM clients ORDER BY id")
+	suite.Require().NoError(err)
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		suite.Require().NoError(rows.Scan(&name))
+		names = append(names, name)
+	}
+	return names
+}
+
+func (suite *TxTestSuit) TestWithTx() {
+	suite.T().Run("should commit when fn succeeds", func(t *testing.T) {
+		// When
+		err := suite.db.WithTx(suite.ctx, func(ctx context.Context) error {
+			return suite.addClient(ctx, "Committed")
+		})
+
+		// Then
+		suite.NoError(err, "should not return error")
+		suite.Contains(suite.clientNames(), "Committed", "should keep the changes")
+	})
+
+	suite.T().Run("should roll back when fn fails", func(t *testing.T) {
+		// Given
+		failure := stderrors.New("second step failed")
+
+		// When
+		err := suite.db.WithTx(suite.ctx, func(ctx context.Context) error {
+			if err := suite.addClient(ctx, "RolledBack"); err != nil {
+				return err
+			}
+			return failure
+		})
+
+		// Then
+		suite.ErrorIs(err, failure, "should return the error of fn")
+		suite.NotContains(suite.clientNames(), "RolledBack", "should undo the first step")
+	})
+
+	suite.T().Run("should roll back when fn panics", func(t *testing.T) {
+		// When
+		suite.Panics(func() {
+			_ = suite.db.WithTx(suite.ctx, func(ctx context.Context) error {
+				_ = suite.addClient(ctx, "Panicked")
+				panic("boom")
+			})
+		}, "should let the panic through")
+
+		// Then
+		suite.NotContains(suite.clientNames(), "Panicked", "should undo the changes")
+	})
+
+	suite.T().Run("should roll back only the failed nested transaction", func(t *testing.T) {
+		// When
+		err := suite.db.WithTx(suite.ctx, func(ctx context.Context) error {
+			if err := suite.addClient(ctx, "Outer"); err != nil {
+				return err
+			}
+			_ = suite.db.WithTx(ctx, func(ctx context.Context) error {
+				_ = suite.addClient(ctx, "Inner")
+				return stderrors.New("inner failed")
+			})
+			return nil
+		})
+
+		// Then
+		suite.NoError(err, "should not return error")
+		names := suite.clientNames()
+		suite.Contains(names, "Outer", "should commit the outer transaction")
+		suite.NotContains(names, "Inner", "should roll back to the savepoint")
+	})
+}
@@ -15,12 +15,14 @@ const (
 	ErrCodeDBInsert     ErrorCode = "DB_INSERT_ERROR"
 	ErrCodeDBDelete     ErrorCode = "DB_DELETE_ERROR"
 	ErrCodeDBSelect     ErrorCode = "DB_SELECT_ERROR"
+	ErrCodeMigration    ErrorCode = "MIGRATION_ERROR"
 
 	// Client operations
 
 	ErrCodeClientNotFound ErrorCode = "CLIENT_NOT_FOUND"
 	ErrCodeClientCreate   ErrorCode = "CLIENT_CREATE_ERROR"
 	ErrCodeClientDelete   ErrorCode = "CLIENT_DELETE_ERROR"
+	ErrCodeClientUpdate   ErrorCode = "CLIENT_UPDATE_ERROR"
 	ErrCodeClientList     ErrorCode = "CLIENT_LIST_ERROR"
 
 	// Shoot operations
@@ -28,7 +30,18 @@ const (
 	ErrCodeShootNotFound ErrorCode = "SHOOT_NOT_FOUND"
 	ErrCodeShootCreate   ErrorCode = "SHOOT_CREATE_ERROR"
 	ErrCodeShootDelete   ErrorCode = "SHOOT_DELETE_ERROR"
+	ErrCodeShootUpdate   ErrorCode = "SHOOT_UPDATE_ERROR"
 	ErrCodeShootList     ErrorCode = "SHOOT_LIST_ERROR"
+	ErrCodeShootConflict ErrorCode = "SHOOT_CONFLICT"
+
+	// Payment operations
+
+	ErrCodePaymentCreate ErrorCode = "PAYMENT_CREATE_ERROR"
+	ErrCodePaymentList   ErrorCode = "PAYMENT_LIST_ERROR"
+
+	// Record was changed by someone else since it was read
+
+	ErrCodeVersionConflict ErrorCode = "VERSION_CONFLICT"
 
 	// Validation
 
@@ -38,6 +51,10 @@ const (
 	//Configuration
 
 	ErrCodeConfig ErrorCode = "CONFIG_ERROR"
+
+	// Unexpected failures
+
+	ErrCodeInternal ErrorCode = "INTERNAL_ERROR"
 )
 
 type AppError struct {
@@ -53,6 +70,10 @@ func (e *AppError) Error() string {
 	return fmt.Sprintf("[%s] %s", e.Code, e.Message)
 }
 
+func (e *AppError) Unwrap() error {
+	return e.Err
+}
+
 func New(code ErrorCode, message string) *AppError {
 	return &AppError{Code: code, Message: message}
 }
@@ -68,3 +89,13 @@ func IsErrorCode(err error, code ErrorCode) bool {
 	}
 	return false
 }
+
+// Code returns the code of the outermost AppError in the chain, or an empty
+// code if err is not an AppError.
+func Code(err error) ErrorCode {
+	var appErr *AppError
+	if errors.As(err, &appErr) {
+		return appErr.Code
+	}
+	return ""
+}
@@ -0,0 +1,75 @@
+package export
+
+import (
+	"time"
+
+	"github.com/Coiiap5e/photographer/internal/model"
+)
+
+var clientColumns = []Column[model.Client]{
+	{"id", "ID", func(c model.Client) Cell { return number(c.Id) }},
+	{"first_name", "First Name", func(c model.Client) Cell { return text(c.FirstName) }},
+	{"last_name", "Last Name", func(c model.Client) Cell { return text(c.LastName) }},
+	{"phone", "Phone", func(c model.Client) Cell { return text(c.Phone) }},
+	{"social_url", "Social Network", func(c model.Client) Cell { return text(c.SocialNetworkUrl) }},
+	{"created_at", "Created", func(c model.Client) Cell { return dateTime(c.CreatedAt) }},
+}
+
+var shootColumns = []Column[model.Shoot]{
+	{"id", "ID", func(s model.Shoot) Cell { return number(s.Id) }},
+	{"date", "Date", func(s model.Shoot) Cell { return date(s.ShootDate) }},
+	{"start", "Start", func(s model.Shoot) Cell { return clock(s.StartTime) }},
+	{"end", "End", func(s model.Shoot) Cell { return clock(s.EndTime) }},
+	{"type", "Type", func(s model.Shoot) Cell { return text(s.ShootType) }},
+	{"location", "Location", func(s model.Shoot) Cell { return text(s.ShootLocation) }},
+	{"price", "Price", func(s model.Shoot) Cell { return money(s.ShootPrice) }},
+	{"currency", "Currency", func(s model.Shoot) Cell { return text(s.ShootPrice.Currency) }},
+	{"notes", "Notes", func(s model.Shoot) Cell { return text(s.Notes) }},
+	{"client_id", "Client ID", func(s model.Shoot) Cell { return number(s.ClientId) }},
+	{"client_first_name", "First Name", func(s model.Shoot) Cell { return text(s.Client.FirstName) }},
+	{"client_last_name", "Last Name", func(s model.Shoot) Cell { return text(s.Client.LastName) }},
+	{"client_phone", "Phone", func(s model.Shoot) Cell { return text(s.Client.Phone) }},
+	{"client_social_url", "Social Network", func(s model.Shoot) Cell { return text(s.Client.SocialNetworkUrl) }},
+	{"created_at", "Created", func(s model.Shoot) Cell { return dateTime(s.CreatedAt) }},
+}
+
+// ClientColumns returns the keys of the client columns in default order
+func ClientColumns() []string {
+	return columnKeys(clientColumns)
+}
+
+// ShootColumns returns the keys of the shoot columns in default order
+func ShootColumns() []string {
+	return columnKeys(shootColumns)
+}
+
+// Clients builds a table of clients created between from and to, a zero
+// time leaves that side open
+func Clients(clients []model.Client, keys []string, from, to time.Time) (*Table, error) {
+	var selected []model.Client
+	for _, client := range clients {
+		if inRange(client.CreatedAt, from, to) {
+			selected = append(selected, client)
+		}
+	}
+
+	return buildTable("Clients", clientColumns, keys, selected)
+}
+
+// Shoots builds a table of shoots with their clients. Shoots are expected
+// to be filtered by date already.
+func Shoots(shoots []model.Shoot, keys []string) (*Table, error) {
+	return buildTable("Shoots", shootColumns, keys, shoots)
+}
+
+// inRange compares calendar days, so to includes the whole last day
+func inRange(t, from, to time.Time) bool {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	if !from.IsZero() && day.Before(from) {
+		return false
+	}
+	if !to.IsZero() && day.After(to) {
+		return false
+	}
+	return true
+}
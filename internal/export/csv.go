@@ -0,0 +1,67 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/Coiiap5e/photographer/internal/errors"
+)
+
+// WriteCSV writes table as CSV with a UTF-8 BOM, without it Excel reads
+// Cyrillic text as gibberish
+func WriteCSV(w io.Writer, table *Table, locale Locale) error {
+	if _, err := io.WriteString(w, "\ufeff"); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to write CSV")
+	}
+
+	writer := csv.NewWriter(w)
+	writer.Comma = locale.Delimiter
+	writer.UseCRLF = true
+
+	if err := writer.Write(table.Header); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to write CSV")
+	}
+
+	record := make([]string, len(table.Header))
+	for _, row := range table.Rows {
+		for i, cell := range row {
+			record[i] = formatCell(cell, locale)
+		}
+		if err := writer.Write(record); err != nil {
+			return errors.Wrap(err, errors.ErrCodeInternal, "failed to write CSV")
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to write CSV")
+	}
+
+	return nil
+}
+
+func formatCell(cell Cell, locale Locale) string {
+	switch cell.Kind {
+	case NumberCell:
+		return strconv.FormatInt(cell.Number, 10)
+	case DateCell:
+		return formatTime(cell, locale.DateLayout)
+	case TimeCell:
+		return formatTime(cell, "15:04")
+	case DateTimeCell:
+		return formatTime(cell, locale.DateTimeLayout)
+	case MoneyCell:
+		return strings.Replace(cell.Money.Decimal(), ".", locale.DecimalSeparator, 1)
+	default:
+		return cell.Text
+	}
+}
+
+func formatTime(cell Cell, layout string) string {
+	if cell.Time.IsZero() {
+		return ""
+	}
+	return cell.Time.Format(layout)
+}
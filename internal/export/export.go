@@ -0,0 +1,230 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Coiiap5e/photographer/internal/errors"
+	"github.com/Coiiap5e/photographer/internal/model"
+)
+
+// Formats a table can be written in
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+// Locale decides how dates and amounts are written to CSV and which number
+// formats XLSX cells get
+type Locale struct {
+	Name             string
+	DateLayout       string
+	DateTimeLayout   string
+	DecimalSeparator string
+	// Delimiter separates CSV columns, ';' where ',' is the decimal separator
+	Delimiter rune
+	// XLSXDate and XLSXDateTime are Excel number formats
+	XLSXDate     string
+	XLSXDateTime string
+}
+
+var locales = map[string]Locale{
+	"ru": {
+		Name:             "ru",
+		DateLayout:       "02.01.2006",
+		DateTimeLayout:   "02.01.2006 15:04",
+		DecimalSeparator: ",",
+		Delimiter:        ';',
+		XLSXDate:         "dd.mm.yyyy",
+		XLSXDateTime:     "dd.mm.yyyy hh:mm",
+	},
+	"en": {
+		Name:             "en",
+		DateLayout:       "01/02/2006",
+		DateTimeLayout:   "01/02/2006 15:04",
+		DecimalSeparator: ".",
+		Delimiter:        ',',
+		XLSXDate:         "mm/dd/yyyy",
+		XLSXDateTime:     "mm/dd/yyyy hh:mm",
+	},
+	"iso": {
+		Name:             "iso",
+		DateLayout:       "2006-01-02",
+		DateTimeLayout:   "2006-01-02 15:04",
+		DecimalSeparator: ".",
+		Delimiter:        ',',
+		XLSXDate:         "yyyy-mm-dd",
+		XLSXDateTime:     "yyyy-mm-dd hh:mm",
+	},
+}
+
+// DefaultLocale matches the dd.mm.yyyy dates used across the app
+const DefaultLocale = "ru"
+
+// LookupLocale returns the locale with the given name, "" is the default
+func LookupLocale(name string) (Locale, error) {
+	if name == "" {
+		name = DefaultLocale
+	}
+	locale, ok := locales[strings.ToLower(name)]
+	if !ok {
+		return Locale{}, errors.New(errors.ErrCodeInvalidInput,
+			fmt.Sprintf("unknown locale %q, use ru, en or iso", name))
+	}
+	return locale, nil
+}
+
+// DetectFormat returns format if set, otherwise it is taken from the
+// extension of path
+func DetectFormat(format, path string) (string, error) {
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	}
+	switch strings.ToLower(format) {
+	case "", FormatCSV:
+		return FormatCSV, nil
+	case FormatXLSX:
+		return FormatXLSX, nil
+	default:
+		return "", errors.New(errors.ErrCodeInvalidInput,
+			fmt.Sprintf("unknown export format %q, use csv or xlsx", format))
+	}
+}
+
+type CellKind int
+
+const (
+	TextCell CellKind = iota
+	NumberCell
+	DateCell
+	TimeCell
+	DateTimeCell
+	MoneyCell
+)
+
+// Cell is a typed value, so XLSX gets real dates and numbers while CSV gets
+// them formatted for the locale
+type Cell struct {
+	Kind   CellKind
+	Text   string
+	Number int64
+	Time   time.Time
+	Money  model.Money
+}
+
+func text(value string) Cell {
+	return Cell{Kind: TextCell, Text: value}
+}
+
+func number(value int) Cell {
+	return Cell{Kind: NumberCell, Number: int64(value)}
+}
+
+func date(value time.Time) Cell {
+	return Cell{Kind: DateCell, Time: value}
+}
+
+func clock(value time.Time) Cell {
+	return Cell{Kind: TimeCell, Time: value}
+}
+
+func dateTime(value time.Time) Cell {
+	return Cell{Kind: DateTimeCell, Time: value}
+}
+
+func money(value model.Money) Cell {
+	return Cell{Kind: MoneyCell, Money: value}
+}
+
+// Table is a header row followed by data rows
+type Table struct {
+	Name   string
+	Header []string
+	Rows   [][]Cell
+}
+
+// Column describes one exportable column of T
+type Column[T any] struct {
+	Key   string
+	Title string
+	Value func(T) Cell
+}
+
+// buildTable fills a table with the columns named by keys, all columns
+// when keys is empty
+func buildTable[T any](name string, columns []Column[T], keys []string, items []T) (*Table, error) {
+	selected, err := selectColumns(columns, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	table := &Table{Name: name}
+	for _, column := range selected {
+		table.Header = append(table.Header, column.Title)
+	}
+
+	for _, item := range items {
+		row := make([]Cell, len(selected))
+		for i, column := range selected {
+			row[i] = column.Value(item)
+		}
+		table.Rows = append(table.Rows, row)
+	}
+
+	return table, nil
+}
+
+func selectColumns[T any](columns []Column[T], keys []string) ([]Column[T], error) {
+	if len(keys) == 0 {
+		return columns, nil
+	}
+
+	byKey := make(map[string]Column[T], len(columns))
+	for _, column := range columns {
+		byKey[column.Key] = column
+	}
+
+	selected := make([]Column[T], 0, len(keys))
+	for _, key := range keys {
+		column, ok := byKey[strings.ToLower(strings.TrimSpace(key))]
+		if !ok {
+			return nil, errors.New(errors.ErrCodeInvalidInput, fmt.Sprintf(
+				"unknown column %q, use %s", key, strings.Join(columnKeys(columns), ", ")))
+		}
+		selected = append(selected, column)
+	}
+
+	return selected, nil
+}
+
+func columnKeys[T any](columns []Column[T]) []string {
+	keys := make([]string, len(columns))
+	for i, column := range columns {
+		keys[i] = column.Key
+	}
+	return keys
+}
+
+// ParseColumns splits a comma separated column list, "" selects all columns
+func ParseColumns(value string) []string {
+	var keys []string
+	for _, key := range strings.Split(value, ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// Write writes table in the given format
+func Write(w io.Writer, format string, table *Table, locale Locale) error {
+	switch format {
+	case FormatXLSX:
+		return WriteXLSX(w, table, locale)
+	default:
+		return WriteCSV(w, table, locale)
+	}
+}
@@ -0,0 +1,175 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/Coiiap5e/photographer/internal/errors"
+	"github.com/Coiiap5e/photographer/internal/model"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestExportTestSuit(t *testing.T) {
+	suite.Run(t, new(ExportTestSuit))
+}
+
+type ExportTestSuit struct {
+	suite.Suite
+}
+
+func testClients() []model.Client {
+	return []model.Client{
+		{Id: 1, FirstName: "Иван", LastName: "Иванов", Phone: "+7(900)000-00-00",
+			SocialNetworkUrl: "vk.com/ivanov", CreatedAt: time.Date(2025, 1, 5, 10, 30, 0, 0, time.UTC)},
+		{Id: 2, FirstName: "Anna", LastName: "Petrova", Phone: "+7(901)111-11-11",
+			CreatedAt: time.Date(2025, 2, 1, 9, 0, 0, 0, time.UTC)},
+	}
+}
+
+func testShoots() []model.Shoot {
+	return []model.Shoot{{
+		Id:            7,
+		ClientId:      1,
+		ShootDate:     time.Date(2025, 1, 20, 0, 0, 0, 0, time.UTC),
+		StartTime:     time.Date(0, 1, 1, 15, 0, 0, 0, time.UTC),
+		EndTime:       time.Date(0, 1, 1, 16, 30, 0, 0, time.UTC),
+		ShootPrice:    model.NewMoney(150050, "RUB"),
+		ShootLocation: "Pushkin blvd, 1",
+		ShootType:     "love story",
+		Client:        testClients()[0],
+	}}
+}
+
+func (suite *ExportTestSuit) csv(table *Table, locale string) string {
+	loc, err := LookupLocale(locale)
+	suite.Require().NoError(err)
+	var buf bytes.Buffer
+	suite.Require().NoError(WriteCSV(&buf, table, loc))
+	return buf.String()
+}
+
+func (suite *ExportTestSuit) TestClients() {
+	suite.T().Run("should select columns in the given order", func(t *testing.T) {
+		// When
+		table, err := Clients(testClients(), []string{"phone", "first_name"}, time.Time{}, time.Time{})
+
+		// Then
+		suite.Require().NoError(err, "should not return error")
+		suite.Equal([]string{"Phone", "First Name"}, table.Header, "should use selected columns")
+		suite.Equal("+7(900)000-00-00", table.Rows[0][0].Text, "should put phone first")
+	})
+
+	suite.T().Run("should filter by creation date", func(t *testing.T) {
+		// When
+		table, err := Clients(testClients(), nil,
+			time.Date(2025, 1, 5, 0, 0, 0, 0, time.UTC), time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC))
+
+		// Then
+		suite.Require().NoError(err, "should not return error")
+		suite.Len(table.Rows, 1, "should keep clients created within the range")
+	})
+
+	suite.T().Run("should reject unknown column", func(t *testing.T) {
+		// When
+		_, err := Clients(testClients(), []string{"email"}, time.Time{}, time.Time{})
+
+		// Then
+		suite.True(errors.IsErrorCode(err, errors.ErrCodeInvalidInput), "should return invalid input error")
+	})
+}
+
+func (suite *ExportTestSuit) TestWriteCSV() {
+	suite.T().Run("should format shoots for russian locale", func(t *testing.T) {
+		// Given
+		table, err := Shoots(testShoots(),
+			[]string{"date", "start", "client_first_name", "client_phone", "price", "location"})
+		suite.Require().NoError(err)
+
+		// When
+		out := suite.csv(table, "ru")
+
+		// Then
+		suite.Equal("\ufeffDate;Start;First Name;Phone;Price;Location\r\n"+
+			"20.01.2025;15:00;Иван;+7(900)000-00-00;1500,50;Pushkin blvd, 1\r\n", out,
+			"should include client data and use russian formats")
+	})
+
+	suite.T().Run("should format dates for english locale", func(t *testing.T) {
+		// Given
+		table, err := Clients(testClients(), []string{"id", "created_at"}, time.Time{}, time.Time{})
+		suite.Require().NoError(err)
+
+		// When
+		out := suite.csv(table, "en")
+
+		// Then
+		suite.Contains(out, "1,01/05/2025 10:30\r\n", "should use english date format")
+	})
+
+	suite.T().Run("should reject unknown locale", func(t *testing.T) {
+		// When
+		_, err := LookupLocale("de")
+
+		// Then
+		suite.True(errors.IsErrorCode(err, errors.ErrCodeInvalidInput), "should return invalid input error")
+	})
+}
+
+func (suite *ExportTestSuit) TestWriteXLSX() {
+	// Given
+	table, err := Shoots(testShoots(), []string{"date", "start", "type", "price"})
+	suite.Require().NoError(err)
+	locale, _ := LookupLocale("ru")
+	var buf bytes.Buffer
+
+	// When
+	err = WriteXLSX(&buf, table, locale)
+
+	// Then
+	suite.Require().NoError(err, "should not return error")
+
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	suite.Require().NoError(err, "should write a zip archive")
+
+	files := map[string]string{}
+	for _, file := range archive.File {
+		r, err := file.Open()
+		suite.Require().NoError(err)
+		content, err := io.ReadAll(r)
+		suite.Require().NoError(err)
+		files[file.Name] = string(content)
+	}
+
+	suite.Contains(files, "[Content_Types].xml", "should have content types")
+	suite.Contains(files["xl/workbook.xml"], `<sheet name="Shoots"`, "should name the sheet")
+	suite.Contains(files["xl/styles.xml"], `formatCode="dd.mm.yyyy"`, "should use locale date format")
+
+	sheet := files["xl/worksheets/sheet1.xml"]
+	suite.Contains(sheet, `<c r="A1" s="1" t="inlineStr"><is><t xml:space="preserve">Date</t></is></c>`,
+		"should write bold header")
+	suite.Contains(sheet, `<c r="A2" s="2"><v>45677</v></c>`, "should store date as serial number")
+	suite.Contains(sheet, `<c r="B2" s="3"><v>0.625</v></c>`, "should store time as day fraction")
+	suite.Contains(sheet, `<c r="D2" s="5"><v>1500.50</v></c>`, "should store price as number")
+}
+
+func (suite *ExportTestSuit) TestCellRef() {
+	for expected, column := range map[string]int{"A1": 0, "Z1": 25, "AA1": 26, "AZ1": 51, "BA1": 52} {
+		suite.Equal(expected, cellRef(column, 1), "should name column %d", column)
+	}
+}
+
+func (suite *ExportTestSuit) TestDetectFormat() {
+	format, err := DetectFormat("", "clients.XLSX")
+	suite.NoError(err, "should not return error")
+	suite.Equal(FormatXLSX, format, "should take format from extension")
+
+	format, err = DetectFormat("", "")
+	suite.NoError(err, "should not return error")
+	suite.Equal(FormatCSV, format, "should default to CSV")
+
+	_, err = DetectFormat("pdf", "")
+	suite.True(errors.IsErrorCode(err, errors.ErrCodeInvalidInput), "should reject unknown format")
+}
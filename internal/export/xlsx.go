@@ -0,0 +1,210 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Coiiap5e/photographer/internal/errors"
+)
+
+// Style indexes into cellXfs of styles.xml
+const (
+	styleDefault = iota
+	styleHeader
+	styleDate
+	styleTime
+	styleDateTime
+	styleMoney
+	styleWholeMoney
+)
+
+const contentTypesXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>
+<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>
+<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>
+</Types>`
+
+const rootRelsXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>
+</Relationships>`
+
+const workbookRelsXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>
+</Relationships>`
+
+const workbookXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="%s" sheetId="1" r:id="rId1"/></sheets>
+</workbook>`
+
+// stylesXML takes the date and date-time formats of the locale. Built-in
+// formats 4 and 3 are #,##0.00 and #,##0.
+const stylesXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<numFmts count="3">
+<numFmt numFmtId="164" formatCode="%s"/>
+<numFmt numFmtId="165" formatCode="hh:mm"/>
+<numFmt numFmtId="166" formatCode="%s"/>
+</numFmts>
+<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>
+<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>
+<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>
+<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>
+<cellXfs count="7">
+<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>
+<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>
+<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>
+<xf numFmtId="165" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>
+<xf numFmtId="166" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>
+<xf numFmtId="4" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>
+<xf numFmtId="3" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>
+</cellXfs>
+</styleSheet>`
+
+// WriteXLSX writes table as a single sheet workbook. Dates, times and
+// amounts are stored as numbers with a number format, so they sort and sum
+// in a spreadsheet.
+func WriteXLSX(w io.Writer, table *Table, locale Locale) error {
+	var sheet bytes.Buffer
+	writeSheet(&sheet, table)
+
+	parts := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", contentTypesXML},
+		{"_rels/.rels", rootRelsXML},
+		{"xl/workbook.xml", fmt.Sprintf(workbookXML, escape(table.Name))},
+		{"xl/_rels/workbook.xml.rels", workbookRelsXML},
+		{"xl/styles.xml", fmt.Sprintf(stylesXML, escape(locale.XLSXDate), escape(locale.XLSXDateTime))},
+		{"xl/worksheets/sheet1.xml", sheet.String()},
+	}
+
+	archive := zip.NewWriter(w)
+	for _, part := range parts {
+		file, err := archive.Create(part.name)
+		if err != nil {
+			return errors.Wrap(err, errors.ErrCodeInternal, "failed to write XLSX")
+		}
+		if _, err := io.WriteString(file, part.content); err != nil {
+			return errors.Wrap(err, errors.ErrCodeInternal, "failed to write XLSX")
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to write XLSX")
+	}
+
+	return nil
+}
+
+func writeSheet(buf *bytes.Buffer, table *Table) {
+	buf.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n")
+	buf.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	buf.WriteString(`<sheetViews><sheetView workbookViewId="0">` +
+		`<pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/>` +
+		`</sheetView></sheetViews>`)
+	buf.WriteString("<sheetData>")
+
+	buf.WriteString(`<row r="1">`)
+	for i, title := range table.Header {
+		writeText(buf, cellRef(i, 1), title, styleHeader)
+	}
+	buf.WriteString("</row>")
+
+	for r, row := range table.Rows {
+		line := r + 2
+		fmt.Fprintf(buf, `<row r="%d">`, line)
+		for i, cell := range row {
+			writeCell(buf, cellRef(i, line), cell)
+		}
+		buf.WriteString("</row>")
+	}
+
+	buf.WriteString("</sheetData></worksheet>")
+}
+
+func writeCell(buf *bytes.Buffer, ref string, cell Cell) {
+	switch cell.Kind {
+	case NumberCell:
+		writeNumber(buf, ref, strconv.FormatInt(cell.Number, 10), styleDefault)
+	case DateCell:
+		if !cell.Time.IsZero() {
+			writeNumber(buf, ref, serial(cell.Time, true), styleDate)
+		}
+	case TimeCell:
+		writeNumber(buf, ref, dayFraction(cell.Time), styleTime)
+	case DateTimeCell:
+		if !cell.Time.IsZero() {
+			writeNumber(buf, ref, serial(cell.Time, false), styleDateTime)
+		}
+	case MoneyCell:
+		value := cell.Money.Decimal()
+		style := styleMoney
+		if !strings.Contains(value, ".") {
+			style = styleWholeMoney
+		}
+		writeNumber(buf, ref, value, style)
+	default:
+		if cell.Text != "" {
+			writeText(buf, ref, cell.Text, styleDefault)
+		}
+	}
+}
+
+func writeNumber(buf *bytes.Buffer, ref, value string, style int) {
+	fmt.Fprintf(buf, `<c r="%s" s="%d"><v>%s</v></c>`, ref, style, value)
+}
+
+func writeText(buf *bytes.Buffer, ref, value string, style int) {
+	fmt.Fprintf(buf, `<c r="%s" s="%d" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`,
+		ref, style, escape(value))
+}
+
+// cellRef returns the A1 style reference of a zero based column and a
+// one based row
+func cellRef(column, row int) string {
+	name := ""
+	for column++; column > 0; column = (column - 1) / 26 {
+		name = string(rune('A'+(column-1)%26)) + name
+	}
+	return name + strconv.Itoa(row)
+}
+
+// excelEpoch is day zero of the 1900 date system, two days before
+// 01.01.1900 because of the fictional 29.02.1900 Excel keeps
+var excelEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// serial converts t to an Excel serial date, taking the wall clock as is
+func serial(t time.Time, dateOnly bool) string {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	days := int64(day.Sub(excelEpoch).Hours() / 24)
+	if dateOnly {
+		return strconv.FormatInt(days, 10)
+	}
+	fraction, _ := strconv.ParseFloat(dayFraction(t), 64)
+	return strconv.FormatFloat(float64(days)+fraction, 'f', -1, 64)
+}
+
+func dayFraction(t time.Time) string {
+	seconds := t.Hour()*3600 + t.Minute()*60 + t.Second()
+	return strconv.FormatFloat(float64(seconds)/86400, 'f', -1, 64)
+}
+
+func escape(value string) string {
+	var buf bytes.Buffer
+	_ = xml.EscapeText(&buf, []byte(value))
+	return buf.String()
+}
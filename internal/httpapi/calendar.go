@@ -0,0 +1,65 @@
+package httpapi
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	"github.com/Coiiap5e/photographer/internal/ical"
+	"github.com/Coiiap5e/photographer/internal/model"
+)
+
+// calendarFeed serves upcoming shoots as an iCalendar feed for calendar apps
+// to subscribe to. The feed has no other authentication, so the token in the
+// path must be kept secret.
+//
+// The ETag is a hash of the feed, so it changes whenever a shoot is added,
+// changed or deleted. Last-Modified is the latest shoot change and does not
+// notice deletions, which is why If-None-Match is checked first.
+func (s *Server) calendarFeed(w http.ResponseWriter, r *http.Request) {
+	token := r.PathValue("token")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(s.calendarToken)) != 1 {
+		http.NotFound(w, r)
+		return
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	shoots, err := s.shootService.ListShoots(r.Context(), today, time.Time{})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := ical.Encode(&buf, "Shoots", shoots); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	sum := sha256.Sum256(buf.Bytes())
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:16])+`"`)
+	w.Header().Set("Cache-Control", "private, no-cache")
+
+	http.ServeContent(w, r, "shoots.ics", lastModified(shoots), bytes.NewReader(buf.Bytes()))
+}
+
+func lastModified(shoots []model.Shoot) time.Time {
+	var latest time.Time
+	for _, shoot := range shoots {
+		changed := shoot.UpdatedAt
+		if changed.IsZero() {
+			changed = shoot.CreatedAt
+		}
+		if changed.After(latest) {
+			latest = changed
+		}
+	}
+	return latest
+}
@@ -0,0 +1,153 @@
+package httpapi
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Coiiap5e/photographer/internal/errors"
+	"github.com/Coiiap5e/photographer/internal/model"
+)
+
+type clientRequest struct {
+	FirstName        string `json:"first_name"`
+	LastName         string `json:"last_name"`
+	Phone            string `json:"phone"`
+	SocialNetworkUrl string `json:"social_network_url"`
+}
+
+// clientPatchRequest changes only the fields present in the body. Version
+// must be the one last read, otherwise the update is rejected with 409.
+type clientPatchRequest struct {
+	Version          int     `json:"version"`
+	FirstName        *string `json:"first_name"`
+	LastName         *string `json:"last_name"`
+	Phone            *string `json:"phone"`
+	SocialNetworkUrl *string `json:"social_network_url"`
+}
+
+type clientResponse struct {
+	Id               int       `json:"id"`
+	FirstName        string    `json:"first_name"`
+	LastName         string    `json:"last_name"`
+	Phone            string    `json:"phone"`
+	SocialNetworkUrl string    `json:"social_network_url"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+	Version          int       `json:"version"`
+}
+
+func newClientResponse(client *model.Client) clientResponse {
+	return clientResponse{
+		Id:               client.Id,
+		FirstName:        client.FirstName,
+		LastName:         client.LastName,
+		Phone:            client.Phone,
+		SocialNetworkUrl: client.SocialNetworkUrl,
+		CreatedAt:        client.CreatedAt,
+		UpdatedAt:        client.UpdatedAt,
+		Version:          client.Version,
+	}
+}
+
+func (s *Server) listClients(w http.ResponseWriter, r *http.Request) {
+	clients, err := s.clientService.ListClients(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response := make([]clientResponse, 0, len(clients))
+	for i := range clients {
+		response = append(response, newClientResponse(&clients[i]))
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (s *Server) createClient(w http.ResponseWriter, r *http.Request) {
+	var req clientRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	client := &model.Client{
+		FirstName:        strings.TrimSpace(req.FirstName),
+		LastName:         strings.TrimSpace(req.LastName),
+		Phone:            strings.TrimSpace(req.Phone),
+		SocialNetworkUrl: strings.TrimSpace(req.SocialNetworkUrl),
+	}
+
+	if err := s.clientService.CreateClient(r.Context(), client); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, newClientResponse(client))
+}
+
+func (s *Server) getClient(w http.ResponseWriter, r *http.Request) {
+	id, err := pathId(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	client, err := s.clientService.GetClientByID(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newClientResponse(client))
+}
+
+func (s *Server) updateClient(w http.ResponseWriter, r *http.Request) {
+	id, err := pathId(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	var req clientPatchRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if req.Version <= 0 {
+		writeError(w, errors.New(errors.ErrCodeValidation, "version is required"))
+		return
+	}
+
+	update := &model.ClientUpdate{
+		FirstName:        req.FirstName,
+		LastName:         req.LastName,
+		Phone:            req.Phone,
+		SocialNetworkUrl: req.SocialNetworkUrl,
+	}
+
+	client, err := s.clientService.UpdateClient(r.Context(), id, req.Version, update)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newClientResponse(client))
+}
+
+func (s *Server) deleteClient(w http.ResponseWriter, r *http.Request) {
+	id, err := pathId(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := s.clientService.DeleteClient(r.Context(), id); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,189 @@
+package httpapi
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Coiiap5e/photographer/internal/audit"
+	"github.com/Coiiap5e/photographer/internal/errors"
+	"github.com/Coiiap5e/photographer/internal/service"
+	"github.com/Coiiap5e/photographer/internal/validation"
+)
+
+// maxBodySize limits request bodies, the API only accepts small JSON objects
+const maxBodySize = 1 << 20
+
+// Actor is who changes made through the API are recorded as made by. The API
+// does not authenticate callers, so a name sent in the X-Actor header is only
+// noted next to it as unverified and never taken for a real user.
+const Actor = "api"
+
+// maxClaimedActor limits the X-Actor name kept in the audit log
+const maxClaimedActor = 100
+
+type Server struct {
+	clientService service.Client
+	shootService  service.Shoot
+	calendarToken string
+	mux           *http.ServeMux
+}
+
+type errorResponse struct {
+	Error errorBody `json:"error"`
+}
+
+type errorBody struct {
+	Code    errors.ErrorCode `json:"code"`
+	Message string           `json:"message"`
+	// Conflicts lists the clashing shoots for SHOOT_CONFLICT errors
+	Conflicts []shootResponse `json:"conflicts,omitempty"`
+	// Fields lists the invalid fields for VALIDATION_ERROR errors
+	Fields validation.Errors `json:"fields,omitempty"`
+}
+
+// NewServer returns the API handler. The iCal feed of upcoming shoots is
+// served at /calendar/{calendarToken}/shoots.ics, an empty token disables it.
+func NewServer(clientService service.Client, shootService service.Shoot,
+	calendarToken string) *Server {
+	s := &Server{
+		clientService: clientService,
+		shootService:  shootService,
+		calendarToken: calendarToken,
+		mux:           http.NewServeMux(),
+	}
+
+	s.mux.HandleFunc("GET /clients", s.listClients)
+	s.mux.HandleFunc("POST /clients", s.createClient)
+	s.mux.HandleFunc("GET /clients/{id}", s.getClient)
+	s.mux.HandleFunc("PATCH /clients/{id}", s.updateClient)
+	s.mux.HandleFunc("DELETE /clients/{id}", s.deleteClient)
+
+	s.mux.HandleFunc("GET /shoots", s.listShoots)
+	s.mux.HandleFunc("POST /shoots", s.createShoot)
+	s.mux.HandleFunc("GET /shoots/{id}", s.getShoot)
+	s.mux.HandleFunc("PATCH /shoots/{id}", s.updateShoot)
+	s.mux.HandleFunc("DELETE /shoots/{id}", s.deleteShoot)
+
+	if calendarToken != "" {
+		s.mux.HandleFunc("GET /calendar/{token}/shoots.ics", s.calendarFeed)
+	}
+
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+	r = r.WithContext(audit.WithActor(r.Context(), requestActor(r)))
+
+	s.mux.ServeHTTP(rec, r)
+
+	// keep the calendar token out of the logs
+	path := r.URL.Path
+	if strings.HasPrefix(path, "/calendar/") {
+		path = "/calendar/***"
+	}
+
+	log.Printf("%s %s %d %s", r.Method, path, rec.status, time.Since(start))
+}
+
+// requestActor returns the audit actor of r, Actor with the unverified name
+// from X-Actor if the caller sent one
+func requestActor(r *http.Request) string {
+	claimed := strings.TrimSpace(r.Header.Get("X-Actor"))
+	if claimed == "" {
+		return Actor
+	}
+	if runes := []rune(claimed); len(runes) > maxClaimedActor {
+		claimed = string(runes[:maxClaimedActor])
+	}
+	return fmt.Sprintf("%s (X-Actor %q, unverified)", Actor, claimed)
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// StatusCode maps an application error code to an HTTP status
+func StatusCode(code errors.ErrorCode) int {
+	switch code {
+	case errors.ErrCodeClientNotFound, errors.ErrCodeShootNotFound:
+		return http.StatusNotFound
+	case errors.ErrCodeValidation, errors.ErrCodeInvalidInput:
+		return http.StatusBadRequest
+	case errors.ErrCodeVersionConflict, errors.ErrCodeShootConflict:
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("failed to write response: %v", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	var appErr *errors.AppError
+	if !stderrors.As(err, &appErr) {
+		appErr = errors.Wrap(err, errors.ErrCodeInternal, "internal error")
+	}
+
+	status := StatusCode(appErr.Code)
+	if status == http.StatusInternalServerError {
+		log.Printf("request failed: %v", err)
+	}
+
+	body := errorBody{
+		Code:    appErr.Code,
+		Message: appErr.Message,
+	}
+
+	var conflictErr *service.ConflictError
+	if stderrors.As(err, &conflictErr) {
+		for i := range conflictErr.Shoots {
+			body.Conflicts = append(body.Conflicts, newShootResponse(&conflictErr.Shoots[i]))
+		}
+	}
+
+	body.Fields = validation.Fields(err)
+
+	writeJSON(w, status, errorResponse{Error: body})
+}
+
+func decodeJSON(w http.ResponseWriter, r *http.Request, v any) error {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(v); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInvalidInput, "invalid JSON body")
+	}
+
+	return nil
+}
+
+func pathId(r *http.Request) (int, error) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || id <= 0 {
+		return 0, errors.New(errors.ErrCodeInvalidInput, "id must be a number > 0")
+	}
+
+	return id, nil
+}
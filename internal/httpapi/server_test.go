@@ -0,0 +1,544 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Coiiap5e/photographer/internal/audit"
+	"github.com/Coiiap5e/photographer/internal/errors"
+	"github.com/Coiiap5e/photographer/internal/importer"
+	"github.com/Coiiap5e/photographer/internal/model"
+	"github.com/Coiiap5e/photographer/internal/service"
+	"github.com/Coiiap5e/photographer/internal/validation"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestServerTestSuit(t *testing.T) {
+	suite.Run(t, new(ServerTestSuit))
+}
+
+type ServerTestSuit struct {
+	suite.Suite
+	clients *fakeClientService
+	shoots  *fakeShootService
+	server  *Server
+}
+
+func (suite *ServerTestSuit) SetupTest() {
+	suite.clients = &fakeClientService{clients: map[int]*model.Client{}}
+	suite.shoots = &fakeShootService{shoots: map[int]*model.Shoot{}}
+	suite.server = NewServer(suite.clients, suite.shoots, "secret")
+}
+
+func (suite *ServerTestSuit) do(method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	suite.server.ServeHTTP(rec, req)
+	return rec
+}
+
+func (suite *ServerTestSuit) TestCreateClient() {
+	suite.T().Run("should create client", func(t *testing.T) {
+		// When
+		rec := suite.do(http.MethodPost, "/clients",
+			`{"first_name":"Ivan","last_name":"Ivanov","phone":"+7(900)000-00-00"}`)
+
+		// Then
+		suite.Equal(http.StatusCreated, rec.Code, "should respond with 201")
+
+		var response clientResponse
+		suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &response))
+		suite.Greater(response.Id, 0, "should return generated ID")
+		suite.Equal("Ivan", response.FirstName, "should return first name")
+	})
+	suite.T().Run("should reject missing fields", func(t *testing.T) {
+		// When
+		rec := suite.do(http.MethodPost, "/clients", `{"first_name":"Ivan"}`)
+
+		// Then
+		suite.Equal(http.StatusBadRequest, rec.Code, "should respond with 400")
+
+		var response errorResponse
+		suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &response))
+		suite.Equal(errors.ErrCodeValidation, response.Error.Code, "should return validation code")
+		suite.Equal(validation.Errors{
+			{Field: "last_name", Message: "must not be empty"},
+			{Field: "phone", Message: "must not be empty"},
+		}, response.Error.Fields, "should name the missing fields")
+	})
+	suite.T().Run("should list invalid fields", func(t *testing.T) {
+		// When
+		rec := suite.do(http.MethodPost, "/clients",
+			`{"first_name":"Ivan","last_name":"Ivanov","phone":"12-34","social_network_url":"vk.com/ivan"}`)
+
+		// Then
+		suite.Equal(http.StatusBadRequest, rec.Code, "should respond with 400")
+
+		var response errorResponse
+		suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &response))
+		suite.Equal(errors.ErrCodeValidation, response.Error.Code, "should return validation code")
+		suite.Equal(validation.Errors{{Field: "phone", Message: "must have 10 to 15 digits"}},
+			response.Error.Fields, "should name the invalid field")
+	})
+	suite.T().Run("should reject malformed JSON", func(t *testing.T) {
+		// When
+		rec := suite.do(http.MethodPost, "/clients", `{"first_name":`)
+
+		// Then
+		suite.Equal(http.StatusBadRequest, rec.Code, "should respond with 400")
+		suite.Contains(rec.Body.String(), string(errors.ErrCodeInvalidInput), "should return invalid input code")
+	})
+}
+
+func (suite *ServerTestSuit) TestGetClient() {
+	suite.T().Run("should return client", func(t *testing.T) {
+		// Given
+		suite.clients.clients[1] = &model.Client{Id: 1, FirstName: "Anna", LastName: "Petrova"}
+
+		// When
+		rec := suite.do(http.MethodGet, "/clients/1", "")
+
+		// Then
+		suite.Equal(http.StatusOK, rec.Code, "should respond with 200")
+		suite.Contains(rec.Body.String(), `"first_name":"Anna"`, "should return client")
+	})
+	suite.T().Run("should map not found error to 404", func(t *testing.T) {
+		// When
+		rec := suite.do(http.MethodGet, "/clients/42", "")
+
+		// Then
+		suite.Equal(http.StatusNotFound, rec.Code, "should respond with 404")
+
+		var response errorResponse
+		suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &response))
+		suite.Equal(errors.ErrCodeClientNotFound, response.Error.Code, "should return error code")
+	})
+	suite.T().Run("should reject invalid ID", func(t *testing.T) {
+		// When
+		rec := suite.do(http.MethodGet, "/clients/abc", "")
+
+		// Then
+		suite.Equal(http.StatusBadRequest, rec.Code, "should respond with 400")
+	})
+}
+
+func (suite *ServerTestSuit) TestDeleteClient() {
+	// Given
+	suite.clients.clients[1] = &model.Client{Id: 1}
+
+	// When
+	rec := suite.do(http.MethodDelete, "/clients/1", "")
+
+	// Then
+	suite.Equal(http.StatusNoContent, rec.Code, "should respond with 204")
+	suite.NotContains(suite.clients.clients, 1, "should delete client")
+}
+
+func (suite *ServerTestSuit) TestActorHeader() {
+	suite.T().Run("should record the claimed actor as an unverified note", func(t *testing.T) {
+		// Given
+		suite.clients.clients[1] = &model.Client{Id: 1}
+		req := httptest.NewRequest(http.MethodDelete, "/clients/1", nil)
+		req.Header.Set("X-Actor", "assistant")
+
+		// When
+		suite.server.ServeHTTP(httptest.NewRecorder(), req)
+
+		// Then
+		suite.Equal(`api (X-Actor "assistant", unverified)`, suite.clients.deletedBy,
+			"should not record the change as made by the claimed actor")
+	})
+	suite.T().Run("should record changes without the header as made by the API", func(t *testing.T) {
+		// Given
+		suite.clients.clients[2] = &model.Client{Id: 2}
+
+		// When
+		suite.do(http.MethodDelete, "/clients/2", "")
+
+		// Then
+		suite.Equal(Actor, suite.clients.deletedBy, "should record the API as the actor")
+	})
+}
+
+func (suite *ServerTestSuit) TestUpdateClient() {
+	suite.T().Run("should update only given fields", func(t *testing.T) {
+		// Given
+		suite.clients.clients[1] = &model.Client{Id: 1, FirstName: "Anna", Phone: "111", Version: 1}
+
+		// When
+		rec := suite.do(http.MethodPatch, "/clients/1", `{"version":1,"phone":"222"}`)
+
+		// Then
+		suite.Equal(http.StatusOK, rec.Code, "should respond with 200")
+		suite.Equal("222", suite.clients.clients[1].Phone, "should update phone")
+		suite.Equal("Anna", suite.clients.clients[1].FirstName, "should keep first name")
+		suite.Contains(rec.Body.String(), `"version":2`, "should return new version")
+	})
+	suite.T().Run("should map version conflict to 409", func(t *testing.T) {
+		// Given
+		suite.clients.clients[2] = &model.Client{Id: 2, Version: 3}
+
+		// When
+		rec := suite.do(http.MethodPatch, "/clients/2", `{"version":1,"phone":"222"}`)
+
+		// Then
+		suite.Equal(http.StatusConflict, rec.Code, "should respond with 409")
+	})
+	suite.T().Run("should require version", func(t *testing.T) {
+		// When
+		rec := suite.do(http.MethodPatch, "/clients/1", `{"phone":"333"}`)
+
+		// Then
+		suite.Equal(http.StatusBadRequest, rec.Code, "should respond with 400")
+	})
+}
+
+func (suite *ServerTestSuit) TestUpdateShoot() {
+	suite.T().Run("should move shoot", func(t *testing.T) {
+		// Given
+		suite.shoots.shoots[1] = &model.Shoot{Id: 1, Version: 1}
+
+		// When
+		rec := suite.do(http.MethodPatch, "/shoots/1", `{"version":1,"date":"2025-02-01"}`)
+
+		// Then
+		suite.Equal(http.StatusOK, rec.Code, "should respond with 200")
+		suite.Equal("2025-02-01", suite.shoots.shoots[1].ShootDate.Format(dateLayout), "should move shoot")
+	})
+	suite.T().Run("should take times without a date on the shoot date", func(t *testing.T) {
+		// Given
+		suite.shoots.shoots[2] = &model.Shoot{Id: 2, Version: 1,
+			ShootDate: time.Date(2025, 1, 20, 0, 0, 0, 0, time.UTC)}
+
+		// When
+		rec := suite.do(http.MethodPatch, "/shoots/2", `{"version":1,"start_time":"11:00","end_time":"12:30"}`)
+
+		// Then
+		suite.Equal(http.StatusOK, rec.Code, "should respond with 200")
+		shoot := suite.shoots.shoots[2]
+		suite.Equal("2025-01-20 11:00", shoot.StartTime.Format(dateLayout+" "+timeLayout),
+			"should put the start time on the shoot date")
+		suite.Equal("2025-01-20 12:30", shoot.EndTime.Format(dateLayout+" "+timeLayout),
+			"should put the end time on the shoot date")
+	})
+	suite.T().Run("should map a missing shoot to 404", func(t *testing.T) {
+		// When
+		rec := suite.do(http.MethodPatch, "/shoots/42", `{"version":1,"start_time":"11:00"}`)
+
+		// Then
+		suite.Equal(http.StatusNotFound, rec.Code, "should respond with 404")
+	})
+}
+
+func (suite *ServerTestSuit) TestCreateShoot() {
+	suite.T().Run("should create shoot", func(t *testing.T) {
+		// When
+		rec := suite.do(http.MethodPost, "/shoots", `{"client_id":1,"date":"2025-01-20",
+			"start_time":"15:00","end_time":"16:00","shoot_price":{"amount":"1000.50"},
+			"location":"Pushkin blvd","shoot_type":"love story"}`)
+
+		// Then
+		suite.Equal(http.StatusCreated, rec.Code, "should respond with 201")
+
+		var response shootResponse
+		suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &response))
+		suite.Equal("2025-01-20", response.Date, "should return date")
+		suite.Equal("15:00", response.StartTime, "should return start time")
+		suite.Equal(moneyJSON{Amount: "1000.50", Currency: "RUB"}, response.ShootPrice,
+			"should return price in the default currency")
+	})
+	suite.T().Run("should map conflict to 409 with clashing shoots", func(t *testing.T) {
+		// When
+		rec := suite.do(http.MethodPost, "/shoots", `{"client_id":1,"date":"2025-01-20",
+			"start_time":"15:30","end_time":"16:30","location":"beacon","shoot_type":"family"}`)
+
+		// Then
+		suite.Equal(http.StatusConflict, rec.Code, "should respond with 409")
+
+		var response errorResponse
+		suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &response))
+		suite.Equal(errors.ErrCodeShootConflict, response.Error.Code, "should return conflict code")
+		suite.Len(response.Error.Conflicts, 1, "should list the clashing shoot")
+	})
+	suite.T().Run("should book anyway when overlap is allowed", func(t *testing.T) {
+		// When
+		rec := suite.do(http.MethodPost, "/shoots", `{"client_id":1,"date":"2025-01-20",
+			"start_time":"15:30","end_time":"16:30","location":"beacon","shoot_type":"family",
+			"allow_overlap":true}`)
+
+		// Then
+		suite.Equal(http.StatusCreated, rec.Code, "should respond with 201")
+	})
+	suite.T().Run("should list missing fields", func(t *testing.T) {
+		// When
+		rec := suite.do(http.MethodPost, "/shoots", `{"date":"2025-01-21",
+			"start_time":"15:00","end_time":"16:00","location":" "}`)
+
+		// Then
+		suite.Equal(http.StatusBadRequest, rec.Code, "should respond with 400")
+
+		var response errorResponse
+		suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &response))
+		suite.Equal(validation.Errors{
+			{Field: "client_id", Message: "must be set"},
+			{Field: "location", Message: "must not be empty"},
+			{Field: "shoot_type", Message: "must not be empty"},
+		}, response.Error.Fields, "should name the missing fields")
+	})
+	suite.T().Run("should reject invalid date", func(t *testing.T) {
+		// When
+		rec := suite.do(http.MethodPost, "/shoots", `{"client_id":1,"date":"20.01.2025",
+			"start_time":"15:00","end_time":"16:00","location":"beacon","shoot_type":"family"}`)
+
+		// Then
+		suite.Equal(http.StatusBadRequest, rec.Code, "should respond with 400")
+	})
+}
+
+func (suite *ServerTestSuit) TestListShoots() {
+	suite.T().Run("should pass date range to service", func(t *testing.T) {
+		// When
+		rec := suite.do(http.MethodGet, "/shoots?from=2025-01-01&to=2025-01-31", "")
+
+		// Then
+		suite.Equal(http.StatusOK, rec.Code, "should respond with 200")
+		suite.Equal("2025-01-01", suite.shoots.from.Format(dateLayout), "should pass from")
+		suite.Equal("2025-01-31", suite.shoots.to.Format(dateLayout), "should pass to")
+		suite.JSONEq(`[]`, rec.Body.String(), "should return empty list")
+	})
+	suite.T().Run("should reject invalid range", func(t *testing.T) {
+		// When
+		rec := suite.do(http.MethodGet, "/shoots?from=yesterday", "")
+
+		// Then
+		suite.Equal(http.StatusBadRequest, rec.Code, "should respond with 400")
+	})
+}
+
+func (suite *ServerTestSuit) TestCalendarFeed() {
+	tomorrow := time.Now().AddDate(0, 0, 1)
+	suite.shoots.nextId = 2
+	suite.shoots.shoots[1] = &model.Shoot{Id: 1, ShootDate: time.Now().AddDate(0, 0, -3),
+		Client: model.Client{FirstName: "Old"}, UpdatedAt: time.Now().AddDate(0, 0, -5)}
+	suite.shoots.shoots[2] = &model.Shoot{Id: 2,
+		ShootDate: time.Date(tomorrow.Year(), tomorrow.Month(), tomorrow.Day(), 0, 0, 0, 0, time.UTC),
+		StartTime: time.Date(0, 1, 1, 15, 0, 0, 0, time.UTC), EndTime: time.Date(0, 1, 1, 16, 0, 0, 0, time.UTC),
+		Client: model.Client{FirstName: "Anna", LastName: "Petrova"}, UpdatedAt: time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)}
+
+	suite.T().Run("should serve upcoming shoots", func(t *testing.T) {
+		// When
+		rec := suite.do(http.MethodGet, "/calendar/secret/shoots.ics", "")
+
+		// Then
+		suite.Equal(http.StatusOK, rec.Code, "should respond with 200")
+		suite.Equal("text/calendar; charset=utf-8", rec.Header().Get("Content-Type"), "should serve iCalendar")
+		suite.NotEmpty(rec.Header().Get("ETag"), "should set ETag")
+		suite.Equal("Fri, 10 Jan 2025 12:00:00 GMT", rec.Header().Get("Last-Modified"),
+			"should use the latest shoot change")
+		suite.Contains(rec.Body.String(), "UID:shoot-2@photographer", "should include upcoming shoot")
+		suite.NotContains(rec.Body.String(), "UID:shoot-1@photographer", "should skip past shoots")
+	})
+	suite.T().Run("should reject wrong token", func(t *testing.T) {
+		// When
+		rec := suite.do(http.MethodGet, "/calendar/guess/shoots.ics", "")
+
+		// Then
+		suite.Equal(http.StatusNotFound, rec.Code, "should respond with 404")
+	})
+	suite.T().Run("should answer 304 until shoots change", func(t *testing.T) {
+		// Given
+		etag := suite.do(http.MethodGet, "/calendar/secret/shoots.ics", "").Header().Get("ETag")
+		conditional := func() *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodGet, "/calendar/secret/shoots.ics", nil)
+			req.Header.Set("If-None-Match", etag)
+			rec := httptest.NewRecorder()
+			suite.server.ServeHTTP(rec, req)
+			return rec
+		}
+
+		// When
+		unchanged := conditional()
+		suite.shoots.shoots[2].ShootLocation = "beacon"
+		changed := conditional()
+
+		// Then
+		suite.Equal(http.StatusNotModified, unchanged.Code, "should respond with 304")
+		suite.Equal(http.StatusOK, changed.Code, "should respond with 200 after a change")
+		suite.NotEqual(etag, changed.Header().Get("ETag"), "should change ETag")
+	})
+	suite.T().Run("should be disabled without token", func(t *testing.T) {
+		// Given
+		server := NewServer(suite.clients, suite.shoots, "")
+		rec := httptest.NewRecorder()
+
+		// When
+		server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/calendar/secret/shoots.ics", nil))
+
+		// Then
+		suite.Equal(http.StatusNotFound, rec.Code, "should respond with 404")
+	})
+}
+
+type fakeClientService struct {
+	clients map[int]*model.Client
+	nextId  int
+	// deletedBy is the actor of the last deletion
+	deletedBy string
+}
+
+func (f *fakeClientService) CreateClient(_ context.Context, client *model.Client) error {
+	if err := validation.Client(client); err != nil {
+		return err
+	}
+	f.nextId++
+	client.Id = f.nextId
+	client.CreatedAt = time.Now()
+	f.clients[client.Id] = client
+	return nil
+}
+
+func (f *fakeClientService) DeleteClient(ctx context.Context, id int) error {
+	if _, ok := f.clients[id]; !ok {
+		return errors.New(errors.ErrCodeClientNotFound, "client not found")
+	}
+	delete(f.clients, id)
+	f.deletedBy = audit.Actor(ctx)
+	return nil
+}
+
+func (f *fakeClientService) FindClients(context.Context, model.ClientFilter) (*model.ClientPage, error) {
+	return &model.ClientPage{}, nil
+}
+
+func (f *fakeClientService) SearchClients(context.Context, string) ([]model.Client, error) {
+	return nil, nil
+}
+
+func (f *fakeClientService) ImportClients(context.Context, io.Reader, importer.Options,
+	bool) (*importer.Report, error) {
+	return &importer.Report{}, nil
+}
+
+func (f *fakeClientService) ListClients(context.Context) ([]model.Client, error) {
+	clients := make([]model.Client, 0, len(f.clients))
+	for _, client := range f.clients {
+		clients = append(clients, *client)
+	}
+	return clients, nil
+}
+
+func (f *fakeClientService) UpdateClient(_ context.Context, id, version int,
+	update *model.ClientUpdate) (*model.Client, error) {
+	client, ok := f.clients[id]
+	if !ok {
+		return nil, errors.New(errors.ErrCodeClientNotFound, "client not found")
+	}
+	if client.Version != version {
+		return nil, errors.New(errors.ErrCodeVersionConflict, "client was changed by someone else")
+	}
+	if update.Phone != nil {
+		client.Phone = *update.Phone
+	}
+	client.Version++
+	return client, nil
+}
+
+func (f *fakeClientService) GetClientByID(_ context.Context, id int) (*model.Client, error) {
+	client, ok := f.clients[id]
+	if !ok {
+		return nil, errors.New(errors.ErrCodeClientNotFound, "client not found")
+	}
+	return client, nil
+}
+
+type fakeShootService struct {
+	shoots   map[int]*model.Shoot
+	nextId   int
+	from, to time.Time
+}
+
+func (f *fakeShootService) CreateShoot(_ context.Context, shoot *model.Shoot) error {
+	if err := validation.Shoot(shoot, shoot.ShootDate); err != nil {
+		return err
+	}
+	for _, other := range f.shoots {
+		if !shoot.AllowOverlap && other.ShootDate.Equal(shoot.ShootDate) {
+			return errors.Wrap(&service.ConflictError{Shoots: []model.Shoot{*other}},
+				errors.ErrCodeShootConflict, "shoot overlaps other shoots")
+		}
+	}
+	f.nextId++
+	shoot.Id = f.nextId
+	f.shoots[shoot.Id] = shoot
+	return nil
+}
+
+func (f *fakeShootService) DeleteShoot(_ context.Context, id int) error {
+	if _, ok := f.shoots[id]; !ok {
+		return errors.New(errors.ErrCodeShootNotFound, "shoot not found")
+	}
+	delete(f.shoots, id)
+	return nil
+}
+
+func (f *fakeShootService) FindShoots(context.Context, model.ShootFilter) (*model.ShootPage, error) {
+	return &model.ShootPage{}, nil
+}
+
+func (f *fakeShootService) ListShoots(_ context.Context, from, to time.Time) ([]model.Shoot, error) {
+	f.from, f.to = from, to
+
+	shoots := []model.Shoot{}
+	for id := 1; id <= f.nextId; id++ {
+		shoot, ok := f.shoots[id]
+		if ok && (from.IsZero() || !shoot.ShootDate.Before(from)) {
+			shoots = append(shoots, *shoot)
+		}
+	}
+	return shoots, nil
+}
+
+func (f *fakeShootService) UpdateShoot(_ context.Context, id, version int,
+	update *model.ShootUpdate) (*model.Shoot, error) {
+	shoot, ok := f.shoots[id]
+	if !ok {
+		return nil, errors.New(errors.ErrCodeShootNotFound, "shoot not found")
+	}
+	if update.ShootDate != nil {
+		shoot.ShootDate = *update.ShootDate
+	}
+	if update.StartTime != nil {
+		shoot.StartTime = *update.StartTime
+	}
+	if update.EndTime != nil {
+		shoot.EndTime = *update.EndTime
+	}
+	shoot.Version++
+	return shoot, nil
+}
+
+func (f *fakeShootService) DefaultSlotQuery(from, to time.Time) model.SlotQuery {
+	return model.SlotQuery{From: from, To: to}
+}
+
+func (f *fakeShootService) DefaultCurrency() string {
+	return "RUB"
+}
+
+func (f *fakeShootService) FindFreeSlots(context.Context, model.SlotQuery) ([]model.Slot, error) {
+	return nil, nil
+}
+
+func (f *fakeShootService) GetShootByID(_ context.Context, id int) (*model.Shoot, error) {
+	shoot, ok := f.shoots[id]
+	if !ok {
+		return nil, errors.New(errors.ErrCodeShootNotFound, "shoot not found")
+	}
+	return shoot, nil
+}
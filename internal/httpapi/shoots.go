@@ -0,0 +1,321 @@
+package httpapi
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Coiiap5e/photographer/internal/errors"
+	"github.com/Coiiap5e/photographer/internal/model"
+)
+
+const (
+	dateLayout = "2006-01-02"
+	timeLayout = "15:04"
+)
+
+type shootRequest struct {
+	ClientId      int       `json:"client_id"`
+	Date          string    `json:"date"`
+	StartTime     string    `json:"start_time"`
+	EndTime       string    `json:"end_time"`
+	ShootPrice    moneyJSON `json:"shoot_price"`
+	ShootLocation string    `json:"location"`
+	ShootType     string    `json:"shoot_type"`
+	Notes         string    `json:"notes"`
+	AllowOverlap  bool      `json:"allow_overlap"`
+}
+
+// shootPatchRequest changes only the fields present in the body. Version
+// must be the one last read, otherwise the update is rejected with 409.
+type shootPatchRequest struct {
+	Version       int        `json:"version"`
+	ClientId      *int       `json:"client_id"`
+	Date          *string    `json:"date"`
+	StartTime     *string    `json:"start_time"`
+	EndTime       *string    `json:"end_time"`
+	ShootPrice    *moneyJSON `json:"shoot_price"`
+	ShootLocation *string    `json:"location"`
+	ShootType     *string    `json:"shoot_type"`
+	Notes         *string    `json:"notes"`
+	AllowOverlap  *bool      `json:"allow_overlap"`
+}
+
+type shootResponse struct {
+	Id              int       `json:"id"`
+	ClientId        int       `json:"client_id"`
+	Date            string    `json:"date"`
+	StartTime       string    `json:"start_time"`
+	EndTime         string    `json:"end_time"`
+	ShootPrice      moneyJSON `json:"shoot_price"`
+	ShootLocation   string    `json:"location"`
+	ClientFirstName string    `json:"client_first_name"`
+	ClientLastName  string    `json:"client_last_name"`
+	ShootType       string    `json:"shoot_type"`
+	Notes           string    `json:"notes"`
+	AllowOverlap    bool      `json:"allow_overlap"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+	Version         int       `json:"version"`
+}
+
+// moneyJSON carries the amount as a decimal string, e.g. "1500.50", so no
+// precision is lost in float conversions. Currency defaults to APP_CURRENCY.
+type moneyJSON struct {
+	Amount   string `json:"amount"`
+	Currency string `json:"currency,omitempty"`
+}
+
+func newMoneyJSON(money model.Money) moneyJSON {
+	return moneyJSON{Amount: money.Decimal(), Currency: money.Currency}
+}
+
+func (m moneyJSON) toModel(defaultCurrency string) (model.Money, error) {
+	currency := defaultCurrency
+	if m.Currency != "" {
+		currency = strings.ToUpper(m.Currency)
+	}
+
+	if m.Amount == "" {
+		return model.Money{Currency: currency}, nil
+	}
+
+	money, err := model.ParseMoney(m.Amount, currency)
+	if err != nil {
+		return model.Money{}, errors.Wrap(err, errors.ErrCodeValidation,
+			"shoot_price must look like {\"amount\": \"1500.50\", \"currency\": \"RUB\"}")
+	}
+
+	return money, nil
+}
+
+func newShootResponse(shoot *model.Shoot) shootResponse {
+	return shootResponse{
+		Id:              shoot.Id,
+		ClientId:        shoot.ClientId,
+		Date:            shoot.ShootDate.Format(dateLayout),
+		StartTime:       shoot.StartTime.Format(timeLayout),
+		EndTime:         shoot.EndTime.Format(timeLayout),
+		ShootPrice:      newMoneyJSON(shoot.ShootPrice),
+		ShootLocation:   shoot.ShootLocation,
+		ClientFirstName: shoot.Client.FirstName,
+		ClientLastName:  shoot.Client.LastName,
+		ShootType:       shoot.ShootType,
+		Notes:           shoot.Notes,
+		AllowOverlap:    shoot.AllowOverlap,
+		CreatedAt:       shoot.CreatedAt,
+		UpdatedAt:       shoot.UpdatedAt,
+		Version:         shoot.Version,
+	}
+}
+
+func (req shootRequest) toModel(currency string) (*model.Shoot, error) {
+	date, err := time.Parse(dateLayout, req.Date)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeValidation, "date must use format YYYY-MM-DD")
+	}
+
+	startTime, err := parseTime(date, req.StartTime)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeValidation, "start_time must use format HH:MM")
+	}
+
+	endTime, err := parseTime(date, req.EndTime)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeValidation, "end_time must use format HH:MM")
+	}
+
+	price, err := req.ShootPrice.toModel(currency)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.Shoot{
+		ClientId:      req.ClientId,
+		ShootDate:     date,
+		StartTime:     startTime,
+		EndTime:       endTime,
+		ShootPrice:    price,
+		ShootLocation: strings.TrimSpace(req.ShootLocation),
+		ShootType:     strings.TrimSpace(req.ShootType),
+		Notes:         req.Notes,
+		AllowOverlap:  req.AllowOverlap,
+	}, nil
+}
+
+// toUpdate returns the update the request asks for. Times sent without a
+// date are taken on date, the current date of the shoot.
+func (req shootPatchRequest) toUpdate(currency string, date time.Time) (*model.ShootUpdate, error) {
+	if req.Version <= 0 {
+		return nil, errors.New(errors.ErrCodeValidation, "version is required")
+	}
+
+	update := &model.ShootUpdate{
+		ClientId:      req.ClientId,
+		ShootLocation: req.ShootLocation,
+		ShootType:     req.ShootType,
+		Notes:         req.Notes,
+		AllowOverlap:  req.AllowOverlap,
+	}
+
+	if req.Date != nil {
+		parsed, err := time.Parse(dateLayout, *req.Date)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeValidation, "date must use format YYYY-MM-DD")
+		}
+		date = parsed
+		update.ShootDate = &date
+	}
+
+	if req.StartTime != nil {
+		startTime, err := parseTime(date, *req.StartTime)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeValidation, "start_time must use format HH:MM")
+		}
+		update.StartTime = &startTime
+	}
+
+	if req.EndTime != nil {
+		endTime, err := parseTime(date, *req.EndTime)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeValidation, "end_time must use format HH:MM")
+		}
+		update.EndTime = &endTime
+	}
+
+	if req.ShootPrice != nil {
+		price, err := req.ShootPrice.toModel(currency)
+		if err != nil {
+			return nil, err
+		}
+		update.ShootPrice = &price
+	}
+
+	return update, nil
+}
+
+func parseTime(date time.Time, value string) (time.Time, error) {
+	return time.Parse(dateLayout+" "+timeLayout, date.Format(dateLayout)+" "+value)
+}
+
+func (s *Server) listShoots(w http.ResponseWriter, r *http.Request) {
+	var from, to time.Time
+	var err error
+
+	if value := r.URL.Query().Get("from"); value != "" {
+		if from, err = time.Parse(dateLayout, value); err != nil {
+			writeError(w, errors.Wrap(err, errors.ErrCodeInvalidInput, "from must use format YYYY-MM-DD"))
+			return
+		}
+	}
+
+	if value := r.URL.Query().Get("to"); value != "" {
+		if to, err = time.Parse(dateLayout, value); err != nil {
+			writeError(w, errors.Wrap(err, errors.ErrCodeInvalidInput, "to must use format YYYY-MM-DD"))
+			return
+		}
+	}
+
+	shoots, err := s.shootService.ListShoots(r.Context(), from, to)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response := make([]shootResponse, 0, len(shoots))
+	for i := range shoots {
+		response = append(response, newShootResponse(&shoots[i]))
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (s *Server) createShoot(w http.ResponseWriter, r *http.Request) {
+	var req shootRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	shoot, err := req.toModel(s.shootService.DefaultCurrency())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := s.shootService.CreateShoot(r.Context(), shoot); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, newShootResponse(shoot))
+}
+
+func (s *Server) getShoot(w http.ResponseWriter, r *http.Request) {
+	id, err := pathId(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	shoot, err := s.shootService.GetShootByID(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newShootResponse(shoot))
+}
+
+func (s *Server) updateShoot(w http.ResponseWriter, r *http.Request) {
+	id, err := pathId(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	var req shootPatchRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	var date time.Time
+	if req.Date == nil && (req.StartTime != nil || req.EndTime != nil) {
+		current, err := s.shootService.GetShootByID(r.Context(), id)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		date = current.ShootDate
+	}
+
+	update, err := req.toUpdate(s.shootService.DefaultCurrency(), date)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	shoot, err := s.shootService.UpdateShoot(r.Context(), id, req.Version, update)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newShootResponse(shoot))
+}
+
+func (s *Server) deleteShoot(w http.ResponseWriter, r *http.Request) {
+	id, err := pathId(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := s.shootService.DeleteShoot(r.Context(), id); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,145 @@
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Coiiap5e/photographer/internal/model"
+)
+
+const (
+	prodID = "-//Coiiap5e//photographer//EN"
+	// uidDomain makes UIDs globally unique as RFC 5545 recommends
+	uidDomain = "photographer"
+	// maxLineLength is the limit in octets before a line is folded
+	maxLineLength = 75
+
+	// Shoot times have no time zone, so they are written as floating
+	// local times that calendar apps show as is
+	localLayout = "20060102T150405"
+	utcLayout   = "20060102T150405Z"
+)
+
+// UID returns the stable event UID of a shoot
+func UID(shootID int) string {
+	return fmt.Sprintf("shoot-%d@%s", shootID, uidDomain)
+}
+
+// Encode writes shoots as an RFC 5545 VCALENDAR with one VEVENT per shoot.
+// The output depends only on the shoots, so unchanged shoots give identical
+// files.
+func Encode(w io.Writer, name string, shoots []model.Shoot) error {
+	bw := bufio.NewWriter(w)
+	e := &encoder{w: bw}
+
+	e.line("BEGIN", "VCALENDAR")
+	e.line("VERSION", "2.0")
+	e.line("PRODID", prodID)
+	e.line("CALSCALE", "GREGORIAN")
+	e.line("METHOD", "PUBLISH")
+	if name != "" {
+		e.line("X-WR-CALNAME", escape(name))
+	}
+
+	for i := range shoots {
+		e.event(&shoots[i])
+	}
+
+	e.line("END", "VCALENDAR")
+
+	if e.err != nil {
+		return e.err
+	}
+	return bw.Flush()
+}
+
+type encoder struct {
+	w   *bufio.Writer
+	err error
+}
+
+func (e *encoder) event(shoot *model.Shoot) {
+	start, end := shoot.Interval()
+
+	stamp := shoot.UpdatedAt
+	if stamp.IsZero() {
+		stamp = shoot.CreatedAt
+	}
+
+	client := shoot.ClientName()
+
+	summary := client
+	if shoot.ShootType != "" {
+		summary = shoot.ShootType + ": " + client
+	}
+
+	description := []string{"Client: " + client}
+	if shoot.ShootType != "" {
+		description = append(description, "Type: "+shoot.ShootType)
+	}
+	if !shoot.ShootPrice.IsZero() {
+		description = append(description, "Price: "+shoot.ShootPrice.String())
+	}
+	if shoot.Notes != "" {
+		description = append(description, "Notes: "+shoot.Notes)
+	}
+
+	e.line("BEGIN", "VEVENT")
+	e.line("UID", UID(shoot.Id))
+	e.line("DTSTAMP", stamp.UTC().Format(utcLayout))
+	if !shoot.UpdatedAt.IsZero() {
+		e.line("LAST-MODIFIED", shoot.UpdatedAt.UTC().Format(utcLayout))
+	}
+	if shoot.Version > 1 {
+		e.line("SEQUENCE", fmt.Sprint(shoot.Version-1))
+	}
+	e.line("DTSTART", start.Format(localLayout))
+	e.line("DTEND", end.Format(localLayout))
+	e.line("SUMMARY", escape(summary))
+	if shoot.ShootLocation != "" {
+		e.line("LOCATION", escape(shoot.ShootLocation))
+	}
+	e.line("DESCRIPTION", escape(strings.Join(description, "\n")))
+	e.line("END", "VEVENT")
+}
+
+// line writes a content line, folding it after maxLineLength octets
+// without splitting UTF-8 characters
+func (e *encoder) line(name, value string) {
+	if e.err != nil {
+		return
+	}
+
+	text := name + ":" + value
+	var b strings.Builder
+	width := 0
+
+	for _, r := range text {
+		size := utf8.RuneLen(r)
+		if width+size > maxLineLength {
+			b.WriteString("\r\n ")
+			width = 1
+		}
+		b.WriteRune(r)
+		width += size
+	}
+	b.WriteString("\r\n")
+
+	_, e.err = e.w.WriteString(b.String())
+}
+
+var escaper = strings.NewReplacer(
+	`\`, `\\`,
+	";", `\;`,
+	",", `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+)
+
+// escape quotes a TEXT value as required by RFC 5545 section 3.3.11
+func escape(value string) string {
+	return escaper.Replace(value)
+}
@@ -0,0 +1,112 @@
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Coiiap5e/photographer/internal/model"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestICalTestSuit(t *testing.T) {
+	suite.Run(t, new(ICalTestSuit))
+}
+
+type ICalTestSuit struct {
+	suite.Suite
+}
+
+func testShoot() model.Shoot {
+	return model.Shoot{
+		Id:            7,
+		ClientId:      1,
+		ShootDate:     time.Date(2025, 1, 20, 0, 0, 0, 0, time.UTC),
+		StartTime:     time.Date(0, 1, 1, 15, 0, 0, 0, time.UTC),
+		EndTime:       time.Date(0, 1, 1, 16, 30, 0, 0, time.UTC),
+		ShootPrice:    model.NewMoney(150000, "RUB"),
+		ShootLocation: "Pushkin blvd, 1",
+		ShootType:     "love story",
+		Notes:         "take an umbrella; rain\nsecond line",
+		UpdatedAt:     time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC),
+		Version:       3,
+		Client:        model.Client{Id: 1, FirstName: "Ivan", LastName: "Ivanov"},
+	}
+}
+
+func (suite *ICalTestSuit) encode(shoots ...model.Shoot) string {
+	var buf bytes.Buffer
+	suite.Require().NoError(Encode(&buf, "Shoots", shoots))
+	return buf.String()
+}
+
+func (suite *ICalTestSuit) TestEncode() {
+	suite.T().Run("should write one event per shoot", func(t *testing.T) {
+		// Given
+		second := testShoot()
+		second.Id = 8
+
+		// When
+		out := suite.encode(testShoot(), second)
+
+		// Then
+		suite.True(strings.HasPrefix(out, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"), "should start with calendar header")
+		suite.True(strings.HasSuffix(out, "END:VCALENDAR\r\n"), "should end calendar")
+		suite.Equal(2, strings.Count(out, "BEGIN:VEVENT\r\n"), "should write two events")
+		suite.Contains(out, "UID:shoot-7@photographer\r\n", "should derive UID from shoot ID")
+		suite.Contains(out, "UID:shoot-8@photographer\r\n", "should derive UID from shoot ID")
+	})
+
+	suite.T().Run("should write shoot fields", func(t *testing.T) {
+		// When
+		out := suite.encode(testShoot())
+
+		// Then
+		suite.Contains(out, "DTSTART:20250120T150000\r\n", "should write floating start time")
+		suite.Contains(out, "DTEND:20250120T163000\r\n", "should write floating end time")
+		suite.Contains(out, "DTSTAMP:20250110T120000Z\r\n", "should stamp with update time")
+		suite.Contains(out, "SEQUENCE:2\r\n", "should derive sequence from version")
+		suite.Contains(out, "SUMMARY:love story: Ivan Ivanov\r\n", "should put type and client into summary")
+		suite.Contains(out, `LOCATION:Pushkin blvd\, 1`, "should escape commas")
+		suite.Contains(unfold(out), `Notes: take an umbrella\; rain\nsecond line`,
+			"should escape semicolons and new lines")
+	})
+
+	suite.T().Run("should end shoot after midnight on the next day", func(t *testing.T) {
+		// Given
+		shoot := testShoot()
+		shoot.StartTime = time.Date(0, 1, 1, 23, 0, 0, 0, time.UTC)
+		shoot.EndTime = time.Date(0, 1, 1, 1, 0, 0, 0, time.UTC)
+
+		// When
+		out := suite.encode(shoot)
+
+		// Then
+		suite.Contains(out, "DTEND:20250121T010000\r\n", "should move end to the next day")
+	})
+
+	suite.T().Run("should fold long lines without splitting characters", func(t *testing.T) {
+		// Given
+		shoot := testShoot()
+		shoot.Notes = strings.Repeat("Фотосессия в парке ", 10)
+
+		// When
+		out := suite.encode(shoot)
+
+		// Then
+		for _, line := range strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n") {
+			suite.LessOrEqual(len(line), 75, "should fold lines at 75 octets")
+			suite.True(strings.ToValidUTF8(line, "?") == line, "should keep lines valid UTF-8")
+		}
+		suite.Contains(unfold(out), "Notes: "+strings.TrimSpace(shoot.Notes), "should keep text after unfolding")
+	})
+
+	suite.T().Run("should give identical output for unchanged shoots", func(t *testing.T) {
+		suite.Equal(suite.encode(testShoot()), suite.encode(testShoot()), "should be deterministic")
+	})
+}
+
+func unfold(out string) string {
+	return strings.ReplaceAll(out, "\r\n ", "")
+}
@@ -0,0 +1,318 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/Coiiap5e/photographer/internal/errors"
+	"github.com/Coiiap5e/photographer/internal/model"
+	"github.com/Coiiap5e/photographer/internal/validation"
+)
+
+// Client fields a CSV column can be mapped to
+const (
+	FieldFirstName = "first_name"
+	FieldLastName  = "last_name"
+	FieldPhone     = "phone"
+	FieldSocialURL = "social_url"
+)
+
+var fields = []string{FieldFirstName, FieldLastName, FieldPhone, FieldSocialURL}
+
+// headerAliases are the column names recognised without an explicit mapping,
+// compared case-insensitively
+var headerAliases = map[string][]string{
+	FieldFirstName: {"first_name", "first name", "firstname", "name", "имя"},
+	FieldLastName:  {"last_name", "last name", "lastname", "surname", "фамилия"},
+	FieldPhone:     {"phone", "phone number", "mobile", "телефон"},
+	FieldSocialURL: {"social_url", "social network url", "social network", "social", "соцсеть"},
+}
+
+// Mapping maps client fields to CSV column names. Fields that are not
+// mapped are looked up by their usual names.
+type Mapping map[string]string
+
+// ParseMapping reads a mapping like "first_name=Имя,phone=Телефон"
+func ParseMapping(value string) (Mapping, error) {
+	mapping := Mapping{}
+	if strings.TrimSpace(value) == "" {
+		return mapping, nil
+	}
+
+	for _, part := range strings.Split(value, ",") {
+		field, column, ok := strings.Cut(part, "=")
+		field = strings.TrimSpace(field)
+		if !ok || strings.TrimSpace(column) == "" || headerAliases[field] == nil {
+			return nil, errors.New(errors.ErrCodeInvalidInput, fmt.Sprintf(
+				"invalid mapping %q, use field=column with fields %s", part, strings.Join(fields, ", ")))
+		}
+		mapping[field] = strings.TrimSpace(column)
+	}
+
+	return mapping, nil
+}
+
+type Options struct {
+	Mapping Mapping
+	// Delimiter separates columns, zero detects ',' or ';' from the header
+	Delimiter rune
+}
+
+type Row struct {
+	Line   int
+	Client model.Client
+}
+
+type Duplicate struct {
+	Row
+	Reason string
+}
+
+type Invalid struct {
+	Line   int
+	Reason string
+}
+
+// Report describes what an import adds and what it skips
+type Report struct {
+	Rows       int
+	New        []Row
+	Duplicates []Duplicate
+	Invalid    []Invalid
+}
+
+// Print writes a summary followed by the skipped rows
+func (r *Report) Print(w io.Writer) {
+	fmt.Fprintf(w, "rows: %d, new: %d, duplicates: %d, invalid: %d\n",
+		r.Rows, len(r.New), len(r.Duplicates), len(r.Invalid))
+
+	for _, duplicate := range r.Duplicates {
+		fmt.Fprintf(w, "line %d: %s %s skipped, %s\n", duplicate.Line,
+			duplicate.Client.FirstName, duplicate.Client.LastName, duplicate.Reason)
+	}
+
+	for _, invalid := range r.Invalid {
+		fmt.Fprintf(w, "line %d: %s\n", invalid.Line, invalid.Reason)
+	}
+}
+
+// PlanClients reads clients from CSV and sorts the rows into new clients,
+// duplicates of existing clients or earlier rows, and invalid rows. A client
+// is a duplicate if it has the same phone, or the same name and social URL.
+func PlanClients(r io.Reader, opts Options, existing []model.Client) (*Report, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInvalidInput, "failed to read CSV")
+	}
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = opts.Delimiter
+	if reader.Comma == 0 {
+		reader.Comma = detectDelimiter(data)
+	}
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInvalidInput, "failed to read CSV header")
+	}
+
+	columns, err := resolveColumns(header, opts.Mapping)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := newIndex()
+	for _, client := range existing {
+		seen.add(client, fmt.Sprintf("client #%d %s %s", client.Id, client.FirstName, client.LastName))
+	}
+
+	report := &Report{}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line, _ := reader.FieldPos(0)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInvalidInput, "failed to read CSV")
+		}
+		if isBlank(record) {
+			continue
+		}
+
+		report.Rows++
+
+		client, reason := clientFromRecord(record, columns)
+		if reason != "" {
+			report.Invalid = append(report.Invalid, Invalid{Line: line, Reason: reason})
+			continue
+		}
+
+		row := Row{Line: line, Client: client}
+		if match := seen.find(client); match != "" {
+			report.Duplicates = append(report.Duplicates, Duplicate{Row: row, Reason: "same " + match})
+			continue
+		}
+
+		seen.add(client, fmt.Sprintf("line %d", line))
+		report.New = append(report.New, row)
+	}
+
+	return report, nil
+}
+
+func detectDelimiter(data []byte) rune {
+	header, _, _ := bytes.Cut(data, []byte("\n"))
+	if bytes.Count(header, []byte(";")) > bytes.Count(header, []byte(",")) {
+		return ';'
+	}
+	return ','
+}
+
+// resolveColumns returns the index of every field found in header
+func resolveColumns(header []string, mapping Mapping) (map[string]int, error) {
+	byName := make(map[string]int, len(header))
+	for i, name := range header {
+		byName[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	columns := make(map[string]int)
+	for _, field := range fields {
+		names := headerAliases[field]
+		if column, ok := mapping[field]; ok {
+			names = []string{column}
+		}
+		for _, name := range names {
+			if i, ok := byName[strings.ToLower(name)]; ok {
+				columns[field] = i
+				break
+			}
+		}
+	}
+
+	var missing []string
+	for _, field := range []string{FieldFirstName, FieldLastName, FieldPhone} {
+		if _, ok := columns[field]; !ok {
+			missing = append(missing, field)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, errors.New(errors.ErrCodeValidation, fmt.Sprintf(
+			"CSV has no columns for %s, map them with field=column", strings.Join(missing, ", ")))
+	}
+
+	return columns, nil
+}
+
+func clientFromRecord(record []string, columns map[string]int) (model.Client, string) {
+	value := func(field string) string {
+		i, ok := columns[field]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	client := model.Client{
+		FirstName:        value(FieldFirstName),
+		LastName:         value(FieldLastName),
+		SocialNetworkUrl: value(FieldSocialURL),
+	}
+
+	var missing []string
+	if client.FirstName == "" {
+		missing = append(missing, FieldFirstName)
+	}
+	if client.LastName == "" {
+		missing = append(missing, FieldLastName)
+	}
+	if value(FieldPhone) == "" {
+		missing = append(missing, FieldPhone)
+	}
+	if len(missing) > 0 {
+		return client, "missing " + strings.Join(missing, ", ")
+	}
+
+	phone, err := NormalizePhone(value(FieldPhone))
+	if err != nil {
+		return client, err.Error()
+	}
+	client.Phone = phone
+
+	if err := validation.Client(&client); err != nil {
+		return client, validation.Fields(err).Error()
+	}
+
+	return client, ""
+}
+
+func isBlank(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// index finds clients that were already seen by phone or by name and
+// social URL
+type index struct {
+	byPhone map[string]string
+	byName  map[string]string
+}
+
+func newIndex() *index {
+	return &index{byPhone: map[string]string{}, byName: map[string]string{}}
+}
+
+func (idx *index) add(client model.Client, label string) {
+	if key := phoneKey(client.Phone); key != "" {
+		if _, ok := idx.byPhone[key]; !ok {
+			idx.byPhone[key] = label
+		}
+	}
+	if key := nameKey(client); key != "" {
+		if _, ok := idx.byName[key]; !ok {
+			idx.byName[key] = label
+		}
+	}
+}
+
+// find returns why client matches a seen client, or "" if it does not
+func (idx *index) find(client model.Client) string {
+	if label, ok := idx.byPhone[phoneKey(client.Phone)]; ok {
+		return "phone as " + label
+	}
+	if label, ok := idx.byName[nameKey(client)]; ok {
+		return "name and social network as " + label
+	}
+	return ""
+}
+
+func phoneKey(phone string) string {
+	if normalized, err := NormalizePhone(phone); err == nil {
+		phone = normalized
+	}
+	return digits(phone)
+}
+
+// nameKey is empty for clients without a social URL, since namesakes are
+// common and the name alone does not identify a client
+func nameKey(client model.Client) string {
+	url := strings.ToLower(strings.TrimSpace(client.SocialNetworkUrl))
+	if url == "" {
+		return ""
+	}
+	name := strings.Fields(strings.ToLower(client.FirstName + " " + client.LastName))
+	sort.Strings(name)
+	return strings.Join(name, " ") + "|" + strings.TrimSuffix(url, "/")
+}
@@ -0,0 +1,138 @@
+package importer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/Coiiap5e/photographer/internal/errors"
+	"github.com/Coiiap5e/photographer/internal/model"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestImporterTestSuit(t *testing.T) {
+	suite.Run(t, new(ImporterTestSuit))
+}
+
+type ImporterTestSuit struct {
+	suite.Suite
+}
+
+func (suite *ImporterTestSuit) TestNormalizePhone() {
+	for input, expected := range map[string]string{
+		"+7(900)000-00-00":  "+7(900)000-00-00",
+		"8 900 000 00 00":   "+7(900)000-00-00",
+		"9000000000":        "+7(900)000-00-00",
+		"+49 30 1234567":    "+49301234567",
+		"+1 (202) 555-0100": "+12025550100",
+	} {
+		phone, err := NormalizePhone(input)
+		suite.NoError(err, "should not return error for %q", input)
+		suite.Equal(expected, phone, "should normalize %q", input)
+	}
+
+	_, err := NormalizePhone("12-34")
+	suite.Error(err, "should reject too short number")
+}
+
+func (suite *ImporterTestSuit) TestParseMapping() {
+	suite.T().Run("should parse field=column pairs", func(t *testing.T) {
+		// When
+		mapping, err := ParseMapping("first_name=Имя, phone = Телефон")
+
+		// Then
+		suite.NoError(err, "should not return error")
+		suite.Equal(Mapping{FieldFirstName: "Имя", FieldPhone: "Телефон"}, mapping, "should map fields")
+	})
+	suite.T().Run("should reject unknown field", func(t *testing.T) {
+		// When
+		_, err := ParseMapping("email=Почта")
+
+		// Then
+		suite.True(errors.IsErrorCode(err, errors.ErrCodeInvalidInput), "should return invalid input error")
+	})
+}
+
+func (suite *ImporterTestSuit) TestPlanClients() {
+	existing := []model.Client{
+		{Id: 1, FirstName: "Ivan", LastName: "Ivanov", Phone: "+7(900)000-00-00"},
+		{Id: 2, FirstName: "Anna", LastName: "Petrova", Phone: "+7(901)111-11-11",
+			SocialNetworkUrl: "vk.com/a.petrova"},
+	}
+
+	suite.T().Run("should sort rows into new, duplicate and invalid", func(t *testing.T) {
+		// Given
+		csv := "\ufeffИмя;Фамилия;Телефон;Соцсеть\n" +
+			"Maria;Sidorova;8 (902) 222-22-22;\n" +
+			"Ivan;Ivanov;89000000000;\n" +
+			"Anna;Petrova;+7 999 999 99 99;VK.com/a.petrova/\n" +
+			"Maria;Sidorova;+79022222222;\n" +
+			";Smirnov;+79033333333;\n" +
+			"Oleg;Smirnov;123;\n" +
+			"Olga;Orlova;+79044444444;my vk page\n" +
+			";;;\n"
+
+		// When
+		report, err := PlanClients(strings.NewReader(csv), Options{}, existing)
+
+		// Then
+		suite.Require().NoError(err, "should not return error")
+		suite.Equal(7, report.Rows, "should count non-blank rows")
+
+		suite.Require().Len(report.New, 1, "should find one new client")
+		suite.Equal(2, report.New[0].Line, "should keep line number")
+		suite.Equal("+7(902)222-22-22", report.New[0].Client.Phone, "should normalize phone")
+
+		suite.Require().Len(report.Duplicates, 3, "should find duplicates")
+		suite.Contains(report.Duplicates[0].Reason, "phone as client #1", "should match existing phone")
+		suite.Contains(report.Duplicates[1].Reason, "name and social network as client #2",
+			"should match existing name and social URL")
+		suite.Contains(report.Duplicates[2].Reason, "phone as line 2", "should match earlier row")
+
+		suite.Require().Len(report.Invalid, 3, "should find invalid rows")
+		suite.Equal("missing first_name", report.Invalid[0].Reason, "should report missing field")
+		suite.Contains(report.Invalid[1].Reason, "invalid phone number", "should report bad phone")
+		suite.Contains(report.Invalid[2].Reason, "social_network_url: must be a web address",
+			"should report bad social URL")
+	})
+
+	suite.T().Run("should use column mapping", func(t *testing.T) {
+		// Given
+		csv := "Client,Surname,Mobile number\nOleg,Smirnov,+7 903 333 33 33\n"
+		mapping := Mapping{FieldFirstName: "client", FieldPhone: "Mobile number"}
+
+		// When
+		report, err := PlanClients(strings.NewReader(csv), Options{Mapping: mapping}, nil)
+
+		// Then
+		suite.Require().NoError(err, "should not return error")
+		suite.Require().Len(report.New, 1, "should find new client")
+		suite.Equal("Oleg", report.New[0].Client.FirstName, "should read mapped column")
+	})
+
+	suite.T().Run("should reject CSV without required columns", func(t *testing.T) {
+		// When
+		_, err := PlanClients(strings.NewReader("First name,Last name\nOleg,Smirnov\n"), Options{}, nil)
+
+		// Then
+		suite.True(errors.IsErrorCode(err, errors.ErrCodeValidation), "should return validation error")
+		suite.ErrorContains(err, "phone", "should name the missing column")
+	})
+}
+
+func (suite *ImporterTestSuit) TestReportPrint() {
+	// Given
+	duplicate := Duplicate{
+		Row:    Row{Line: 3, Client: model.Client{FirstName: "Ivan", LastName: "Ivanov"}},
+		Reason: "same phone as line 2",
+	}
+	report := &Report{Rows: 2, New: []Row{{Line: 2}}, Duplicates: []Duplicate{duplicate}}
+	var buf bytes.Buffer
+
+	// When
+	report.Print(&buf)
+
+	// Then
+	suite.Equal("rows: 2, new: 1, duplicates: 1, invalid: 0\n"+
+		"line 3: Ivan Ivanov skipped, same phone as line 2\n", buf.String(), "should print summary")
+}
@@ -0,0 +1,40 @@
+package importer
+
+import (
+	"fmt"
+	"strings"
+)
+
+// NormalizePhone formats Russian numbers like the rest of the app,
+// +7(900)000-00-00, and other numbers as + followed by digits. Numbers
+// without a country code and numbers starting with 8 are taken as Russian.
+func NormalizePhone(value string) (string, error) {
+	number := digits(value)
+	international := strings.HasPrefix(strings.TrimSpace(value), "+")
+
+	switch {
+	case !international && len(number) == 10:
+		number = "7" + number
+	case !international && len(number) == 11 && number[0] == '8':
+		number = "7" + number[1:]
+	}
+
+	if len(number) < 10 || len(number) > 15 {
+		return "", fmt.Errorf("invalid phone number %q", value)
+	}
+
+	if len(number) == 11 && number[0] == '7' {
+		return fmt.Sprintf("+7(%s)%s-%s-%s", number[1:4], number[4:7], number[7:9], number[9:]), nil
+	}
+
+	return "+" + number, nil
+}
+
+func digits(value string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, value)
+}
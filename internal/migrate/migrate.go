@@ -0,0 +1,342 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Coiiap5e/photographer/internal/database"
+	"github.com/Coiiap5e/photographer/internal/errors"
+	"github.com/Coiiap5e/photographer/internal/migration"
+	sqlitemigration "github.com/Coiiap5e/photographer/internal/migration/sqlite"
+)
+
+var (
+	fileNameRe = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+	createRe   = regexp.MustCompile(`(?i)CREATE\s+TABLE\s+(?:IF\s+NOT\s+EXISTS\s+)?([\w."]+)`)
+	refRe      = regexp.MustCompile(`(?i)REFERENCES\s+([\w."]+)`)
+)
+
+// ownColumns are the columns of the schema_migrations table the stores create
+var ownColumns = []string{"version", "name", "applied_at"}
+
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+type Status struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+}
+
+type Migrator struct {
+	store      store
+	migrations []Migration
+}
+
+// store keeps the schema_migrations table of one kind of database
+type store interface {
+	// withLock runs fn with the applied migrations while no other migrator
+	// can change the schema
+	withLock(ctx context.Context, fn func(applied map[int64]time.Time) error) error
+	// applied reads the applied migrations without locking or changing the
+	// database, none if schema_migrations does not exist yet
+	applied(ctx context.Context) (map[int64]time.Time, error)
+	// apply runs script and records mig as applied or rolled back in one
+	// transaction, it is only called by fn of withLock
+	apply(ctx context.Context, mig Migration, script string, up bool) error
+}
+
+// New returns a Migrator for the migrations embedded into the binary.
+func New(db *database.DB) (*Migrator, error) {
+	return NewWithFS(db, migration.FS)
+}
+
+func NewWithFS(db *database.DB, fsys fs.FS) (*Migrator, error) {
+	return newMigrator(&postgresStore{pool: db.Pool}, fsys)
+}
+
+// NewSQLite returns a Migrator for the SQLite migrations embedded into the
+// binary.
+func NewSQLite(db *database.SQLite) (*Migrator, error) {
+	return newMigrator(&sqliteStore{db: db.DB}, sqlitemigration.FS)
+}
+
+func newMigrator(store store, fsys fs.FS) (*Migrator, error) {
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := Validate(migrations); err != nil {
+		return nil, err
+	}
+
+	return &Migrator{store: store, migrations: migrations}, nil
+}
+
+// Load reads <version>_<name>.up.sql / .down.sql pairs from the root of fsys
+// and returns them sorted by version.
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeMigration, "failed to read migrations")
+	}
+
+	byVersion := make(map[int64]*Migration)
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".sql") {
+			continue
+		}
+
+		match := fileNameRe.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, errors.New(errors.ErrCodeMigration,
+				fmt.Sprintf("invalid migration file name %q", entry.Name()))
+		}
+
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeMigration,
+				fmt.Sprintf("invalid migration version in %q", entry.Name()))
+		}
+
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeMigration,
+				fmt.Sprintf("failed to read %q", entry.Name()))
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+
+		if m.Name != match[2] {
+			return nil, errors.New(errors.ErrCodeMigration,
+				fmt.Sprintf("duplicate migration version %d: %s and %s", version, m.Name, match[2]))
+		}
+
+		if match[3] == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		migrations = append(migrations, *m)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// Validate checks that every migration has both directions and that no
+// migration references a table which is only created by a later one.
+func Validate(migrations []Migration) error {
+	created := make(map[string]bool)
+
+	for _, m := range migrations {
+		if strings.TrimSpace(m.Up) == "" {
+			return errors.New(errors.ErrCodeMigration,
+				fmt.Sprintf("migration %d_%s has no up script", m.Version, m.Name))
+		}
+		if strings.TrimSpace(m.Down) == "" {
+			return errors.New(errors.ErrCodeMigration,
+				fmt.Sprintf("migration %d_%s has no down script", m.Version, m.Name))
+		}
+
+		for _, match := range createRe.FindAllStringSubmatch(m.Up, -1) {
+			created[tableName(match[1])] = true
+		}
+
+		for _, match := range refRe.FindAllStringSubmatch(m.Up, -1) {
+			table := tableName(match[1])
+			if !created[table] {
+				return errors.New(errors.ErrCodeMigration,
+					fmt.Sprintf("migration %d_%s references table %q before it is created",
+						m.Version, m.Name, table))
+			}
+		}
+	}
+
+	return nil
+}
+
+func tableName(name string) string {
+	name = strings.ToLower(strings.ReplaceAll(name, `"`, ""))
+	return strings.TrimPrefix(name, "public.")
+}
+
+// Up applies all pending migrations.
+func (m *Migrator) Up(ctx context.Context) error {
+	if len(m.migrations) == 0 {
+		return nil
+	}
+	return m.Goto(ctx, m.migrations[len(m.migrations)-1].Version)
+}
+
+// Down rolls back the n most recently applied migrations.
+func (m *Migrator) Down(ctx context.Context, n int) error {
+	if n <= 0 {
+		return errors.New(errors.ErrCodeMigration, "number of migrations to roll back must be > 0")
+	}
+
+	return m.withLock(ctx, func(applied map[int64]time.Time) error {
+		for i := len(m.migrations) - 1; i >= 0 && n > 0; i-- {
+			if _, ok := applied[m.migrations[i].Version]; !ok {
+				continue
+			}
+			if err := m.apply(ctx, m.migrations[i], false); err != nil {
+				return err
+			}
+			n--
+		}
+		return nil
+	})
+}
+
+// Goto migrates up or down until version is the last applied migration.
+// Version 0 rolls back everything.
+func (m *Migrator) Goto(ctx context.Context, version int64) error {
+	if version != 0 && !m.known(version) {
+		return errors.New(errors.ErrCodeMigration,
+			fmt.Sprintf("unknown migration version %d", version))
+	}
+
+	return m.withLock(ctx, func(applied map[int64]time.Time) error {
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			mig := m.migrations[i]
+			if _, ok := applied[mig.Version]; ok && mig.Version > version {
+				if err := m.apply(ctx, mig, false); err != nil {
+					return err
+				}
+			}
+		}
+
+		for _, mig := range m.migrations {
+			if _, ok := applied[mig.Version]; !ok && mig.Version <= version {
+				if err := m.apply(ctx, mig, true); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// Status reports every known migration and whether it has been applied.
+// It only reads the database, so it takes no lock and creates no table.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.store.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := m.checkApplied(applied); err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, mig := range m.migrations {
+		appliedAt, ok := applied[mig.Version]
+		statuses = append(statuses, Status{
+			Version:   mig.Version,
+			Name:      mig.Name,
+			Applied:   ok,
+			AppliedAt: appliedAt,
+		})
+	}
+
+	return statuses, nil
+}
+
+func (m *Migrator) known(version int64) bool {
+	for _, mig := range m.migrations {
+		if mig.Version == version {
+			return true
+		}
+	}
+	return false
+}
+
+// apply runs mig up or down
+func (m *Migrator) apply(ctx context.Context, mig Migration, up bool) error {
+	script, direction := mig.Down, "down"
+	if up {
+		script, direction = mig.Up, "up"
+	}
+
+	if err := m.store.apply(ctx, mig, script, up); err != nil {
+		return err
+	}
+
+	log.Printf("migration %d_%s %s", mig.Version, mig.Name, direction)
+
+	return nil
+}
+
+// withLock runs fn with the applied migrations under the lock of the store.
+// It fails if a migration was applied that m does not know about.
+func (m *Migrator) withLock(ctx context.Context, fn func(applied map[int64]time.Time) error) error {
+	return m.store.withLock(ctx, func(applied map[int64]time.Time) error {
+		if err := m.checkApplied(applied); err != nil {
+			return err
+		}
+		return fn(applied)
+	})
+}
+
+// checkApplied fails if a migration was applied that m does not know about
+func (m *Migrator) checkApplied(applied map[int64]time.Time) error {
+	for version := range applied {
+		if !m.known(version) {
+			return errors.New(errors.ErrCodeMigration,
+				fmt.Sprintf("applied migration %d has no migration file", version))
+		}
+	}
+	return nil
+}
+
+// checkLayout fails unless columns, those of an existing schema_migrations
+// table, include the ones the stores create. Other tools use the same table
+// name, golang-migrate keeps only version and dirty in it.
+func checkLayout(columns []string) error {
+	for _, own := range ownColumns {
+		if !slices.Contains(columns, own) {
+			return errors.New(errors.ErrCodeMigration, fmt.Sprintf(
+				"schema_migrations has columns %s, it was not created by this migrator "+
+					"and may belong to another migration tool", strings.Join(columns, ", ")))
+		}
+	}
+	return nil
+}
+
+// migrationError wraps the error of running mig
+func migrationError(err error, mig Migration, up bool) error {
+	direction := "down"
+	if up {
+		direction = "up"
+	}
+	return errors.Wrap(err, errors.ErrCodeMigration,
+		fmt.Sprintf("migration %d_%s %s failed", mig.Version, mig.Name, direction))
+}
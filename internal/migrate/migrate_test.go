@@ -0,0 +1,188 @@
+package migrate
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/Coiiap5e/photographer/internal/database"
+	"github.com/Coiiap5e/photographer/internal/errors"
+	"github.com/Coiiap5e/photographer/internal/migration"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestMigrateTestSuit(t *testing.T) {
+	suite.Run(t, new(MigrateTestSuit))
+}
+
+type MigrateTestSuit struct {
+	suite.Suite
+}
+
+func (suite *MigrateTestSuit) TestLoad() {
+	suite.T().Run("should load migrations sorted by version", func(t *testing.T) {
+		// Given
+		fsys := fstest.MapFS{
+			"002_b.up.sql":   {Data: []byte("CREATE TABLE b (id INT)")},
+			"002_b.down.sql": {Data: []byte("DROP TABLE b")},
+			"001_a.up.sql":   {Data: []byte("CREATE TABLE a (id INT)")},
+			"001_a.down.sql": {Data: []byte("DROP TABLE a")},
+		}
+
+		// When
+		migrations, err := Load(fsys)
+
+		// Then
+		suite.NoError(err, "should not return error")
+		suite.Require().Len(migrations, 2, "should load 2 migrations")
+		suite.Equal(int64(1), migrations[0].Version, "first migration should have lowest version")
+		suite.Equal("a", migrations[0].Name, "should parse migration name")
+		suite.Equal("DROP TABLE a", migrations[0].Down, "should read down script")
+		suite.Equal(int64(2), migrations[1].Version, "second migration should have next version")
+	})
+	suite.T().Run("should return error for invalid file name", func(t *testing.T) {
+		// Given
+		fsys := fstest.MapFS{
+			"clients.sql": {Data: []byte("CREATE TABLE clients (id INT)")},
+		}
+
+		// When
+		_, err := Load(fsys)
+
+		// Then
+		suite.True(errors.IsErrorCode(err, errors.ErrCodeMigration), "should return migration error")
+	})
+	suite.T().Run("should return error for duplicate version", func(t *testing.T) {
+		// Given
+		fsys := fstest.MapFS{
+			"001_a.up.sql": {Data: []byte("CREATE TABLE a (id INT)")},
+			"001_b.up.sql": {Data: []byte("CREATE TABLE b (id INT)")},
+		}
+
+		// When
+		_, err := Load(fsys)
+
+		// Then
+		suite.True(errors.IsErrorCode(err, errors.ErrCodeMigration), "should return migration error")
+	})
+}
+
+func (suite *MigrateTestSuit) TestValidate() {
+	suite.T().Run("should accept embedded migrations", func(t *testing.T) {
+		// Given
+		migrations, err := Load(migration.FS)
+		suite.Require().NoError(err, "precondition: migrations should load")
+
+		// When
+		err = Validate(migrations)
+
+		// Then
+		suite.NoError(err, "embedded migrations should be valid")
+	})
+	suite.T().Run("should detect table referenced before it is created", func(t *testing.T) {
+		// Given
+		migrations := []Migration{
+			{
+				Version: 1,
+				Name:    "shoots",
+				Up:      "CREATE TABLE shoots (client_id INTEGER REFERENCES public.clients(id))",
+				Down:    "DROP TABLE shoots",
+			},
+			{
+				Version: 2,
+				Name:    "clients",
+				Up:      "CREATE TABLE clients (id SERIAL PRIMARY KEY)",
+				Down:    "DROP TABLE clients",
+			},
+		}
+
+		// When
+		err := Validate(migrations)
+
+		// Then
+		suite.True(errors.IsErrorCode(err, errors.ErrCodeMigration), "should return migration error")
+		suite.ErrorContains(err, `references table "clients"`, "should name the missing table")
+	})
+	suite.T().Run("should detect missing down script", func(t *testing.T) {
+		// Given
+		migrations := []Migration{
+			{Version: 1, Name: "clients", Up: "CREATE TABLE clients (id INT)"},
+		}
+
+		// When
+		err := Validate(migrations)
+
+		// Then
+		suite.True(errors.IsErrorCode(err, errors.ErrCodeMigration), "should return migration error")
+	})
+}
+
+// sqliteMigrator returns a Migrator for a new SQLite database file
+func (suite *MigrateTestSuit) sqliteMigrator(t *testing.T) (*Migrator, *database.SQLite) {
+	db, err := database.NewSQLite(context.Background(), filepath.Join(t.TempDir(), "test.db"))
+	suite.Require().NoError(err, "precondition: database should open")
+	t.Cleanup(db.Close)
+
+	migrator, err := NewSQLite(db)
+	suite.Require().NoError(err, "precondition: migrator should load")
+
+	return migrator, db
+}
+
+func (suite *MigrateTestSuit) TestStatus() {
+	suite.T().Run("should report nothing applied without creating schema_migrations", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		migrator, db := suite.sqliteMigrator(t)
+
+		// When
+		statuses, err := migrator.Status(ctx)
+
+		// Then
+		suite.NoError(err, "should not return error")
+		suite.NotEmpty(statuses, "should list the known migrations")
+		for _, status := range statuses {
+			suite.False(status.Applied, "migration %d should not be applied", status.Version)
+		}
+
+		var tables int
+		err = db.DB.QueryRowContext(ctx,
+			`SELECT count(*) FROM sqlite_master WHERE name = 'schema_migrations'`).Scan(&tables)
+		suite.NoError(err, "should count tables")
+		suite.Zero(tables, "should not create schema_migrations")
+	})
+	suite.T().Run("should report applied migrations", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		migrator, _ := suite.sqliteMigrator(t)
+		suite.Require().NoError(migrator.Up(ctx), "precondition: migrations should apply")
+
+		// When
+		statuses, err := migrator.Status(ctx)
+
+		// Then
+		suite.NoError(err, "should not return error")
+		for _, status := range statuses {
+			suite.True(status.Applied, "migration %d should be applied", status.Version)
+			suite.False(status.AppliedAt.IsZero(), "migration %d should have applied time", status.Version)
+		}
+	})
+	suite.T().Run("should reject schema_migrations of golang-migrate", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		migrator, db := suite.sqliteMigrator(t)
+		_, err := db.DB.ExecContext(ctx,
+			`CREATE TABLE schema_migrations (version INTEGER NOT NULL PRIMARY KEY, dirty BOOLEAN NOT NULL)`)
+		suite.Require().NoError(err, "precondition: foreign table should be created")
+
+		// When
+		_, statusErr := migrator.Status(ctx)
+		upErr := migrator.Up(ctx)
+
+		// Then
+		suite.True(errors.IsErrorCode(statusErr, errors.ErrCodeMigration), "status should return migration error")
+		suite.ErrorContains(statusErr, "version, dirty", "should name the columns found")
+		suite.True(errors.IsErrorCode(upErr, errors.ErrCodeMigration), "up should return migration error")
+	})
+}
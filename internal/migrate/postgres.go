@@ -0,0 +1,164 @@
+package migrate
+
+import (
+	"context"
+	"time"
+
+	"github.com/Coiiap5e/photographer/internal/database"
+	"github.com/Coiiap5e/photographer/internal/errors"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// lockKey is the advisory lock taken while migrations run, so two
+// instances started at the same time do not apply the same migration twice.
+const lockKey = 2025101000
+
+type postgresStore struct {
+	pool *pgxpool.Pool
+	// conn holds the advisory lock while withLock runs
+	conn *pgxpool.Conn
+}
+
+func (s *postgresStore) withLock(ctx context.Context, fn func(applied map[int64]time.Time) error) error {
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeDBConnection, "failed to acquire connection")
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, "SELECT pg_advisory_lock($1)", lockKey); err != nil {
+		return errors.Wrap(err, errors.ErrCodeMigration, "failed to lock migrations")
+	}
+	defer conn.Exec(context.Background(), "SELECT pg_advisory_unlock($1)", lockKey)
+
+	// CREATE TABLE IF NOT EXISTS would keep a table of another tool
+	columns, err := s.columns(ctx, conn)
+	if err != nil {
+		return err
+	}
+	if len(columns) > 0 {
+		if err := checkLayout(columns); err != nil {
+			return err
+		}
+	}
+
+	query := `
+CREATE TABLE IF NOT EXISTS schema_migrations
+(
+    version BIGINT PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+)`
+
+	if _, err := conn.Exec(ctx, query); err != nil {
+		return errors.Wrap(err, errors.ErrCodeMigration, "failed to create schema_migrations table")
+	}
+
+	applied, err := s.read(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	s.conn = conn
+	defer func() { s.conn = nil }()
+
+	return fn(applied)
+}
+
+func (s *postgresStore) applied(ctx context.Context) (map[int64]time.Time, error) {
+	columns, err := s.columns(ctx, s.pool)
+	if err != nil {
+		return nil, err
+	}
+	if len(columns) == 0 {
+		return map[int64]time.Time{}, nil
+	}
+	if err := checkLayout(columns); err != nil {
+		return nil, err
+	}
+
+	return s.read(ctx, s.pool)
+}
+
+// columns returns the columns of schema_migrations, none if it does not exist
+func (s *postgresStore) columns(ctx context.Context, q database.Querier) ([]string, error) {
+	query := `
+SELECT column_name
+FROM information_schema.columns
+WHERE table_schema = current_schema() AND table_name = 'schema_migrations'`
+
+	rows, err := q.Query(ctx, query)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeDBSelect, "failed to get schema_migrations columns")
+	}
+
+	defer rows.Close()
+
+	var columns []string
+	for rows.Next() {
+		var column string
+		if err := rows.Scan(&column); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeDBSelect, "failed to get schema_migrations column")
+		}
+		columns = append(columns, column)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeDBSelect, "error during rows iteration")
+	}
+
+	return columns, nil
+}
+
+// read returns the applied migrations recorded in schema_migrations
+func (s *postgresStore) read(ctx context.Context, q database.Querier) (map[int64]time.Time, error) {
+	rows, err := q.Query(ctx, `SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeDBSelect, "failed to get applied migrations")
+	}
+	defer rows.Close()
+
+	applied := make(map[int64]time.Time)
+	for rows.Next() {
+		var version int64
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeDBSelect, "failed to get applied migration")
+		}
+		applied[version] = appliedAt
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeDBSelect, "error during rows iteration")
+	}
+
+	return applied, nil
+}
+
+func (s *postgresStore) apply(ctx context.Context, mig Migration, script string, up bool) error {
+	tx, err := s.conn.Begin(ctx)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeMigration, "failed to begin transaction")
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, script); err != nil {
+		return migrationError(err, mig, up)
+	}
+
+	if up {
+		_, err = tx.Exec(ctx, `INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`,
+			mig.Version, mig.Name)
+	} else {
+		_, err = tx.Exec(ctx, `DELETE FROM schema_migrations WHERE version = $1`, mig.Version)
+	}
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeMigration, "failed to record migration")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return errors.Wrap(err, errors.ErrCodeMigration, "failed to commit migration")
+	}
+
+	return nil
+}
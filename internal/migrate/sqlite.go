@@ -0,0 +1,140 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Coiiap5e/photographer/internal/errors"
+)
+
+// sqliteStore keeps schema_migrations in SQLite. SQLite allows one writer at
+// a time, so migrations need no extra lock.
+type sqliteStore struct {
+	db *sql.DB
+}
+
+func (s *sqliteStore) withLock(ctx context.Context, fn func(applied map[int64]time.Time) error) error {
+	// CREATE TABLE IF NOT EXISTS would keep a table of another tool
+	columns, err := s.columns(ctx)
+	if err != nil {
+		return err
+	}
+	if len(columns) > 0 {
+		if err := checkLayout(columns); err != nil {
+			return err
+		}
+	}
+
+	query := `
+CREATE TABLE IF NOT EXISTS schema_migrations
+(
+    version INTEGER PRIMARY KEY,
+    name TEXT NOT NULL,
+    applied_at TEXT NOT NULL DEFAULT (datetime('now'))
+)`
+
+	if _, err := s.db.ExecContext(ctx, query); err != nil {
+		return errors.Wrap(err, errors.ErrCodeMigration, "failed to create schema_migrations table")
+	}
+
+	applied, err := s.read(ctx)
+	if err != nil {
+		return err
+	}
+
+	return fn(applied)
+}
+
+func (s *sqliteStore) applied(ctx context.Context) (map[int64]time.Time, error) {
+	columns, err := s.columns(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(columns) == 0 {
+		return map[int64]time.Time{}, nil
+	}
+	if err := checkLayout(columns); err != nil {
+		return nil, err
+	}
+
+	return s.read(ctx)
+}
+
+// columns returns the columns of schema_migrations, none if it does not exist
+func (s *sqliteStore) columns(ctx context.Context) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT name FROM pragma_table_info('schema_migrations')`)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeDBSelect, "failed to get schema_migrations columns")
+	}
+	defer rows.Close()
+
+	var columns []string
+	for rows.Next() {
+		var column string
+		if err := rows.Scan(&column); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeDBSelect, "failed to get schema_migrations column")
+		}
+		columns = append(columns, column)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeDBSelect, "error during rows iteration")
+	}
+
+	return columns, nil
+}
+
+// read returns the applied migrations recorded in schema_migrations. The
+// rows are closed when it returns, before the only connection is used again.
+func (s *sqliteStore) read(ctx context.Context) (map[int64]time.Time, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeDBSelect, "failed to get applied migrations")
+	}
+	defer rows.Close()
+
+	applied := make(map[int64]time.Time)
+	for rows.Next() {
+		var version int64
+		var appliedAt string
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeDBSelect, "failed to get applied migration")
+		}
+		applied[version], _ = time.Parse(time.DateTime, appliedAt)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeDBSelect, "error during rows iteration")
+	}
+
+	return applied, nil
+}
+
+func (s *sqliteStore) apply(ctx context.Context, mig Migration, script string, up bool) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeMigration, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		return migrationError(err, mig, up)
+	}
+
+	if up {
+		_, err = tx.ExecContext(ctx, `INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`,
+			mig.Version, mig.Name)
+	} else {
+		_, err = tx.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version = $1`, mig.Version)
+	}
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeMigration, "failed to record migration")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, errors.ErrCodeMigration, "failed to commit migration")
+	}
+
+	return nil
+}
@@ -0,0 +1,7 @@
+ALTER TABLE shoots
+    DROP COLUMN IF EXISTS version,
+    DROP COLUMN IF EXISTS updated_at;
+
+ALTER TABLE clients
+    DROP COLUMN IF EXISTS version,
+    DROP COLUMN IF EXISTS updated_at;
\ No newline at end of file
@@ -0,0 +1,7 @@
+ALTER TABLE clients
+    ADD COLUMN updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+
+ALTER TABLE shoots
+    ADD COLUMN updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
\ No newline at end of file
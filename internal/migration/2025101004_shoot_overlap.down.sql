@@ -0,0 +1,3 @@
+ALTER TABLE shoots
+    DROP CONSTRAINT IF EXISTS shoots_no_overlap,
+    DROP COLUMN IF EXISTS allow_overlap;
\ No newline at end of file
@@ -0,0 +1,22 @@
+ALTER TABLE shoots
+    ADD COLUMN allow_overlap BOOLEAN NOT NULL DEFAULT false;
+
+-- Shoots booked before the constraint existed may already overlap
+UPDATE shoots s SET allow_overlap = true
+WHERE EXISTS (
+    SELECT 1 FROM shoots o
+    WHERE o.id <> s.id
+      AND tsrange(o.date + o.start_time,
+                  CASE WHEN o.end_time > o.start_time THEN o.date + o.end_time
+                       ELSE o.date + 1 + o.end_time END)
+       && tsrange(s.date + s.start_time,
+                  CASE WHEN s.end_time > s.start_time THEN s.date + s.end_time
+                       ELSE s.date + 1 + s.end_time END)
+);
+
+ALTER TABLE shoots
+    ADD CONSTRAINT shoots_no_overlap EXCLUDE USING gist (
+        tsrange(date + start_time,
+                CASE WHEN end_time > start_time THEN date + end_time
+                     ELSE date + 1 + end_time END) WITH &&
+    ) WHERE (NOT allow_overlap);
\ No newline at end of file
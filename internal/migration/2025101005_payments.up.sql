@@ -0,0 +1,12 @@
+CREATE TABLE payments
+(
+    id SERIAL PRIMARY KEY,
+    shoot_id INTEGER NOT NULL REFERENCES shoots(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('deposit', 'final', 'refund')),
+    amount DECIMAL(10,0) NOT NULL CHECK (amount > 0),
+    paid_at DATE NOT NULL DEFAULT CURRENT_DATE,
+    notes TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX payments_shoot_id_idx ON payments (shoot_id);
\ No newline at end of file
@@ -0,0 +1,10 @@
+-- Amounts go back to whole roubles. Kopecks are rounded up in both tables,
+-- so a price and its payment stay equal and a payment of less than a
+-- rouble does not become 0 and break CHECK (amount > 0)
+ALTER TABLE payments
+    DROP COLUMN currency,
+    ALTER COLUMN amount TYPE DECIMAL(10,0) USING CEIL(amount / 100.0);
+
+ALTER TABLE shoots
+    DROP COLUMN shoot_currency,
+    ALTER COLUMN shoot_price TYPE DECIMAL(10,0) USING CEIL(shoot_price / 100.0);
\ No newline at end of file
@@ -0,0 +1,9 @@
+-- Prices and payments are stored in minor units (kopecks, cents) with a
+-- currency code. Existing amounts were whole roubles.
+ALTER TABLE shoots
+    ALTER COLUMN shoot_price TYPE BIGINT USING shoot_price * 100,
+    ADD COLUMN shoot_currency CHAR(3) NOT NULL DEFAULT 'RUB';
+
+ALTER TABLE payments
+    ALTER COLUMN amount TYPE BIGINT USING amount * 100,
+    ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'RUB';
\ No newline at end of file
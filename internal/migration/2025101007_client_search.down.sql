@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS clients_search_phone_idx;
+DROP INDEX IF EXISTS clients_search_text_idx;
+DROP EXTENSION IF EXISTS pg_trgm;
\ No newline at end of file
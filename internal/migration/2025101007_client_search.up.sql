@@ -0,0 +1,12 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- The expressions must match the ones in repository.SearchClients for the
+-- indexes to be used. Phones keep only their digits, a leading 8 of a full
+-- Russian number becomes 7 as in the search query.
+CREATE INDEX clients_search_text_idx ON clients USING gin (
+    (first_name || ' ' || last_name || ' ' || COALESCE(social_network_url, '')) gin_trgm_ops
+);
+
+CREATE INDEX clients_search_phone_idx ON clients USING gin (
+    regexp_replace(regexp_replace(COALESCE(phone, ''), '[^0-9]', '', 'g'), '^8([0-9]{10})$', '7\1') gin_trgm_ops
+);
\ No newline at end of file
@@ -0,0 +1,7 @@
+ALTER TABLE shoots
+    ADD COLUMN client_first_name VARCHAR(255),
+    ADD COLUMN client_last_name VARCHAR(255);
+
+UPDATE shoots s SET client_first_name = c.first_name, client_last_name = c.last_name
+FROM clients c
+WHERE c.id = s.client_id;
\ No newline at end of file
@@ -0,0 +1,4 @@
+-- Client names are read from clients with a JOIN instead
+ALTER TABLE shoots
+    DROP COLUMN IF EXISTS client_first_name,
+    DROP COLUMN IF EXISTS client_last_name;
\ No newline at end of file
@@ -0,0 +1,17 @@
+DROP INDEX IF EXISTS shoots_deleted_at_idx;
+DROP INDEX IF EXISTS clients_deleted_at_idx;
+
+-- Without soft deletion the trash can only be emptied
+DELETE FROM shoots WHERE deleted_at IS NOT NULL;
+DELETE FROM clients WHERE deleted_at IS NOT NULL;
+
+ALTER TABLE shoots DROP CONSTRAINT IF EXISTS shoots_no_overlap;
+ALTER TABLE shoots
+    ADD CONSTRAINT shoots_no_overlap EXCLUDE USING gist (
+        tsrange(date + start_time,
+                CASE WHEN end_time > start_time THEN date + end_time
+                     ELSE date + 1 + end_time END) WITH &&
+    ) WHERE (NOT allow_overlap);
+
+ALTER TABLE shoots DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE clients DROP COLUMN IF EXISTS deleted_at;
\ No newline at end of file
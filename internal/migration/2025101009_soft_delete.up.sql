@@ -0,0 +1,14 @@
+ALTER TABLE clients ADD COLUMN deleted_at TIMESTAMP;
+ALTER TABLE shoots ADD COLUMN deleted_at TIMESTAMP;
+
+-- Shoots in the trash no longer take up time in the schedule
+ALTER TABLE shoots DROP CONSTRAINT IF EXISTS shoots_no_overlap;
+ALTER TABLE shoots
+    ADD CONSTRAINT shoots_no_overlap EXCLUDE USING gist (
+        tsrange(date + start_time,
+                CASE WHEN end_time > start_time THEN date + end_time
+                     ELSE date + 1 + end_time END) WITH &&
+    ) WHERE (NOT allow_overlap AND deleted_at IS NULL);
+
+CREATE INDEX clients_deleted_at_idx ON clients (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX shoots_deleted_at_idx ON shoots (deleted_at) WHERE deleted_at IS NOT NULL;
\ No newline at end of file
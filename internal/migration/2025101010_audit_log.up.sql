@@ -0,0 +1,14 @@
+-- No foreign keys, the history outlives purged clients and shoots
+CREATE TABLE audit_log
+(
+    id BIGSERIAL PRIMARY KEY,
+    entity VARCHAR(20) NOT NULL CHECK (entity IN ('client', 'shoot', 'payment')),
+    entity_id INTEGER NOT NULL,
+    action VARCHAR(20) NOT NULL CHECK (action IN ('create', 'update', 'delete', 'restore', 'purge')),
+    before JSONB,
+    after JSONB,
+    actor VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX audit_log_entity_idx ON audit_log (entity, entity_id, id);
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE shoots
+    ALTER COLUMN start_time DROP NOT NULL,
+    ALTER COLUMN end_time DROP NOT NULL;
\ No newline at end of file
@@ -0,0 +1,12 @@
+-- The overlap range needs both times, NULL would make it unbounded. Shoots
+-- stored without times get midnight and are kept out of the check rather
+-- than clashing with the whole day.
+UPDATE shoots SET
+    start_time = COALESCE(start_time, '00:00'),
+    end_time = COALESCE(end_time, start_time, '00:00'),
+    allow_overlap = true
+WHERE start_time IS NULL OR end_time IS NULL;
+
+ALTER TABLE shoots
+    ALTER COLUMN start_time SET NOT NULL,
+    ALTER COLUMN end_time SET NOT NULL;
\ No newline at end of file
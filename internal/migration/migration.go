@@ -0,0 +1,8 @@
+package migration
+
+import "embed"
+
+// FS holds the SQL migrations shipped with the binary.
+//
+//go:embed *.sql
+var FS embed.FS
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS audit_log;
+DROP TABLE IF EXISTS payments;
+DROP TABLE IF EXISTS shoots;
+DROP TABLE IF EXISTS clients;
\ No newline at end of file
@@ -0,0 +1,65 @@
+CREATE TABLE clients
+(
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    first_name TEXT NOT NULL,
+    last_name TEXT NOT NULL,
+    phone TEXT,
+    social_network_url TEXT,
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f000', 'now')),
+    updated_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f000', 'now')),
+    version INTEGER NOT NULL DEFAULT 1,
+    deleted_at TEXT
+);
+
+-- Overlapping shoots are rejected by the repository, SQLite has no
+-- exclusion constraints
+CREATE TABLE shoots
+(
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    client_id INTEGER NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    date TEXT NOT NULL,
+    start_time TEXT,
+    end_time TEXT,
+    shoot_price INTEGER,
+    shoot_currency TEXT NOT NULL DEFAULT 'RUB',
+    location TEXT,
+    shoot_type TEXT,
+    notes TEXT,
+    allow_overlap INTEGER NOT NULL DEFAULT 0,
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f000', 'now')),
+    updated_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f000', 'now')),
+    version INTEGER NOT NULL DEFAULT 1,
+    deleted_at TEXT
+);
+
+CREATE INDEX shoots_client_id_idx ON shoots (client_id);
+CREATE INDEX shoots_date_idx ON shoots (date, start_time);
+
+CREATE TABLE payments
+(
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    shoot_id INTEGER NOT NULL REFERENCES shoots(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL CHECK (kind IN ('deposit', 'final', 'refund')),
+    amount INTEGER NOT NULL CHECK (amount > 0),
+    currency TEXT NOT NULL DEFAULT 'RUB',
+    paid_at TEXT NOT NULL DEFAULT (date('now')),
+    notes TEXT,
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f000', 'now'))
+);
+
+CREATE INDEX payments_shoot_id_idx ON payments (shoot_id);
+
+-- No foreign keys, the history outlives purged clients and shoots
+CREATE TABLE audit_log
+(
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    entity TEXT NOT NULL CHECK (entity IN ('client', 'shoot', 'payment')),
+    entity_id INTEGER NOT NULL,
+    action TEXT NOT NULL CHECK (action IN ('create', 'update', 'delete', 'restore', 'purge')),
+    before TEXT,
+    after TEXT,
+    actor TEXT NOT NULL,
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f000', 'now'))
+);
+
+CREATE INDEX audit_log_entity_idx ON audit_log (entity, entity_id, id);
\ No newline at end of file
@@ -0,0 +1,10 @@
+package sqlite
+
+import "embed"
+
+// FS holds the SQLite migrations shipped with the binary. Timestamps are
+// stored as UTC text in the form 2006-01-02 15:04:05.000000, dates as
+// 2006-01-02 and times of day as 15:04:05.
+//
+//go:embed *.sql
+var FS embed.FS
@@ -0,0 +1,38 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type AuditEntity string
+
+const (
+	AuditClient  AuditEntity = "client"
+	AuditShoot   AuditEntity = "shoot"
+	AuditPayment AuditEntity = "payment"
+)
+
+type AuditAction string
+
+const (
+	AuditCreate  AuditAction = "create"
+	AuditUpdate  AuditAction = "update"
+	AuditDelete  AuditAction = "delete"
+	AuditRestore AuditAction = "restore"
+	AuditPurge   AuditAction = "purge"
+)
+
+// AuditEntry is one change of a client, shoot or payment. Before and After
+// are the stored row as JSON, Before is empty for a created row and After
+// for a purged one.
+type AuditEntry struct {
+	Id        int64
+	Entity    AuditEntity
+	EntityId  int
+	Action    AuditAction
+	Before    json.RawMessage
+	After     json.RawMessage
+	Actor     string
+	CreatedAt time.Time
+}
@@ -9,4 +9,16 @@ type Client struct {
 	Phone            string
 	SocialNetworkUrl string
 	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	Version          int
+	// DeletedAt is set while the client is in the trash
+	DeletedAt *time.Time
+}
+
+// ClientUpdate holds the fields to change, nil fields are left as is
+type ClientUpdate struct {
+	FirstName        *string
+	LastName         *string
+	Phone            *string
+	SocialNetworkUrl *string
 }
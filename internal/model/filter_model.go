@@ -0,0 +1,82 @@
+package model
+
+import "time"
+
+type ShootSort string
+
+const (
+	// ShootSortDate orders by date and start time, the default
+	ShootSortDate    ShootSort = "date"
+	ShootSortPrice   ShootSort = "price"
+	ShootSortCreated ShootSort = "created"
+)
+
+func (s ShootSort) Valid() bool {
+	switch s {
+	case "", ShootSortDate, ShootSortPrice, ShootSortCreated:
+		return true
+	}
+	return false
+}
+
+// ShootFilter selects shoots, zero fields do not filter
+type ShootFilter struct {
+	// From and To are the first and last shoot date, inclusive
+	From     time.Time
+	To       time.Time
+	ClientId int
+	// ShootType matches exactly, ignoring case
+	ShootType string
+	// Location matches a substring, ignoring case
+	Location string
+	// MinPrice and MaxPrice are inclusive and keep only shoots priced in
+	// their currency
+	MinPrice *Money
+	MaxPrice *Money
+	Sort     ShootSort
+	Desc     bool
+	// After is the last shoot of the previous page, the page starts after it
+	// in sort order
+	After *Shoot
+	// Limit is the page size, zero returns all shoots
+	Limit int
+}
+
+// ShootPage is one page of shoots
+type ShootPage struct {
+	Shoots []Shoot
+	// Next selects the following page, nil on the last page
+	Next *ShootFilter
+}
+
+type ClientSort string
+
+const (
+	// ClientSortName orders by last and first name, the default
+	ClientSortName    ClientSort = "name"
+	ClientSortCreated ClientSort = "created"
+)
+
+func (s ClientSort) Valid() bool {
+	switch s {
+	case "", ClientSortName, ClientSortCreated:
+		return true
+	}
+	return false
+}
+
+type ClientFilter struct {
+	Sort ClientSort
+	Desc bool
+	// After is the last client of the previous page
+	After *Client
+	// Limit is the page size, zero returns all clients
+	Limit int
+}
+
+// ClientPage is one page of clients
+type ClientPage struct {
+	Clients []Client
+	// Next selects the following page, nil on the last page
+	Next *ClientFilter
+}
@@ -0,0 +1,127 @@
+package model
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Money is an amount in minor units (kopecks, cents) of an ISO 4217 currency
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+// zeroDecimalCurrencies have no minor units, all other currencies use two
+var zeroDecimalCurrencies = map[string]bool{
+	"CLP": true,
+	"ISK": true,
+	"JPY": true,
+	"KRW": true,
+	"VND": true,
+}
+
+func NewMoney(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// ValidCurrency reports whether code looks like an ISO 4217 code, e.g. RUB
+func ValidCurrency(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+	for _, r := range code {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+func decimals(currency string) int {
+	if zeroDecimalCurrencies[currency] {
+		return 0
+	}
+	return 2
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+func (m Money) Sub(other Money) Money {
+	return Money{Amount: m.Amount - other.Amount, Currency: m.Currency}
+}
+
+// Decimal formats the amount in major units without the currency, e.g. 1500.50
+func (m Money) Decimal() string {
+	digits := decimals(m.Currency)
+	amount := m.Amount
+
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	if digits == 0 {
+		return sign + strconv.FormatInt(amount, 10)
+	}
+
+	unit := int64(1)
+	for i := 0; i < digits; i++ {
+		unit *= 10
+	}
+
+	return fmt.Sprintf("%s%d.%0*d", sign, amount/unit, digits, amount%unit)
+}
+
+func (m Money) String() string {
+	if m.Currency == "" {
+		return m.Decimal()
+	}
+	return m.Decimal() + " " + m.Currency
+}
+
+// ParseMoney reads amounts like "1500", "1500.5" or "20.50 USD".
+// currency is used when the value has no currency of its own.
+func ParseMoney(value, currency string) (Money, error) {
+	fields := strings.Fields(value)
+	if len(fields) == 2 {
+		currency = strings.ToUpper(fields[1])
+	} else if len(fields) != 1 {
+		return Money{}, fmt.Errorf("invalid amount %q", value)
+	}
+
+	if !ValidCurrency(currency) {
+		return Money{}, fmt.Errorf("invalid currency %q", currency)
+	}
+
+	number := strings.Replace(fields[0], ",", ".", 1)
+	whole, fraction, _ := strings.Cut(number, ".")
+
+	digits := decimals(currency)
+	if len(fraction) > digits {
+		return Money{}, fmt.Errorf("%s allows at most %d decimal places", currency, digits)
+	}
+
+	negative := strings.HasPrefix(whole, "-")
+	whole = strings.TrimPrefix(whole, "-")
+	if whole == "" && fraction == "" {
+		return Money{}, fmt.Errorf("invalid amount %q", value)
+	}
+	if whole == "" {
+		whole = "0"
+	}
+
+	amount, err := strconv.ParseInt(whole+fraction+strings.Repeat("0", digits-len(fraction)), 10, 64)
+	if err != nil || strings.ContainsAny(whole+fraction, "+-") {
+		return Money{}, fmt.Errorf("invalid amount %q", value)
+	}
+
+	if negative {
+		amount = -amount
+	}
+
+	return Money{Amount: amount, Currency: currency}, nil
+}
@@ -0,0 +1,68 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+func TestMoneyTestSuit(t *testing.T) {
+	suite.Run(t, new(MoneyTestSuit))
+}
+
+type MoneyTestSuit struct {
+	suite.Suite
+}
+
+func (suite *MoneyTestSuit) TestParseMoney() {
+	suite.T().Run("should parse whole amount in default currency", func(t *testing.T) {
+		// When
+		money, err := ParseMoney("1500", "RUB")
+
+		// Then
+		suite.NoError(err, "should not return error")
+		suite.Equal(NewMoney(150000, "RUB"), money, "should store amount in kopecks")
+	})
+
+	suite.T().Run("should parse fraction and own currency", func(t *testing.T) {
+		// When
+		money, err := ParseMoney("20.5 usd", "RUB")
+
+		// Then
+		suite.NoError(err, "should not return error")
+		suite.Equal(NewMoney(2050, "USD"), money, "should use currency from the value")
+	})
+
+	suite.T().Run("should accept comma as decimal separator", func(t *testing.T) {
+		// When
+		money, err := ParseMoney("99,99", "EUR")
+
+		// Then
+		suite.NoError(err, "should not return error")
+		suite.Equal(NewMoney(9999, "EUR"), money, "should parse cents")
+	})
+
+	suite.T().Run("should reject invalid values", func(t *testing.T) {
+		for _, value := range []string{"", "abc", "1.234", "10 RUB extra", "1.-5", "100 JPYY"} {
+			// When
+			_, err := ParseMoney(value, "RUB")
+
+			// Then
+			suite.Error(err, "should return error for %q", value)
+		}
+	})
+
+	suite.T().Run("should reject fraction for currency without minor units", func(t *testing.T) {
+		// When
+		_, err := ParseMoney("100.5", "JPY")
+
+		// Then
+		suite.Error(err, "should return error")
+	})
+}
+
+func (suite *MoneyTestSuit) TestString() {
+	suite.Equal("1500.05 RUB", NewMoney(150005, "RUB").String(), "should format kopecks")
+	suite.Equal("-0.50 USD", NewMoney(-50, "USD").String(), "should format negative amounts")
+	suite.Equal("1500 JPY", NewMoney(1500, "JPY").String(), "should not add decimals to yen")
+}
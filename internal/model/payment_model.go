@@ -0,0 +1,55 @@
+package model
+
+import "time"
+
+type PaymentKind string
+
+const (
+	PaymentDeposit PaymentKind = "deposit"
+	PaymentFinal   PaymentKind = "final"
+	PaymentRefund  PaymentKind = "refund"
+)
+
+func (k PaymentKind) Valid() bool {
+	return k == PaymentDeposit || k == PaymentFinal || k == PaymentRefund
+}
+
+type Payment struct {
+	Id        int
+	ShootId   int
+	Kind      PaymentKind
+	Amount    Money
+	PaidAt    time.Time
+	Notes     string
+	CreatedAt time.Time
+}
+
+// ShootBalance compares the price of a shoot with what has been paid,
+// refunds are subtracted from Paid
+type ShootBalance struct {
+	ShootId         int
+	ClientId        int
+	ClientFirstName string
+	ClientLastName  string
+	ShootDate       time.Time
+	Price           Money
+	Paid            Money
+}
+
+func (b ShootBalance) Due() Money {
+	return b.Price.Sub(b.Paid)
+}
+
+// ClientBalance sums the shoots of a client, there is one balance for every
+// currency the client has been charged in
+type ClientBalance struct {
+	ClientId  int
+	FirstName string
+	LastName  string
+	Price     Money
+	Paid      Money
+}
+
+func (b ClientBalance) Due() Money {
+	return b.Price.Sub(b.Paid)
+}
@@ -1,18 +1,61 @@
 package model
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 type Shoot struct {
-	Id              int
-	ClientId        int
-	ShootDate       time.Time
-	StartTime       time.Time
-	EndTime         time.Time
-	ShootPrice      int
-	ShootLocation   string
-	ClientFirstName string
-	ClientLastName  string
-	ShootType       string
-	Notes           string
-	CreatedAt       time.Time
+	Id            int
+	ClientId      int
+	ShootDate     time.Time
+	StartTime     time.Time
+	EndTime       time.Time
+	ShootPrice    Money
+	ShootLocation string
+	ShootType     string
+	Notes         string
+	AllowOverlap  bool
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	Version       int
+	// DeletedAt is set while the shoot is in the trash
+	DeletedAt *time.Time
+	// Client is read from clients, only Id, names, phone and social URL are
+	// filled in
+	Client Client
+}
+
+// ClientName returns the first and last name of the shoot's client
+func (s *Shoot) ClientName() string {
+	return strings.TrimSpace(s.Client.FirstName + " " + s.Client.LastName)
+}
+
+// ShootUpdate holds the fields to change, nil fields are left as is
+type ShootUpdate struct {
+	ClientId      *int
+	ShootDate     *time.Time
+	StartTime     *time.Time
+	EndTime       *time.Time
+	ShootPrice    *Money
+	ShootLocation *string
+	ShootType     *string
+	Notes         *string
+	AllowOverlap  *bool
+}
+
+// Interval returns when the shoot starts and ends. A shoot that ends at or
+// before its start time is treated as ending on the next day.
+func (s *Shoot) Interval() (time.Time, time.Time) {
+	start := atClock(s.ShootDate, s.StartTime)
+	end := atClock(s.ShootDate, s.EndTime)
+	if !end.After(start) {
+		end = end.AddDate(0, 0, 1)
+	}
+	return start, end
+}
+
+func atClock(date, clock time.Time) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(),
+		clock.Hour(), clock.Minute(), 0, 0, time.UTC)
 }
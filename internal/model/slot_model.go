@@ -0,0 +1,31 @@
+package model
+
+import "time"
+
+// Slot is a free period in the schedule
+type Slot struct {
+	Start time.Time
+	End   time.Time
+}
+
+func (s Slot) String() string {
+	end := s.End.Format("15:04")
+	if s.End.YearDay() != s.Start.YearDay() || s.End.Year() != s.Start.Year() {
+		end += " (+1)"
+	}
+	return s.Start.Format("Mon 02.01.2006 15:04") + "-" + end
+}
+
+type SlotQuery struct {
+	// From and To are the first and last day to search, inclusive
+	From time.Time
+	To   time.Time
+	// DayStart and DayEnd are working hours as offsets from midnight.
+	// DayEnd not after DayStart means the working day ends after midnight.
+	DayStart time.Duration
+	DayEnd   time.Duration
+	Duration time.Duration
+	Buffer   time.Duration
+	DaysOff  []time.Weekday
+	DatesOff []time.Time
+}
@@ -0,0 +1,8 @@
+package model
+
+// Trash holds the deleted clients and shoots that can still be restored,
+// most recently deleted first
+type Trash struct {
+	Clients []Client
+	Shoots  []Shoot
+}
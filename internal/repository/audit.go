@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Coiiap5e/photographer/internal/audit"
+	"github.com/Coiiap5e/photographer/internal/database"
+	myerrors "github.com/Coiiap5e/photographer/internal/errors"
+	"github.com/Coiiap5e/photographer/internal/model"
+)
+
+type Audit interface {
+	GetHistory(ctx context.Context, entity model.AuditEntity, id int) ([]model.AuditEntry, error)
+}
+
+type postgresAudit struct {
+	db *database.DB
+}
+
+func NewAudit(db *database.DB) Audit {
+	return &postgresAudit{db: db}
+}
+
+// auditTables are the tables the audited entities are stored in
+var auditTables = map[model.AuditEntity]string{
+	model.AuditClient:  "clients",
+	model.AuditShoot:   "shoots",
+	model.AuditPayment: "payments",
+}
+
+// rowSnapshot is a stored row as JSON
+type rowSnapshot struct {
+	id  int
+	row []byte
+}
+
+// snapshot locks the rows of entity matching condition and returns them as
+// they are before a change
+func snapshot(ctx context.Context, tx database.Querier, entity model.AuditEntity, condition string,
+	args ...any) ([]rowSnapshot, error) {
+	query := `
+SELECT t.id, to_jsonb(t)
+FROM ` + auditTables[entity] + ` t
+WHERE ` + condition + `
+ORDER BY t.id
+FOR UPDATE`
+
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return nil, myerrors.Wrap(err, myerrors.ErrCodeDBSelect, "failed to read rows for audit")
+	}
+
+	defer rows.Close()
+
+	var snapshots []rowSnapshot
+
+	for rows.Next() {
+		var s rowSnapshot
+		if err := rows.Scan(&s.id, &s.row); err != nil {
+			return nil, myerrors.Wrap(err, myerrors.ErrCodeDBSelect, "failed to read row for audit")
+		}
+		snapshots = append(snapshots, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, myerrors.Wrap(err, myerrors.ErrCodeDBSelect, "error during rows iteration")
+	}
+
+	return snapshots, nil
+}
+
+// record writes an audit entry for the row of entity with id. before is the
+// row as it was, nil for a new row. The row as it is now is read in tx, so
+// record must be called after the change.
+func record(ctx context.Context, tx database.Querier, entity model.AuditEntity, id int,
+	action model.AuditAction, before []byte) error {
+	query := `
+INSERT INTO audit_log
+	(entity, entity_id, action, actor, before, after)
+SELECT $1, $2::int, $3, $4, $5::jsonb,
+	(SELECT to_jsonb(t) FROM ` + auditTables[entity] + ` t WHERE t.id = $2::int)`
+
+	_, err := tx.Exec(ctx, query, entity, id, action, audit.Actor(ctx), before)
+	if err != nil {
+		return myerrors.Wrap(err, myerrors.ErrCodeDBInsert, "failed to write audit log")
+	}
+
+	return nil
+}
+
+// recordAll writes an audit entry for every row in before
+func recordAll(ctx context.Context, tx database.Querier, entity model.AuditEntity, action model.AuditAction,
+	before []rowSnapshot) error {
+	for _, s := range before {
+		if err := record(ctx, tx, entity, s.id, action, s.row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetHistory returns the changes of a client or shoot, oldest first. The
+// history of a client includes its shoots and that of a shoot its payments.
+func (repo *postgresAudit) GetHistory(ctx context.Context, entity model.AuditEntity,
+	id int) ([]model.AuditEntry, error) {
+	condition := "(entity = $1 AND entity_id = $2)"
+	switch entity {
+	case model.AuditClient:
+		condition += ` OR (entity = 'shoot'
+	AND $2 IN ((before->>'client_id')::int, (after->>'client_id')::int))`
+	case model.AuditShoot:
+		condition += ` OR (entity = 'payment'
+	AND $2 IN ((before->>'shoot_id')::int, (after->>'shoot_id')::int))`
+	}
+
+	query := `
+SELECT id, entity, entity_id, action, before, after, actor, created_at
+FROM audit_log
+WHERE ` + condition + `
+ORDER BY id`
+
+	rows, err := repo.db.Conn(ctx).Query(ctx, query, entity, id)
+	if err != nil {
+		return nil, myerrors.Wrap(err, myerrors.ErrCodeDBSelect, "failed to get audit log")
+	}
+
+	defer rows.Close()
+
+	entries := make([]model.AuditEntry, 0)
+
+	for rows.Next() {
+		var entry model.AuditEntry
+		err := rows.Scan(&entry.Id, &entry.Entity, &entry.EntityId, &entry.Action,
+			&entry.Before, &entry.After, &entry.Actor, &entry.CreatedAt)
+		if err != nil {
+			return nil, myerrors.Wrap(err, myerrors.ErrCodeDBSelect, "failed to get audit entry")
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, myerrors.Wrap(err, myerrors.ErrCodeDBSelect, "error during rows iteration")
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"log"
+	"testing"
+
+	"github.com/Coiiap5e/photographer/internal/audit"
+	"github.com/Coiiap5e/photographer/internal/model"
+	"github.com/Coiiap5e/photographer/testutils"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestAuditRepositoryTestSuit(t *testing.T) {
+	suite.Run(t, new(AuditRepositoryTestSuit))
+}
+
+type AuditRepositoryTestSuit struct {
+	suite.Suite
+	ctx        context.Context
+	db         *testutils.TestDB
+	repo       Audit
+	clientRepo Client
+	shootRepo  Shoot
+}
+
+func (suite *AuditRepositoryTestSuit) SetupSuite() {
+	suite.ctx = audit.WithActor(context.Background(), "assistant")
+
+	var err error
+
+	suite.db, err = testutils.CreateTestDB(suite.ctx)
+	suite.Require().NoError(err, "Failed to setup test database")
+	suite.Require().NotNil(suite.db, "TestDB should not be nil")
+
+	suite.repo = NewAudit(suite.db.GetDB())
+	suite.clientRepo = NewClient(suite.db.GetDB())
+	suite.shootRepo = NewShoot(suite.db.GetDB())
+}
+
+func (suite *AuditRepositoryTestSuit) SetupTest() {
+	err := suite.db.CleanTables(suite.ctx)
+	suite.Require().NoError(err)
+}
+
+func (suite *AuditRepositoryTestSuit) TearDownSuite() {
+	if suite.db != nil {
+		if err := suite.db.Cleanup(suite.ctx); err != nil {
+			log.Fatalf("failed to cleanup test database: %v", err)
+		}
+	}
+}
+
+func (suite *AuditRepositoryTestSuit) TestGetHistory() {
+	suite.T().Run("should record every change of a client", func(t *testing.T) {
+		// Given
+		client := testutils.CreateTestClient()
+		suite.Require().NoError(suite.clientRepo.AddClient(suite.ctx, client))
+
+		phone := "+7(901)111-11-11"
+		_, err := suite.clientRepo.UpdateClient(suite.ctx, client.Id, client.Version,
+			&model.ClientUpdate{Phone: &phone})
+		suite.Require().NoError(err, "precondition: client should be updated")
+
+		// When
+		entries, err := suite.repo.GetHistory(suite.ctx, model.AuditClient, client.Id)
+
+		// Then
+		suite.NoError(err, "should not return error")
+		suite.Require().Len(entries, 2, "should record create and update")
+		suite.Equal(model.AuditCreate, entries[0].Action, "should record creation first")
+		suite.Nil(entries[0].Before, "should have no row before creation")
+		suite.Equal(model.AuditUpdate, entries[1].Action, "should record update")
+		suite.Equal("assistant", entries[1].Actor, "should record actor from context")
+
+		changes, err := audit.Changes(entries[1])
+		suite.NoError(err)
+		suite.Equal([]audit.Change{{Field: "phone", Before: client.Phone, After: phone}}, changes,
+			"should store rows before and after the change")
+	})
+	suite.T().Run("should include shoots in client history", func(t *testing.T) {
+		// Given
+		client := testutils.CreateTestClient()
+		suite.Require().NoError(suite.clientRepo.AddClient(suite.ctx, client))
+		shoot := testutils.CreateTestShoot(client.Id)
+		suite.Require().NoError(suite.shootRepo.AddShoot(suite.ctx, shoot))
+
+		// When
+		err := suite.clientRepo.DeleteClient(suite.ctx, client.Id)
+		suite.Require().NoError(err, "precondition: client should be deleted")
+		entries, err := suite.repo.GetHistory(suite.ctx, model.AuditClient, client.Id)
+
+		// Then
+		suite.NoError(err, "should not return error")
+		var actions []string
+		for _, entry := range entries {
+			actions = append(actions, string(entry.Entity)+" "+string(entry.Action))
+		}
+		suite.Equal([]string{"client create", "shoot create", "client delete", "shoot delete"}, actions,
+			"should record deletion of the client and its shoots")
+	})
+	suite.T().Run("should not record failed changes", func(t *testing.T) {
+		// Given
+		client := testutils.CreateTestClient()
+		suite.Require().NoError(suite.clientRepo.AddClient(suite.ctx, client))
+		phone := "+7(902)222-22-22"
+
+		// When
+		_, err := suite.clientRepo.UpdateClient(suite.ctx, client.Id, client.Version+1,
+			&model.ClientUpdate{Phone: &phone})
+		suite.Require().Error(err, "precondition: update with stale version should fail")
+		entries, err := suite.repo.GetHistory(suite.ctx, model.AuditClient, client.Id)
+
+		// Then
+		suite.NoError(err, "should not return error")
+		suite.Len(entries, 1, "should keep only the creation")
+	})
+}
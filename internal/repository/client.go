@@ -3,6 +3,9 @@ package repository
 import (
 	"context"
 	"errors"
+	"strings"
+	"time"
+	"unicode"
 
 	"github.com/Coiiap5e/photographer/internal/database"
 	myerrors "github.com/Coiiap5e/photographer/internal/errors"
@@ -12,9 +15,15 @@ import (
 
 type Client interface {
 	AddClient(ctx context.Context, client *model.Client) error
+	AddClients(ctx context.Context, clients []*model.Client) error
 	DeleteClient(ctx context.Context, id int) error
 	GetClientByID(ctx context.Context, id int) (*model.Client, error)
-	GetClients(ctx context.Context) ([]model.Client, error)
+	GetClients(ctx context.Context, filter model.ClientFilter) ([]model.Client, error)
+	SearchClients(ctx context.Context, query string, limit int) ([]model.Client, error)
+	UpdateClient(ctx context.Context, id, version int, update *model.ClientUpdate) (*model.Client, error)
+	GetDeletedClients(ctx context.Context) ([]model.Client, error)
+	RestoreClient(ctx context.Context, id int) error
+	PurgeClients(ctx context.Context, olderThan time.Duration) (int, error)
 }
 
 type postgresClient struct {
@@ -25,52 +34,108 @@ func NewClient(db *database.DB) Client {
 	return &postgresClient{db: db}
 }
 
-func (repo *postgresClient) AddClient(ctx context.Context, client *model.Client) error {
-	query := `
-INSERT INTO clients 
-    (first_name, last_name, phone, social_network_url) 
-VALUES 
-    ($1, $2, $3, $4) 
-RETURNING 
-    id, created_at`
-
-	err := repo.db.Pool.QueryRow(ctx, query,
+// clientColumns selects a client in the order scanClient reads them
+const clientColumns = `id, first_name, last_name, phone, social_network_url,
+	created_at, updated_at, version, deleted_at`
+
+func scanClient(row pgx.Row, client *model.Client) error {
+	return row.Scan(
+		&client.Id, &client.FirstName, &client.LastName,
+		&client.Phone, &client.SocialNetworkUrl,
+		&client.CreatedAt, &client.UpdatedAt, &client.Version, &client.DeletedAt)
+}
+
+// insertClientQuery adds a client and returns its generated fields
+const insertClientQuery = `
+INSERT INTO clients
+    (first_name, last_name, phone, social_network_url)
+VALUES
+    ($1, $2, $3, $4)
+RETURNING
+    id, created_at, updated_at, version`
+
+func insertClient(ctx context.Context, tx database.Querier, client *model.Client) error {
+	err := tx.QueryRow(ctx, insertClientQuery,
 		client.FirstName, client.LastName, client.Phone, client.SocialNetworkUrl).
-		Scan(&client.Id, &client.CreatedAt)
+		Scan(&client.Id, &client.CreatedAt, &client.UpdatedAt, &client.Version)
 
 	if err != nil {
 		return myerrors.Wrap(err, myerrors.ErrCodeClientCreate, "failed to create client")
 	}
 
-	return nil
+	return record(ctx, tx, model.AuditClient, client.Id, model.AuditCreate, nil)
+}
+
+func (repo *postgresClient) AddClient(ctx context.Context, client *model.Client) error {
+	return repo.db.WithTx(ctx, func(ctx context.Context) error {
+		return insertClient(ctx, repo.db.Conn(ctx), client)
+	})
 }
 
+// AddClients inserts all clients in one transaction, either every client is
+// added or none is
+func (repo *postgresClient) AddClients(ctx context.Context, clients []*model.Client) error {
+	return repo.db.WithTx(ctx, func(ctx context.Context) error {
+		tx := repo.db.Conn(ctx)
+		for _, client := range clients {
+			if err := insertClient(ctx, tx, client); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteClient moves the client and its shoots to the trash. They get the
+// same deletion time, so RestoreClient brings back only the shoots deleted
+// with the client.
 func (repo *postgresClient) DeleteClient(ctx context.Context, id int) error {
-	query := `
-DELETE FROM clients WHERE id = $1`
+	return repo.db.WithTx(ctx, func(ctx context.Context) error {
+		tx := repo.db.Conn(ctx)
+		client, err := snapshot(ctx, tx, model.AuditClient, "t.id = $1 AND t.deleted_at IS NULL", id)
+		if err != nil {
+			return err
+		}
 
-	result, err := repo.db.Pool.Exec(ctx, query, id)
-	if err != nil {
-		return myerrors.Wrap(err, myerrors.ErrCodeClientDelete, "failed to delete client")
-	}
+		if len(client) == 0 {
+			return myerrors.New(myerrors.ErrCodeClientNotFound, "client not found")
+		}
 
-	if result.RowsAffected() == 0 {
-		return myerrors.New(myerrors.ErrCodeClientNotFound, "client not found")
-	}
+		shoots, err := snapshot(ctx, tx, model.AuditShoot, "t.client_id = $1 AND t.deleted_at IS NULL", id)
+		if err != nil {
+			return err
+		}
+
+		// CURRENT_TIMESTAMP is the start of the transaction in both statements
+		_, err = tx.Exec(ctx, `
+UPDATE clients SET deleted_at = CURRENT_TIMESTAMP
+WHERE id = $1`, id)
+		if err != nil {
+			return myerrors.Wrap(err, myerrors.ErrCodeClientDelete, "failed to delete client")
+		}
+
+		_, err = tx.Exec(ctx, `
+UPDATE shoots SET deleted_at = CURRENT_TIMESTAMP
+WHERE client_id = $1 AND deleted_at IS NULL`, id)
+		if err != nil {
+			return myerrors.Wrap(err, myerrors.ErrCodeClientDelete, "failed to delete client shoots")
+		}
 
-	return nil
+		if err := recordAll(ctx, tx, model.AuditClient, model.AuditDelete, client); err != nil {
+			return err
+		}
+		return recordAll(ctx, tx, model.AuditShoot, model.AuditDelete, shoots)
+	})
 }
 
 func (repo *postgresClient) GetClientByID(ctx context.Context, id int) (*model.Client, error) {
 	query := `
-SELECT id, first_name, last_name, phone, social_network_url
+SELECT ` + clientColumns + `
 FROM clients
-WHERE id = $1`
+WHERE id = $1 AND deleted_at IS NULL`
 
 	var client model.Client
-	err := repo.db.Pool.QueryRow(ctx, query, id).Scan(
-		&client.Id, &client.FirstName, &client.LastName,
-		&client.Phone, &client.SocialNetworkUrl)
+	err := scanClient(repo.db.Conn(ctx).QueryRow(ctx, query, id), &client)
 
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -82,12 +147,19 @@ WHERE id = $1`
 	return &client, nil
 }
 
-func (repo *postgresClient) GetClients(ctx context.Context) ([]model.Client, error) {
+// GetClients returns clients in filter's sort order, at most filter.Limit
+// of them if it is set
+func (repo *postgresClient) GetClients(ctx context.Context, filter model.ClientFilter) ([]model.Client, error) {
+	var b queryBuilder
+	b.where("deleted_at IS NULL")
+
+	page := b.page(clientSortKeys(filter.Sort, filter.After), filter.After != nil, filter.Desc, filter.Limit)
+
 	query := `
-SELECT id, first_name, last_name, phone, social_network_url, created_at
-FROM clients`
+SELECT ` + clientColumns + `
+FROM clients` + b.whereClause() + page
 
-	rows, err := repo.db.Pool.Query(ctx, query)
+	rows, err := repo.db.Conn(ctx).Query(ctx, query, b.args...)
 	if err != nil {
 		return nil, myerrors.Wrap(err, myerrors.ErrCodeDBSelect, "failed to get clients")
 	}
@@ -98,10 +170,165 @@ FROM clients`
 
 	for rows.Next() {
 		var client model.Client
-		err := rows.Scan(
-			&client.Id, &client.FirstName, &client.LastName,
-			&client.Phone, &client.SocialNetworkUrl, &client.CreatedAt)
+		if err := scanClient(rows, &client); err != nil {
+			return nil, myerrors.Wrap(err, myerrors.ErrCodeDBSelect, "failed to get client")
+		}
+		clients = append(clients, client)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, myerrors.Wrap(err, myerrors.ErrCodeDBSelect, "error during rows iteration")
+	}
+
+	return clients, nil
+}
+
+// Search expressions, the same as in the clients_search_*_idx indexes
+const (
+	clientSearchText = `(first_name || ' ' || last_name || ' ' || COALESCE(social_network_url, ''))`
+	// clientSearchPhone normalizes the stored phone like phoneDigits
+	clientSearchPhone = `regexp_replace(regexp_replace(COALESCE(phone, ''), '[^0-9]', '', 'g'), '^8([0-9]{10})$', '7\1')`
+)
+
+// SearchClients returns up to limit clients matching every word of query.
+// A word matches a substring of the name or social URL, and words with at
+// least three digits also match the phone by digits only. A query without
+// letters is one phone number, so "916 123" finds +7(916)123-45-67.
+// Closest names come first.
+func (repo *postgresClient) SearchClients(ctx context.Context, query string, limit int) ([]model.Client, error) {
+	var b queryBuilder
+	b.where("deleted_at IS NULL")
+
+	words := strings.Fields(query)
+	if strings.IndexFunc(query, unicode.IsLetter) < 0 {
+		// a query without letters is a phone number, possibly split by spaces
+		words = []string{phoneDigits(query)}
+	}
+
+	for _, word := range words {
+		condition := clientSearchText + " ILIKE '%' || " + b.arg(escapeLike(word)) + " || '%'"
+		if number := phoneDigits(word); len(number) >= 3 {
+			condition += " OR " + clientSearchPhone + " LIKE '%' || " + b.arg(number) + " || '%'"
+		}
+		b.where("(" + condition + ")")
+	}
+
+	sql := `
+SELECT ` + clientColumns + `
+FROM clients` + b.whereClause() + `
+ORDER BY similarity(first_name || ' ' || last_name, ` + b.arg(query) + `) DESC,
+	last_name, first_name, id`
+	if limit > 0 {
+		sql += "\nLIMIT " + b.arg(limit)
+	}
+
+	rows, err := repo.db.Conn(ctx).Query(ctx, sql, b.args...)
+	if err != nil {
+		return nil, myerrors.Wrap(err, myerrors.ErrCodeDBSelect, "failed to search clients")
+	}
+
+	defer rows.Close()
+
+	clients := make([]model.Client, 0)
+
+	for rows.Next() {
+		var client model.Client
+		if err := scanClient(rows, &client); err != nil {
+			return nil, myerrors.Wrap(err, myerrors.ErrCodeDBSelect, "failed to get client")
+		}
+		clients = append(clients, client)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, myerrors.Wrap(err, myerrors.ErrCodeDBSelect, "error during rows iteration")
+	}
+
+	return clients, nil
+}
+
+// phoneDigits returns the digits of word, with a leading 8 of a full
+// Russian number turned into 7. Stored phones are compared the same way,
+// see clientSearchPhone.
+func phoneDigits(word string) string {
+	number := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, word)
+	if len(number) == 11 && number[0] == '8' {
+		number = "7" + number[1:]
+	}
+	return number
+}
+
+// UpdateClient changes the non-nil fields of update if the stored version
+// still equals version.
+func (repo *postgresClient) UpdateClient(ctx context.Context, id, version int,
+	update *model.ClientUpdate) (*model.Client, error) {
+	query := `
+UPDATE clients SET
+	first_name = COALESCE($3, first_name),
+	last_name = COALESCE($4, last_name),
+	phone = COALESCE($5, phone),
+	social_network_url = COALESCE($6, social_network_url),
+	updated_at = CURRENT_TIMESTAMP,
+	version = version + 1
+WHERE id = $1 AND version = $2
+RETURNING ` + clientColumns
+
+	var client model.Client
+	err := repo.db.WithTx(ctx, func(ctx context.Context) error {
+		tx := repo.db.Conn(ctx)
+		before, err := snapshot(ctx, tx, model.AuditClient, "t.id = $1 AND t.deleted_at IS NULL", id)
 		if err != nil {
+			return err
+		}
+
+		if len(before) == 0 {
+			return myerrors.New(myerrors.ErrCodeClientNotFound, "client not found")
+		}
+
+		err = scanClient(tx.QueryRow(ctx, query, id, version, update.FirstName, update.LastName,
+			update.Phone, update.SocialNetworkUrl), &client)
+
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return myerrors.New(myerrors.ErrCodeVersionConflict, "client was changed by someone else")
+			}
+			return myerrors.Wrap(err, myerrors.ErrCodeClientUpdate, "failed to update client")
+		}
+
+		return record(ctx, tx, model.AuditClient, id, model.AuditUpdate, before[0].row)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &client, nil
+}
+
+// GetDeletedClients returns the clients in the trash, most recently deleted
+// first
+func (repo *postgresClient) GetDeletedClients(ctx context.Context) ([]model.Client, error) {
+	query := `
+SELECT ` + clientColumns + `
+FROM clients
+WHERE deleted_at IS NOT NULL
+ORDER BY deleted_at DESC, id DESC`
+
+	rows, err := repo.db.Conn(ctx).Query(ctx, query)
+	if err != nil {
+		return nil, myerrors.Wrap(err, myerrors.ErrCodeDBSelect, "failed to get deleted clients")
+	}
+
+	defer rows.Close()
+
+	clients := make([]model.Client, 0)
+
+	for rows.Next() {
+		var client model.Client
+		if err := scanClient(rows, &client); err != nil {
 			return nil, myerrors.Wrap(err, myerrors.ErrCodeDBSelect, "failed to get client")
 		}
 		clients = append(clients, client)
@@ -113,3 +340,99 @@ FROM clients`
 
 	return clients, nil
 }
+
+// RestoreClient takes the client out of the trash together with the shoots
+// that were deleted with it. Shoots deleted on their own stay in the trash.
+func (repo *postgresClient) RestoreClient(ctx context.Context, id int) error {
+	return repo.db.WithTx(ctx, func(ctx context.Context) error {
+		tx := repo.db.Conn(ctx)
+		client, err := snapshot(ctx, tx, model.AuditClient, "t.id = $1 AND t.deleted_at IS NOT NULL", id)
+		if err != nil {
+			return err
+		}
+
+		if len(client) == 0 {
+			return myerrors.New(myerrors.ErrCodeClientNotFound, "client not found in trash")
+		}
+
+		shoots, err := snapshot(ctx, tx, model.AuditShoot,
+			"t.client_id = $1 AND t.deleted_at = (SELECT deleted_at FROM clients WHERE id = $1)", id)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx, `
+UPDATE shoots SET deleted_at = NULL
+WHERE client_id = $1
+  AND deleted_at = (SELECT deleted_at FROM clients WHERE id = $1)`, id)
+		if err != nil {
+			if isExclusionViolation(err) {
+				return myerrors.Wrap(err, myerrors.ErrCodeShootConflict,
+					"client's shoots overlap shoots booked since deletion")
+			}
+			return myerrors.Wrap(err, myerrors.ErrCodeClientUpdate, "failed to restore client shoots")
+		}
+
+		_, err = tx.Exec(ctx, `
+UPDATE clients SET deleted_at = NULL
+WHERE id = $1`, id)
+		if err != nil {
+			return myerrors.Wrap(err, myerrors.ErrCodeClientUpdate, "failed to restore client")
+		}
+
+		if err := recordAll(ctx, tx, model.AuditClient, model.AuditRestore, client); err != nil {
+			return err
+		}
+		return recordAll(ctx, tx, model.AuditShoot, model.AuditRestore, shoots)
+	})
+}
+
+// PurgeClients permanently removes clients deleted more than olderThan ago
+// with all their shoots and payments. It returns how many were removed.
+func (repo *postgresClient) PurgeClients(ctx context.Context, olderThan time.Duration) (int, error) {
+	var purged int
+	err := repo.db.WithTx(ctx, func(ctx context.Context) error {
+		tx := repo.db.Conn(ctx)
+		clients, err := snapshot(ctx, tx, model.AuditClient,
+			"t.deleted_at < LOCALTIMESTAMP - $1::interval", olderThan)
+		if err != nil {
+			return err
+		}
+
+		ids := make([]int, len(clients))
+		for i, client := range clients {
+			ids[i] = client.id
+		}
+
+		// the shoots and payments go with the clients by ON DELETE CASCADE
+		shoots, err := snapshot(ctx, tx, model.AuditShoot, "t.client_id = ANY($1)", ids)
+		if err != nil {
+			return err
+		}
+		payments, err := snapshot(ctx, tx, model.AuditPayment,
+			"t.shoot_id IN (SELECT id FROM shoots WHERE client_id = ANY($1))", ids)
+		if err != nil {
+			return err
+		}
+
+		result, err := tx.Exec(ctx, `
+DELETE FROM clients WHERE id = ANY($1)`, ids)
+		if err != nil {
+			return myerrors.Wrap(err, myerrors.ErrCodeClientDelete, "failed to purge clients")
+		}
+		purged = int(result.RowsAffected())
+
+		if err := recordAll(ctx, tx, model.AuditPayment, model.AuditPurge, payments); err != nil {
+			return err
+		}
+		if err := recordAll(ctx, tx, model.AuditShoot, model.AuditPurge, shoots); err != nil {
+			return err
+		}
+		return recordAll(ctx, tx, model.AuditClient, model.AuditPurge, clients)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return purged, nil
+}
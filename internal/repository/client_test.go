@@ -2,9 +2,12 @@ package repository
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"strings"
 	"testing"
 
+	myerrors "github.com/Coiiap5e/photographer/internal/errors"
 	"github.com/Coiiap5e/photographer/internal/model"
 	"github.com/Coiiap5e/photographer/testutils"
 	"github.com/stretchr/testify/suite"
@@ -60,6 +63,49 @@ func (suite *ClientRepositoryTestSuit) TestAddClient() {
 	suite.NotZero(testClient.CreatedAt, "should set creation timestamp in client object")
 }
 
+func (suite *ClientRepositoryTestSuit) TestAddClients() {
+	suite.T().Run("should add all clients", func(t *testing.T) {
+		// Given
+		clients := []*model.Client{
+			testutils.CreateTestClient(),
+			testutils.CreateTestClientWithOptions(func(client *model.Client) {
+				client.FirstName = "Anna"
+				client.Phone = "+7(901)111-11-11"
+			}),
+		}
+
+		// When
+		err := suite.repo.AddClients(suite.ctx, clients)
+
+		// Then
+		suite.NoError(err, "should not return error")
+		suite.Greater(clients[0].Id, 0, "should assign ID to the first client")
+		suite.Greater(clients[1].Id, clients[0].Id, "should assign ID to the second client")
+	})
+
+	suite.T().Run("should add nothing if one client fails", func(t *testing.T) {
+		// Given
+		err := suite.db.CleanTables(suite.ctx)
+		suite.Require().NoError(err)
+
+		clients := []*model.Client{
+			testutils.CreateTestClient(),
+			testutils.CreateTestClientWithOptions(func(client *model.Client) {
+				client.FirstName = strings.Repeat("a", 101)
+			}),
+		}
+
+		// When
+		err = suite.repo.AddClients(suite.ctx, clients)
+
+		// Then
+		suite.Error(err, "should return error")
+		allClients, err := suite.repo.GetClients(suite.ctx, model.ClientFilter{})
+		suite.NoError(err, "should not return error")
+		suite.Empty(allClients, "should roll back the first client")
+	})
+}
+
 func (suite *ClientRepositoryTestSuit) TestGetClientByID() {
 	suite.T().Run("should successfully get a client by ID", func(t *testing.T) {
 		// Given
@@ -146,7 +192,7 @@ func (suite *ClientRepositoryTestSuit) TestGetClients() {
 	suite.NoError(err, "should not return error")
 
 	// When
-	allClients, err := suite.repo.GetClients(suite.ctx)
+	allClients, err := suite.repo.GetClients(suite.ctx, model.ClientFilter{})
 
 	// Then
 	suite.NoError(err, "should not return error")
@@ -180,3 +226,254 @@ func (suite *ClientRepositoryTestSuit) TestGetClients() {
 	suite.Equal(testClient3.SocialNetworkUrl, client3.SocialNetworkUrl, "client3 social network url should match")
 
 }
+
+func (suite *ClientRepositoryTestSuit) TestGetClientsPaged() {
+	// Given
+	names := []string{"Sidorov", "Ivanov", "Petrov"}
+	for i, name := range names {
+		client := testutils.CreateTestClientWithOptions(func(client *model.Client) {
+			client.LastName = name
+			client.Phone = fmt.Sprintf("+7(900)000-00-0%d", i)
+		})
+		err := suite.repo.AddClient(suite.ctx, client)
+		suite.Require().NoError(err, "precondition: client should be created")
+	}
+
+	lastNames := func(clients []model.Client) []string {
+		result := make([]string, len(clients))
+		for i, client := range clients {
+			result[i] = client.LastName
+		}
+		return result
+	}
+
+	suite.T().Run("should order by name in pages", func(t *testing.T) {
+		// Given
+		filter := model.ClientFilter{Limit: 2}
+
+		// When
+		first, err := suite.repo.GetClients(suite.ctx, filter)
+		suite.Require().NoError(err, "should not return error")
+		filter.After = &first[len(first)-1]
+		second, err := suite.repo.GetClients(suite.ctx, filter)
+
+		// Then
+		suite.NoError(err, "should not return error")
+		suite.Equal([]string{"Ivanov", "Petrov"}, lastNames(first), "should return first page")
+		suite.Equal([]string{"Sidorov"}, lastNames(second), "should continue after the last client")
+	})
+
+	suite.T().Run("should order by creation time descending", func(t *testing.T) {
+		// When
+		clients, err := suite.repo.GetClients(suite.ctx, model.ClientFilter{Sort: model.ClientSortCreated, Desc: true})
+
+		// Then
+		suite.NoError(err, "should not return error")
+		suite.Equal([]string{"Petrov", "Ivanov", "Sidorov"}, lastNames(clients), "should return newest first")
+	})
+}
+
+func (suite *ClientRepositoryTestSuit) TestSearchClients() {
+	// Given
+	ivanov := testutils.CreateTestClientWithOptions(func(client *model.Client) {
+		client.Phone = "+7(916)123-45-67"
+	})
+	petrova := testutils.CreateTestClientWithOptions(func(client *model.Client) {
+		client.FirstName = "Anna"
+		client.LastName = "Petrova"
+		client.Phone = "+7(901)111-11-11"
+		client.SocialNetworkUrl = "vk.com/a_petrova"
+	})
+	ivanova := testutils.CreateTestClientWithOptions(func(client *model.Client) {
+		client.FirstName = "Maria"
+		client.LastName = "Ivanova"
+		client.Phone = "+7(902)222-22-22"
+	})
+	for _, client := range []*model.Client{ivanov, petrova, ivanova} {
+		err := suite.repo.AddClient(suite.ctx, client)
+		suite.Require().NoError(err, "precondition: client should be created")
+	}
+
+	ids := func(clients []model.Client) []int {
+		result := make([]int, len(clients))
+		for i, client := range clients {
+			result[i] = client.Id
+		}
+		return result
+	}
+
+	suite.T().Run("should find clients by part of the name ignoring case", func(t *testing.T) {
+		// When
+		clients, err := suite.repo.SearchClients(suite.ctx, "IVANOV", 0)
+
+		// Then
+		suite.NoError(err, "should not return error")
+		suite.ElementsMatch([]int{ivanov.Id, ivanova.Id}, ids(clients), "should match both Ivanovs")
+		suite.Equal(ivanov.Id, clients[0].Id, "should put the closest name first")
+	})
+
+	suite.T().Run("should require every word to match", func(t *testing.T) {
+		// When
+		clients, err := suite.repo.SearchClients(suite.ctx, "maria ivanov", 0)
+
+		// Then
+		suite.NoError(err, "should not return error")
+		suite.Equal([]int{ivanova.Id}, ids(clients), "should match first and last name")
+	})
+
+	suite.T().Run("should find clients by phone digits", func(t *testing.T) {
+		for _, query := range []string{"916 123", "89161234567", "+7 916 123-45-67"} {
+			// When
+			clients, err := suite.repo.SearchClients(suite.ctx, query, 0)
+
+			// Then
+			suite.NoError(err, "should not return error")
+			suite.Equal([]int{ivanov.Id}, ids(clients), "should match phone for %q", query)
+		}
+	})
+
+	suite.T().Run("should find clients by social URL", func(t *testing.T) {
+		// When
+		clients, err := suite.repo.SearchClients(suite.ctx, "a_petrova", 0)
+
+		// Then
+		suite.NoError(err, "should not return error")
+		suite.Equal([]int{petrova.Id}, ids(clients), "should match social URL")
+	})
+
+	suite.T().Run("should limit results", func(t *testing.T) {
+		// When
+		clients, err := suite.repo.SearchClients(suite.ctx, "ivan", 1)
+
+		// Then
+		suite.NoError(err, "should not return error")
+		suite.Len(clients, 1, "should return one client")
+	})
+}
+
+func (suite *ClientRepositoryTestSuit) TestUpdateClient() {
+	suite.T().Run("should update only given fields", func(t *testing.T) {
+		// Given
+		testClient := testutils.CreateTestClient()
+		err := suite.repo.AddClient(suite.ctx, testClient)
+		suite.Require().NoError(err, "precondition: client should be created")
+
+		newPhone := "+7(902)222-22-22"
+
+		// When
+		updatedClient, err := suite.repo.UpdateClient(suite.ctx, testClient.Id, testClient.Version,
+			&model.ClientUpdate{Phone: &newPhone})
+
+		// Then
+		suite.NoError(err, "should not return error")
+		suite.Equal(newPhone, updatedClient.Phone, "should update phone")
+		suite.Equal(testClient.FirstName, updatedClient.FirstName, "should keep first name")
+		suite.Equal(testClient.Version+1, updatedClient.Version, "should increment version")
+	})
+	suite.T().Run("should return conflict error for stale version", func(t *testing.T) {
+		// Given
+		testClient := testutils.CreateTestClient()
+		err := suite.repo.AddClient(suite.ctx, testClient)
+		suite.Require().NoError(err, "precondition: client should be created")
+
+		firstName := "Petr"
+		_, err = suite.repo.UpdateClient(suite.ctx, testClient.Id, testClient.Version,
+			&model.ClientUpdate{FirstName: &firstName})
+		suite.Require().NoError(err, "precondition: client should be updated")
+
+		// When
+		_, err = suite.repo.UpdateClient(suite.ctx, testClient.Id, testClient.Version,
+			&model.ClientUpdate{FirstName: &firstName})
+
+		// Then
+		suite.True(myerrors.IsErrorCode(err, myerrors.ErrCodeVersionConflict),
+			"should return version conflict error")
+	})
+	suite.T().Run("should return error when updating non-existent client", func(t *testing.T) {
+		// Given
+		nonExistentID := 9999
+		firstName := "Petr"
+
+		// When
+		_, err := suite.repo.UpdateClient(suite.ctx, nonExistentID, 1,
+			&model.ClientUpdate{FirstName: &firstName})
+
+		// Then
+		suite.True(myerrors.IsErrorCode(err, myerrors.ErrCodeClientNotFound),
+			"should return not found error")
+	})
+}
+
+func (suite *ClientRepositoryTestSuit) TestRestoreClient() {
+	suite.T().Run("should restore client with shoots deleted together", func(t *testing.T) {
+		// Given
+		testClient := testutils.CreateTestClient()
+		err := suite.repo.AddClient(suite.ctx, testClient)
+		suite.Require().NoError(err, "precondition: client should be created")
+
+		shootRepo := NewShoot(suite.db.GetDB())
+		deletedEarlier := testutils.CreateTestShoot(testClient.Id)
+		err = shootRepo.AddShoot(suite.ctx, deletedEarlier)
+		suite.Require().NoError(err, "precondition: shoot should be created")
+		err = shootRepo.DeleteShoot(suite.ctx, deletedEarlier.Id)
+		suite.Require().NoError(err, "precondition: shoot should be deleted")
+
+		testShoot := testutils.CreateTestShootWithOptions(testClient.Id, func(shoot *model.Shoot) {
+			shoot.ShootDate = deletedEarlier.ShootDate.AddDate(0, 0, 1)
+		})
+		err = shootRepo.AddShoot(suite.ctx, testShoot)
+		suite.Require().NoError(err, "precondition: shoot should be created")
+
+		err = suite.repo.DeleteClient(suite.ctx, testClient.Id)
+		suite.Require().NoError(err, "precondition: client should be deleted")
+
+		_, err = shootRepo.GetShootByID(suite.ctx, testShoot.Id)
+		suite.True(myerrors.IsErrorCode(err, myerrors.ErrCodeShootNotFound), "should hide shoots of deleted client")
+		deleted, err := suite.repo.GetDeletedClients(suite.ctx)
+		suite.Require().NoError(err)
+		suite.Require().Len(deleted, 1, "should list deleted client")
+
+		// When
+		err = suite.repo.RestoreClient(suite.ctx, testClient.Id)
+
+		// Then
+		suite.NoError(err, "should not return error")
+		_, err = suite.repo.GetClientByID(suite.ctx, testClient.Id)
+		suite.NoError(err, "should find restored client")
+		_, err = shootRepo.GetShootByID(suite.ctx, testShoot.Id)
+		suite.NoError(err, "should restore shoot deleted with the client")
+		_, err = shootRepo.GetShootByID(suite.ctx, deletedEarlier.Id)
+		suite.True(myerrors.IsErrorCode(err, myerrors.ErrCodeShootNotFound),
+			"should keep shoot deleted before the client in trash")
+	})
+	suite.T().Run("should return error for client not in trash", func(t *testing.T) {
+		// Given
+		testClient := testutils.CreateTestClient()
+		err := suite.repo.AddClient(suite.ctx, testClient)
+		suite.Require().NoError(err, "precondition: client should be created")
+
+		// When
+		err = suite.repo.RestoreClient(suite.ctx, testClient.Id)
+
+		// Then
+		suite.True(myerrors.IsErrorCode(err, myerrors.ErrCodeClientNotFound), "should return not found error")
+	})
+}
+
+func (suite *ClientRepositoryTestSuit) TestPurgeClients() {
+	// Given
+	testClient := testutils.CreateTestClient()
+	err := suite.repo.AddClient(suite.ctx, testClient)
+	suite.Require().NoError(err, "precondition: client should be created")
+	err = suite.repo.DeleteClient(suite.ctx, testClient.Id)
+	suite.Require().NoError(err, "precondition: client should be deleted")
+
+	// When
+	purged, err := suite.repo.PurgeClients(suite.ctx, 0)
+
+	// Then
+	suite.NoError(err, "should not return error")
+	suite.Equal(1, purged, "should remove deleted client")
+	suite.True(myerrors.IsErrorCode(suite.repo.RestoreClient(suite.ctx, testClient.Id),
+		myerrors.ErrCodeClientNotFound), "should not restore purged client")
+}
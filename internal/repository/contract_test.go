@@ -0,0 +1,401 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Coiiap5e/photographer/internal/database"
+	myerrors "github.com/Coiiap5e/photographer/internal/errors"
+	"github.com/Coiiap5e/photographer/internal/migrate"
+	"github.com/Coiiap5e/photographer/internal/model"
+	"github.com/Coiiap5e/photographer/testutils"
+	"github.com/stretchr/testify/suite"
+)
+
+// ContractTestSuit checks the behaviour every client and shoot repository
+// shares, whatever stores the data
+type ContractTestSuit struct {
+	suite.Suite
+	ctx context.Context
+	// open returns empty repositories for a test, close releases what open
+	// set up
+	open        func(ctx context.Context) (contractRepos, error)
+	close       func(ctx context.Context) error
+	clientRepo  Client
+	shootRepo   Shoot
+	paymentRepo Payment
+	auditRepo   Audit
+}
+
+// contractRepos are the repositories of one store
+type contractRepos struct {
+	client  Client
+	shoot   Shoot
+	payment Payment
+	audit   Audit
+}
+
+func TestMemoryContractTestSuit(t *testing.T) {
+	suite.Run(t, &ContractTestSuit{
+		open: func(ctx context.Context) (contractRepos, error) {
+			store := NewMemoryStore()
+			return contractRepos{NewMemoryClient(store), NewMemoryShoot(store),
+				NewMemoryPayment(store), NewMemoryAudit(store)}, nil
+		},
+	})
+}
+
+func TestPostgresContractTestSuit(t *testing.T) {
+	var db *testutils.TestDB
+	suite.Run(t, &ContractTestSuit{
+		open: func(ctx context.Context) (contractRepos, error) {
+			if db == nil {
+				var err error
+				if db, err = testutils.CreateTestDB(ctx); err != nil {
+					return contractRepos{}, err
+				}
+			}
+			if err := db.CleanTables(ctx); err != nil {
+				return contractRepos{}, err
+			}
+			return contractRepos{NewClient(db.GetDB()), NewShoot(db.GetDB()),
+				NewPayment(db.GetDB()), NewAudit(db.GetDB())}, nil
+		},
+		close: func(ctx context.Context) error {
+			if db == nil {
+				return nil
+			}
+			return db.Cleanup(ctx)
+		},
+	})
+}
+
+func TestSQLiteContractTestSuit(t *testing.T) {
+	dir := t.TempDir()
+	var db *database.SQLite
+	opened := 0
+	suite.Run(t, &ContractTestSuit{
+		open: func(ctx context.Context) (contractRepos, error) {
+			if db != nil {
+				db.Close()
+			}
+			opened++
+
+			var err error
+			db, err = database.NewSQLite(ctx, filepath.Join(dir, fmt.Sprintf("test%d.db", opened)))
+			if err != nil {
+				return contractRepos{}, err
+			}
+			migrator, err := migrate.NewSQLite(db)
+			if err != nil {
+				return contractRepos{}, err
+			}
+			if err := migrator.Up(ctx); err != nil {
+				return contractRepos{}, err
+			}
+			return contractRepos{NewSQLiteClient(db), NewSQLiteShoot(db),
+				NewSQLitePayment(db), NewSQLiteAudit(db)}, nil
+		},
+		close: func(ctx context.Context) error {
+			if db != nil {
+				db.Close()
+			}
+			return nil
+		},
+	})
+}
+
+func (suite *ContractTestSuit) SetupSuite() {
+	suite.ctx = context.Background()
+}
+
+func (suite *ContractTestSuit) SetupTest() {
+	repos, err := suite.open(suite.ctx)
+	suite.Require().NoError(err, "Failed to open repositories")
+	suite.clientRepo, suite.shootRepo = repos.client, repos.shoot
+	suite.paymentRepo, suite.auditRepo = repos.payment, repos.audit
+}
+
+func (suite *ContractTestSuit) TearDownSuite() {
+	if suite.close != nil {
+		suite.Require().NoError(suite.close(suite.ctx), "failed to close repositories")
+	}
+}
+
+func (suite *ContractTestSuit) addClient(lastName string) *model.Client {
+	client := testutils.CreateTestClientWithOptions(func(client *model.Client) {
+		client.LastName = lastName
+	})
+	suite.Require().NoError(suite.clientRepo.AddClient(suite.ctx, client), "precondition: client should be created")
+	return client
+}
+
+func (suite *ContractTestSuit) addShoot(clientID int, date time.Time) *model.Shoot {
+	shoot := testutils.CreateTestShootWithOptions(clientID, func(shoot *model.Shoot) {
+		shoot.ShootDate = date
+	})
+	suite.Require().NoError(suite.shootRepo.AddShoot(suite.ctx, shoot), "precondition: shoot should be created")
+	return shoot
+}
+
+func (suite *ContractTestSuit) TestGeneratedFields() {
+	// Given
+	first := testutils.CreateTestClient()
+	second := testutils.CreateTestClient()
+
+	// When
+	err := suite.clientRepo.AddClients(suite.ctx, []*model.Client{first, second})
+
+	// Then
+	suite.Require().NoError(err, "should not return error")
+	suite.Greater(first.Id, 0, "should assign positive ID")
+	suite.Greater(second.Id, first.Id, "should assign increasing IDs")
+	suite.NotZero(first.CreatedAt, "should set creation timestamp")
+	suite.Equal(1, first.Version, "should start at version 1")
+
+	shoot := suite.addShoot(first.Id, time.Now().AddDate(0, 0, 30))
+	suite.Greater(shoot.Id, 0, "should assign positive ID to shoot")
+	suite.NotZero(shoot.CreatedAt, "should set shoot creation timestamp")
+	suite.Equal(1, shoot.Version, "should start shoot at version 1")
+}
+
+func (suite *ContractTestSuit) TestNotFound() {
+	suite.T().Run("should return client not found", func(t *testing.T) {
+		// When
+		_, getErr := suite.clientRepo.GetClientByID(suite.ctx, 9999)
+		deleteErr := suite.clientRepo.DeleteClient(suite.ctx, 9999)
+		_, updateErr := suite.clientRepo.UpdateClient(suite.ctx, 9999, 1, &model.ClientUpdate{})
+		restoreErr := suite.clientRepo.RestoreClient(suite.ctx, 9999)
+
+		// Then
+		for _, err := range []error{getErr, deleteErr, updateErr, restoreErr} {
+			suite.True(myerrors.IsErrorCode(err, myerrors.ErrCodeClientNotFound),
+				"should return client not found error, got %v", err)
+		}
+	})
+	suite.T().Run("should return shoot not found", func(t *testing.T) {
+		// When
+		_, getErr := suite.shootRepo.GetShootByID(suite.ctx, 9999)
+		deleteErr := suite.shootRepo.DeleteShoot(suite.ctx, 9999)
+		_, updateErr := suite.shootRepo.UpdateShoot(suite.ctx, 9999, 1, &model.ShootUpdate{})
+		restoreErr := suite.shootRepo.RestoreShoot(suite.ctx, 9999)
+
+		// Then
+		for _, err := range []error{getErr, deleteErr, updateErr, restoreErr} {
+			suite.True(myerrors.IsErrorCode(err, myerrors.ErrCodeShootNotFound),
+				"should return shoot not found error, got %v", err)
+		}
+	})
+	suite.T().Run("should not add shoot of non-existent client", func(t *testing.T) {
+		// When
+		err := suite.shootRepo.AddShoot(suite.ctx, testutils.CreateTestShoot(9999))
+
+		// Then
+		suite.True(myerrors.IsErrorCode(err, myerrors.ErrCodeShootCreate), "should return shoot create error")
+	})
+}
+
+func (suite *ContractTestSuit) TestUpdate() {
+	// Given
+	client := suite.addClient("Petrov")
+	name := "Pyotr"
+
+	// When
+	updated, err := suite.clientRepo.UpdateClient(suite.ctx, client.Id, client.Version,
+		&model.ClientUpdate{FirstName: &name})
+
+	// Then
+	suite.Require().NoError(err, "should not return error")
+	suite.Equal(name, updated.FirstName, "should change first name")
+	suite.Equal(client.LastName, updated.LastName, "should keep last name")
+	suite.Equal(client.Version+1, updated.Version, "should increase version")
+
+	_, err = suite.clientRepo.UpdateClient(suite.ctx, client.Id, client.Version,
+		&model.ClientUpdate{FirstName: &name})
+	suite.True(myerrors.IsErrorCode(err, myerrors.ErrCodeVersionConflict),
+		"should reject update of stale version")
+}
+
+func (suite *ContractTestSuit) TestOverlap() {
+	// Given
+	client := suite.addClient("Ivanov")
+	shoot := suite.addShoot(client.Id, time.Now().AddDate(0, 0, 30))
+
+	overlapping := testutils.CreateTestShootWithOptions(client.Id, func(s *model.Shoot) {
+		s.ShootDate = shoot.ShootDate
+		s.StartTime = time.Date(0, 0, 0, 15, 30, 0, 0, time.UTC)
+		s.EndTime = time.Date(0, 0, 0, 17, 0, 0, 0, time.UTC)
+	})
+	adjacent := testutils.CreateTestShootWithOptions(client.Id, func(s *model.Shoot) {
+		s.ShootDate = shoot.ShootDate
+		s.StartTime = time.Date(0, 0, 0, 16, 0, 0, 0, time.UTC)
+		s.EndTime = time.Date(0, 0, 0, 17, 0, 0, 0, time.UTC)
+	})
+
+	// When
+	overlapErr := suite.shootRepo.AddShoot(suite.ctx, overlapping)
+	adjacentErr := suite.shootRepo.AddShoot(suite.ctx, adjacent)
+
+	// Then
+	suite.True(myerrors.IsErrorCode(overlapErr, myerrors.ErrCodeShootConflict),
+		"should return shoot conflict error")
+	suite.NoError(adjacentErr, "should allow shoot starting when another ends")
+}
+
+func (suite *ContractTestSuit) TestGetShoots() {
+	// Given
+	first := suite.addClient("Alekseev")
+	second := suite.addClient("Borisov")
+	date := time.Now().AddDate(0, 0, 30)
+	later := suite.addShoot(first.Id, date.AddDate(0, 0, 2))
+	earlier := suite.addShoot(second.Id, date)
+	suite.addShoot(first.Id, date.AddDate(0, 0, 4))
+
+	// When
+	page, err := suite.shootRepo.GetShoots(suite.ctx, model.ShootFilter{Limit: 2})
+
+	// Then
+	suite.Require().NoError(err, "should not return error")
+	suite.Require().Len(page, 2, "should return one page")
+	suite.Equal(earlier.Id, page[0].Id, "should order by date")
+	suite.Equal(later.Id, page[1].Id, "should order by date")
+	suite.Equal("Borisov", page[0].Client.LastName, "should fill in the client")
+
+	next, err := suite.shootRepo.GetShoots(suite.ctx, model.ShootFilter{After: &page[1], Limit: 2})
+	suite.Require().NoError(err, "should not return error")
+	suite.Len(next, 1, "should return the rest after the page")
+
+	filtered, err := suite.shootRepo.GetShoots(suite.ctx, model.ShootFilter{ClientId: first.Id})
+	suite.Require().NoError(err, "should not return error")
+	suite.Len(filtered, 2, "should keep the shoots of the client")
+}
+
+func (suite *ContractTestSuit) TestSearchClients() {
+	// Given
+	client := testutils.CreateTestClientWithOptions(func(c *model.Client) {
+		c.FirstName, c.LastName, c.Phone = "Anna", "Smirnova", "+7(916)123-45-67"
+	})
+	suite.Require().NoError(suite.clientRepo.AddClient(suite.ctx, client))
+	suite.addClient("Ivanov")
+
+	for _, query := range []string{"smirn", "916 123", "89161234567", "anna smir"} {
+		// When
+		found, err := suite.clientRepo.SearchClients(suite.ctx, query, 10)
+
+		// Then
+		suite.Require().NoError(err, "should not return error")
+		suite.Require().Len(found, 1, "should find one client by %q", query)
+		suite.Equal(client.Id, found[0].Id, "should find the client by %q", query)
+	}
+
+	// Given
+	stored8 := testutils.CreateTestClientWithOptions(func(c *model.Client) {
+		c.FirstName, c.LastName, c.Phone = "Oleg", "Petrov", "8(900)555-12-34"
+	})
+	suite.Require().NoError(suite.clientRepo.AddClient(suite.ctx, stored8))
+
+	for _, query := range []string{"89005551234", "+7 900 555-12-34"} {
+		// When
+		found, err := suite.clientRepo.SearchClients(suite.ctx, query, 10)
+
+		// Then
+		suite.Require().NoError(err, "should not return error")
+		suite.Require().Len(found, 1, "should find the phone stored with 8 by %q", query)
+		suite.Equal(stored8.Id, found[0].Id, "should find the client by %q", query)
+	}
+}
+
+func (suite *ContractTestSuit) TestDeleteRestorePurge() {
+	// Given
+	client := suite.addClient("Ivanov")
+	shoot := suite.addShoot(client.Id, time.Now().AddDate(0, 0, 30))
+
+	// When
+	err := suite.clientRepo.DeleteClient(suite.ctx, client.Id)
+
+	// Then
+	suite.Require().NoError(err, "should not return error")
+	_, err = suite.shootRepo.GetShootByID(suite.ctx, shoot.Id)
+	suite.True(myerrors.IsErrorCode(err, myerrors.ErrCodeShootNotFound), "should delete shoots with client")
+	deleted, err := suite.shootRepo.GetDeletedShoots(suite.ctx)
+	suite.Require().NoError(err, "should not return error")
+	suite.Len(deleted, 1, "should move shoots to the trash")
+	suite.True(myerrors.IsErrorCode(suite.shootRepo.RestoreShoot(suite.ctx, shoot.Id), myerrors.ErrCodeValidation),
+		"should not restore shoot of deleted client")
+
+	suite.Require().NoError(suite.clientRepo.RestoreClient(suite.ctx, client.Id), "should restore client")
+	restored, err := suite.shootRepo.GetShootByID(suite.ctx, shoot.Id)
+	suite.Require().NoError(err, "should restore shoots with client")
+	suite.Equal(client.Id, restored.ClientId, "should keep the client")
+
+	suite.Require().NoError(suite.clientRepo.DeleteClient(suite.ctx, client.Id))
+	purged, err := suite.clientRepo.PurgeClients(suite.ctx, 0)
+	suite.Require().NoError(err, "should not return error")
+	suite.Equal(1, purged, "should purge the client")
+	deleted, err = suite.shootRepo.GetDeletedShoots(suite.ctx)
+	suite.Require().NoError(err, "should not return error")
+	suite.Empty(deleted, "should purge shoots with client")
+	suite.True(myerrors.IsErrorCode(suite.clientRepo.RestoreClient(suite.ctx, client.Id),
+		myerrors.ErrCodeClientNotFound), "should not restore purged client")
+}
+
+func (suite *ContractTestSuit) TestPurgeHistory() {
+	// Given
+	client := suite.addClient("Ivanov")
+	shoot := suite.addShoot(client.Id, time.Now().AddDate(0, 0, 30))
+	payment := &model.Payment{ShootId: shoot.Id, Kind: model.PaymentDeposit,
+		Amount: model.NewMoney(100000, "RUB"), PaidAt: time.Now()}
+	suite.Require().NoError(suite.paymentRepo.AddPayment(suite.ctx, payment),
+		"precondition: payment should be created")
+	suite.Require().NoError(suite.clientRepo.DeleteClient(suite.ctx, client.Id),
+		"precondition: client should be deleted")
+
+	// When
+	_, err := suite.clientRepo.PurgeClients(suite.ctx, 0)
+
+	// Then
+	suite.Require().NoError(err, "should not return error")
+	history, err := suite.auditRepo.GetHistory(suite.ctx, model.AuditShoot, shoot.Id)
+	suite.Require().NoError(err, "should not return error")
+
+	purged := map[model.AuditEntity]int{}
+	for _, entry := range history {
+		if entry.Action == model.AuditPurge {
+			purged[entry.Entity] = entry.EntityId
+			suite.NotNil(entry.Before, "should keep the purged %s", entry.Entity)
+			suite.Nil(entry.After, "should leave nothing after the purge of %s", entry.Entity)
+		}
+	}
+	suite.Equal(map[model.AuditEntity]int{model.AuditShoot: shoot.Id, model.AuditPayment: payment.Id}, purged,
+		"should record the shoots and payments purged with the client")
+}
+
+func (suite *ContractTestSuit) TestConcurrentAdd() {
+	// Given
+	const count = 20
+	errs := make(chan error, count)
+
+	// When
+	for range count {
+		go func() {
+			errs <- suite.clientRepo.AddClient(suite.ctx, testutils.CreateTestClient())
+		}()
+	}
+
+	// Then
+	for range count {
+		suite.NoError(<-errs, "should not return error")
+	}
+	clients, err := suite.clientRepo.GetClients(suite.ctx, model.ClientFilter{})
+	suite.Require().NoError(err, "should not return error")
+	suite.Len(clients, count, "should keep every client")
+
+	ids := make(map[int]bool)
+	for _, client := range clients {
+		ids[client.Id] = true
+	}
+	suite.Len(ids, count, "should assign distinct IDs")
+}
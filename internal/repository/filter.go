@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Coiiap5e/photographer/internal/model"
+)
+
+// queryBuilder collects WHERE conditions and their numbered parameters
+type queryBuilder struct {
+	conditions []string
+	args       []any
+}
+
+// arg adds a parameter and returns its placeholder
+func (b *queryBuilder) arg(value any) string {
+	b.args = append(b.args, value)
+	return fmt.Sprintf("$%d", len(b.args))
+}
+
+func (b *queryBuilder) where(condition string) {
+	b.conditions = append(b.conditions, condition)
+}
+
+func (b *queryBuilder) whereClause() string {
+	if len(b.conditions) == 0 {
+		return ""
+	}
+	return "\nWHERE " + strings.Join(b.conditions, " AND ")
+}
+
+// sortKey is a column to order by and its value in the row a page starts
+// after
+type sortKey struct {
+	column string
+	value  any
+}
+
+// page orders by keys, the last of which must be unique, and starts after
+// the row the key values were taken from when after is set. It returns the
+// ORDER BY and LIMIT clauses.
+func (b *queryBuilder) page(keys []sortKey, after, desc bool, limit int) string {
+	columns := make([]string, len(keys))
+	order := make([]string, len(keys))
+	direction, compare := "ASC", ">"
+	if desc {
+		direction, compare = "DESC", "<"
+	}
+
+	for i, key := range keys {
+		columns[i] = key.column
+		order[i] = key.column + " " + direction
+	}
+
+	if after {
+		values := make([]string, len(keys))
+		for i, key := range keys {
+			values[i] = b.arg(key.value)
+		}
+		b.where(fmt.Sprintf("(%s) %s (%s)",
+			strings.Join(columns, ", "), compare, strings.Join(values, ", ")))
+	}
+
+	clause := "\nORDER BY " + strings.Join(order, ", ")
+	if limit > 0 {
+		clause += "\nLIMIT " + b.arg(limit)
+	}
+	return clause
+}
+
+// shootSortKeys returns the keys for sort taken from after, which may be
+// nil. Columns are qualified as shoots are joined with clients.
+func shootSortKeys(sort model.ShootSort, after *model.Shoot) []sortKey {
+	var shoot model.Shoot
+	if after != nil {
+		shoot = *after
+	}
+
+	switch sort {
+	case model.ShootSortPrice:
+		return []sortKey{
+			{"COALESCE(s.shoot_price, 0)", shoot.ShootPrice.Amount},
+			{"s.id", shoot.Id},
+		}
+	case model.ShootSortCreated:
+		return []sortKey{{"s.created_at", shoot.CreatedAt}, {"s.id", shoot.Id}}
+	default:
+		return []sortKey{
+			{"s.date", shoot.ShootDate},
+			{"s.start_time", shoot.StartTime},
+			{"s.id", shoot.Id},
+		}
+	}
+}
+
+func clientSortKeys(sort model.ClientSort, after *model.Client) []sortKey {
+	var client model.Client
+	if after != nil {
+		client = *after
+	}
+
+	switch sort {
+	case model.ClientSortCreated:
+		return []sortKey{{"created_at", client.CreatedAt}, {"id", client.Id}}
+	default:
+		return []sortKey{
+			{"last_name", client.LastName},
+			{"first_name", client.FirstName},
+			{"id", client.Id},
+		}
+	}
+}
+
+// escapeLike escapes the LIKE wildcards in a substring to search for
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
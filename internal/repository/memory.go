@@ -0,0 +1,258 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/Coiiap5e/photographer/internal/audit"
+	"github.com/Coiiap5e/photographer/internal/model"
+)
+
+// MemoryStore keeps clients, shoots, payments and their audit log in memory
+// for tests and the demo mode. The memory repositories share one store, so
+// shoots see their clients and purging cascades as in Postgres. It is safe
+// for concurrent use.
+type MemoryStore struct {
+	mu       sync.Mutex
+	clients  map[int]model.Client
+	shoots   map[int]model.Shoot
+	payments map[int]model.Payment
+	auditLog []model.AuditEntry
+	// sequences hand out IDs and, like in Postgres, are not rolled back
+	sequences map[model.AuditEntity]int
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		clients:   make(map[int]model.Client),
+		shoots:    make(map[int]model.Shoot),
+		payments:  make(map[int]model.Payment),
+		sequences: make(map[model.AuditEntity]int),
+	}
+}
+
+// memoryState is a copy of the stored data to roll back to
+type memoryState struct {
+	clients  map[int]model.Client
+	shoots   map[int]model.Shoot
+	payments map[int]model.Payment
+	auditLog []model.AuditEntry
+}
+
+// WithTx runs fn and puts the store back as it was if fn returns an error
+// or panics. Unlike a database transaction it does not hide the changes of
+// fn from concurrent callers.
+func (s *MemoryStore) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	s.mu.Lock()
+	saved := memoryState{
+		clients:  maps.Clone(s.clients),
+		shoots:   maps.Clone(s.shoots),
+		payments: maps.Clone(s.payments),
+		auditLog: slices.Clone(s.auditLog),
+	}
+	s.mu.Unlock()
+
+	committed := false
+	defer func() {
+		if !committed {
+			s.mu.Lock()
+			s.clients, s.shoots, s.payments, s.auditLog =
+				saved.clients, saved.shoots, saved.payments, saved.auditLog
+			s.mu.Unlock()
+		}
+	}()
+
+	if err := fn(ctx); err != nil {
+		return err
+	}
+
+	committed = true
+	return nil
+}
+
+// nextId returns the next ID of entity, the caller holds s.mu
+func (s *MemoryStore) nextId(entity model.AuditEntity) int {
+	s.sequences[entity]++
+	return s.sequences[entity]
+}
+
+// memoryNow returns the current time as a TIMESTAMP column stores it
+func memoryNow() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
+
+// memoryDate and memoryClock keep the parts of a time a DATE and a TIME
+// column store, in the form pgx scans them
+func memoryDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func memoryClock(t time.Time) time.Time {
+	return time.Date(2000, 1, 1, t.Hour(), t.Minute(), t.Second(),
+		t.Nanosecond()/1000*1000, time.UTC)
+}
+
+// withClient returns the shoot with its client filled in as the Postgres
+// repository joins it, the caller holds s.mu
+func (s *MemoryStore) withClient(shoot model.Shoot) model.Shoot {
+	client := s.clients[shoot.ClientId]
+	shoot.Client = model.Client{
+		Id:               client.Id,
+		FirstName:        client.FirstName,
+		LastName:         client.LastName,
+		Phone:            client.Phone,
+		SocialNetworkUrl: client.SocialNetworkUrl,
+	}
+	return shoot
+}
+
+// overlaps returns whether shoot clashes with a stored shoot the way the
+// shoots_no_overlap constraint sees it, the caller holds s.mu
+func (s *MemoryStore) overlaps(shoot model.Shoot) bool {
+	for _, other := range s.shoots {
+		if other.Id != shoot.Id && shootsOverlap(shoot, other) {
+			return true
+		}
+	}
+	return false
+}
+
+// shootsOverlap reports whether a and b take up the same time in the
+// schedule. Shoots in the trash and those allowed to overlap never do.
+func shootsOverlap(a, b model.Shoot) bool {
+	if a.AllowOverlap || b.AllowOverlap || a.DeletedAt != nil || b.DeletedAt != nil {
+		return false
+	}
+
+	aStart, aEnd := a.Interval()
+	bStart, bEnd := b.Interval()
+	return aStart.Before(bEnd) && bStart.Before(aEnd)
+}
+
+// Rows as to_jsonb writes them for the audit log of the stores that have
+// no to_jsonb
+type clientRow struct {
+	Id               int     `json:"id"`
+	FirstName        string  `json:"first_name"`
+	LastName         string  `json:"last_name"`
+	Phone            string  `json:"phone"`
+	SocialNetworkUrl string  `json:"social_network_url"`
+	CreatedAt        string  `json:"created_at"`
+	UpdatedAt        string  `json:"updated_at"`
+	Version          int     `json:"version"`
+	DeletedAt        *string `json:"deleted_at"`
+}
+
+type shootRow struct {
+	Id            int     `json:"id"`
+	ClientId      int     `json:"client_id"`
+	Date          string  `json:"date"`
+	StartTime     string  `json:"start_time"`
+	EndTime       string  `json:"end_time"`
+	ShootPrice    int64   `json:"shoot_price"`
+	ShootCurrency string  `json:"shoot_currency"`
+	Location      string  `json:"location"`
+	ShootType     string  `json:"shoot_type"`
+	Notes         string  `json:"notes"`
+	AllowOverlap  bool    `json:"allow_overlap"`
+	CreatedAt     string  `json:"created_at"`
+	UpdatedAt     string  `json:"updated_at"`
+	Version       int     `json:"version"`
+	DeletedAt     *string `json:"deleted_at"`
+}
+
+type paymentRow struct {
+	Id        int               `json:"id"`
+	ShootId   int               `json:"shoot_id"`
+	Kind      model.PaymentKind `json:"kind"`
+	Amount    int64             `json:"amount"`
+	Currency  string            `json:"currency"`
+	PaidAt    string            `json:"paid_at"`
+	Notes     string            `json:"notes"`
+	CreatedAt string            `json:"created_at"`
+}
+
+const jsonTimestamp = "2006-01-02T15:04:05.999999"
+
+func clientJSON(client model.Client) []byte {
+	data, _ := json.Marshal(clientRow{
+		Id: client.Id, FirstName: client.FirstName, LastName: client.LastName,
+		Phone: client.Phone, SocialNetworkUrl: client.SocialNetworkUrl,
+		CreatedAt: client.CreatedAt.Format(jsonTimestamp),
+		UpdatedAt: client.UpdatedAt.Format(jsonTimestamp),
+		Version:   client.Version, DeletedAt: jsonTime(client.DeletedAt),
+	})
+	return data
+}
+
+func shootJSON(shoot model.Shoot) []byte {
+	data, _ := json.Marshal(shootRow{
+		Id: shoot.Id, ClientId: shoot.ClientId, Date: shoot.ShootDate.Format(time.DateOnly),
+		StartTime: shoot.StartTime.Format(time.TimeOnly), EndTime: shoot.EndTime.Format(time.TimeOnly),
+		ShootPrice: shoot.ShootPrice.Amount, ShootCurrency: shoot.ShootPrice.Currency,
+		Location: shoot.ShootLocation, ShootType: shoot.ShootType, Notes: shoot.Notes,
+		AllowOverlap: shoot.AllowOverlap,
+		CreatedAt:    shoot.CreatedAt.Format(jsonTimestamp),
+		UpdatedAt:    shoot.UpdatedAt.Format(jsonTimestamp),
+		Version:      shoot.Version, DeletedAt: jsonTime(shoot.DeletedAt),
+	})
+	return data
+}
+
+func paymentJSON(payment model.Payment) []byte {
+	data, _ := json.Marshal(paymentRow{
+		Id: payment.Id, ShootId: payment.ShootId, Kind: payment.Kind,
+		Amount: payment.Amount.Amount, Currency: payment.Amount.Currency,
+		PaidAt: payment.PaidAt.Format(time.DateOnly), Notes: payment.Notes,
+		CreatedAt: payment.CreatedAt.Format(jsonTimestamp),
+	})
+	return data
+}
+
+func jsonTime(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	value := t.Format(jsonTimestamp)
+	return &value
+}
+
+// row returns the stored row of entity with id as JSON, nil if there is
+// none. The caller holds s.mu.
+func (s *MemoryStore) row(entity model.AuditEntity, id int) []byte {
+	switch entity {
+	case model.AuditClient:
+		if client, ok := s.clients[id]; ok {
+			return clientJSON(client)
+		}
+	case model.AuditShoot:
+		if shoot, ok := s.shoots[id]; ok {
+			return shootJSON(shoot)
+		}
+	case model.AuditPayment:
+		if payment, ok := s.payments[id]; ok {
+			return paymentJSON(payment)
+		}
+	}
+	return nil
+}
+
+// record adds an audit entry for the row of entity with id, before is the
+// row as it was. The caller holds s.mu and calls record after the change.
+func (s *MemoryStore) record(ctx context.Context, entity model.AuditEntity, id int,
+	action model.AuditAction, before []byte) {
+	s.auditLog = append(s.auditLog, model.AuditEntry{
+		Id:        int64(len(s.auditLog) + 1),
+		Entity:    entity,
+		EntityId:  id,
+		Action:    action,
+		Before:    before,
+		After:     s.row(entity, id),
+		Actor:     audit.Actor(ctx),
+		CreatedAt: memoryNow(),
+	})
+}
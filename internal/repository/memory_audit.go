@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/Coiiap5e/photographer/internal/model"
+)
+
+type memoryAudit struct {
+	store *MemoryStore
+}
+
+// NewMemoryAudit returns the audit log kept in store
+func NewMemoryAudit(store *MemoryStore) Audit {
+	return &memoryAudit{store: store}
+}
+
+// GetHistory returns the changes of a client or shoot, oldest first. The
+// history of a client includes its shoots and that of a shoot its payments.
+func (repo *memoryAudit) GetHistory(ctx context.Context, entity model.AuditEntity,
+	id int) ([]model.AuditEntry, error) {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	// the column that refers to the parent in the rows of child entries
+	var child model.AuditEntity
+	var parent string
+	switch entity {
+	case model.AuditClient:
+		child, parent = model.AuditShoot, "client_id"
+	case model.AuditShoot:
+		child, parent = model.AuditPayment, "shoot_id"
+	}
+
+	entries := make([]model.AuditEntry, 0)
+	for _, entry := range repo.store.auditLog {
+		switch {
+		case entry.Entity == entity && entry.EntityId == id:
+		case child != "" && entry.Entity == child &&
+			(refersTo(entry.Before, parent, id) || refersTo(entry.After, parent, id)):
+		default:
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// refersTo reports whether the JSON row has column set to id
+func refersTo(row json.RawMessage, column string, id int) bool {
+	if row == nil {
+		return false
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal(row, &fields); err != nil {
+		return false
+	}
+
+	value, ok := fields[column].(float64)
+	return ok && int(value) == id
+}
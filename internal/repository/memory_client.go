@@ -0,0 +1,405 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	myerrors "github.com/Coiiap5e/photographer/internal/errors"
+	"github.com/Coiiap5e/photographer/internal/model"
+)
+
+type memoryClient struct {
+	store *MemoryStore
+}
+
+// NewMemoryClient returns a client repository keeping its data in store
+func NewMemoryClient(store *MemoryStore) Client {
+	return &memoryClient{store: store}
+}
+
+// insertClient adds a client and fills in its generated fields, the caller
+// holds s.mu
+func (s *MemoryStore) insertClient(ctx context.Context, client *model.Client) {
+	now := memoryNow()
+	client.Id = s.nextId(model.AuditClient)
+	client.CreatedAt, client.UpdatedAt = now, now
+	client.Version = 1
+	client.DeletedAt = nil
+
+	s.clients[client.Id] = *client
+	s.record(ctx, model.AuditClient, client.Id, model.AuditCreate, nil)
+}
+
+func (repo *memoryClient) AddClient(ctx context.Context, client *model.Client) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	repo.store.insertClient(ctx, client)
+	return nil
+}
+
+// AddClients adds all clients, either every client is added or none is
+func (repo *memoryClient) AddClients(ctx context.Context, clients []*model.Client) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	for _, client := range clients {
+		repo.store.insertClient(ctx, client)
+	}
+	return nil
+}
+
+// DeleteClient moves the client and its shoots to the trash with the same
+// deletion time
+func (repo *memoryClient) DeleteClient(ctx context.Context, id int) error {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	client, ok := s.clients[id]
+	if !ok || client.DeletedAt != nil {
+		return myerrors.New(myerrors.ErrCodeClientNotFound, "client not found")
+	}
+
+	now := memoryNow()
+	before := s.row(model.AuditClient, id)
+	client.DeletedAt = &now
+	s.clients[id] = client
+	s.record(ctx, model.AuditClient, id, model.AuditDelete, before)
+
+	for _, shootId := range slices.Sorted(maps.Keys(s.shoots)) {
+		shoot := s.shoots[shootId]
+		if shoot.ClientId != id || shoot.DeletedAt != nil {
+			continue
+		}
+		before := s.row(model.AuditShoot, shootId)
+		shoot.DeletedAt = &now
+		s.shoots[shootId] = shoot
+		s.record(ctx, model.AuditShoot, shootId, model.AuditDelete, before)
+	}
+
+	return nil
+}
+
+func (repo *memoryClient) GetClientByID(ctx context.Context, id int) (*model.Client, error) {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	client, ok := repo.store.clients[id]
+	if !ok || client.DeletedAt != nil {
+		return nil, myerrors.New(myerrors.ErrCodeClientNotFound, "client not found")
+	}
+
+	return &client, nil
+}
+
+// GetClients returns clients in filter's sort order, at most filter.Limit
+// of them if it is set
+func (repo *memoryClient) GetClients(ctx context.Context, filter model.ClientFilter) ([]model.Client, error) {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	compare := func(a, b model.Client) int {
+		return compareClients(filter.Sort, a, b)
+	}
+	if filter.Desc {
+		compare = func(a, b model.Client) int {
+			return compareClients(filter.Sort, b, a)
+		}
+	}
+
+	clients := make([]model.Client, 0)
+	for _, client := range repo.store.clients {
+		if client.DeletedAt != nil {
+			continue
+		}
+		if filter.After != nil && compare(client, *filter.After) <= 0 {
+			continue
+		}
+		clients = append(clients, client)
+	}
+
+	slices.SortFunc(clients, compare)
+
+	if filter.Limit > 0 && len(clients) > filter.Limit {
+		clients = clients[:filter.Limit]
+	}
+
+	return clients, nil
+}
+
+// compareClients orders clients by the keys of clientSortKeys
+func compareClients(sort model.ClientSort, a, b model.Client) int {
+	switch sort {
+	case model.ClientSortCreated:
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), cmp.Compare(a.Id, b.Id))
+	default:
+		return cmp.Or(
+			strings.Compare(a.LastName, b.LastName),
+			strings.Compare(a.FirstName, b.FirstName),
+			cmp.Compare(a.Id, b.Id))
+	}
+}
+
+// SearchClients returns up to limit clients matching every word of query in
+// the same way as the Postgres repository. Closest names come first.
+func (repo *memoryClient) SearchClients(ctx context.Context, query string, limit int) ([]model.Client, error) {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	clients := make([]model.Client, 0, len(repo.store.clients))
+	for _, client := range repo.store.clients {
+		if client.DeletedAt == nil {
+			clients = append(clients, client)
+		}
+	}
+
+	return searchClients(clients, query, limit), nil
+}
+
+// searchClients returns up to limit of clients matching every word of query
+// as the Postgres SearchClients matches them, closest names first
+func searchClients(clients []model.Client, query string, limit int) []model.Client {
+	words := strings.Fields(query)
+	if strings.IndexFunc(query, unicode.IsLetter) < 0 {
+		words = []string{phoneDigits(query)}
+	}
+
+	type match struct {
+		client     model.Client
+		similarity float64
+	}
+
+	var matches []match
+	for _, client := range clients {
+		if !matchesClient(client, words) {
+			continue
+		}
+		matches = append(matches, match{
+			client:     client,
+			similarity: similarity(client.FirstName+" "+client.LastName, query),
+		})
+	}
+
+	slices.SortFunc(matches, func(a, b match) int {
+		return cmp.Or(
+			cmp.Compare(b.similarity, a.similarity),
+			compareClients(model.ClientSortName, a.client, b.client))
+	})
+
+	found := make([]model.Client, 0, len(matches))
+	for _, m := range matches {
+		if limit > 0 && len(found) == limit {
+			break
+		}
+		found = append(found, m.client)
+	}
+
+	return found
+}
+
+// matchesClient reports whether every word matches the client as in the
+// conditions of the Postgres SearchClients
+func matchesClient(client model.Client, words []string) bool {
+	text := strings.ToLower(client.FirstName + " " + client.LastName + " " + client.SocialNetworkUrl)
+	phone := phoneDigits(client.Phone)
+
+	for _, word := range words {
+		if strings.Contains(text, strings.ToLower(word)) {
+			continue
+		}
+		if number := phoneDigits(word); len(number) >= 3 && strings.Contains(phone, number) {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// similarity is the pg_trgm similarity of a and b, the share of trigrams of
+// their words the two have in common
+func similarity(a, b string) float64 {
+	first, second := trigrams(a), trigrams(b)
+	if len(first) == 0 || len(second) == 0 {
+		return 0
+	}
+
+	common := 0
+	for trigram := range first {
+		if second[trigram] {
+			common++
+		}
+	}
+	return float64(common) / float64(len(first)+len(second)-common)
+}
+
+// trigrams returns the trigrams of the alphanumeric words of s, each padded
+// with two spaces in front and one at the end
+func trigrams(s string) map[string]bool {
+	result := make(map[string]bool)
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		runes := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(runes); i++ {
+			result[string(runes[i:i+3])] = true
+		}
+	}
+	return result
+}
+
+// UpdateClient changes the non-nil fields of update if the stored version
+// still equals version.
+func (repo *memoryClient) UpdateClient(ctx context.Context, id, version int,
+	update *model.ClientUpdate) (*model.Client, error) {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	client, ok := s.clients[id]
+	if !ok || client.DeletedAt != nil {
+		return nil, myerrors.New(myerrors.ErrCodeClientNotFound, "client not found")
+	}
+
+	if client.Version != version {
+		return nil, myerrors.New(myerrors.ErrCodeVersionConflict, "client was changed by someone else")
+	}
+
+	before := s.row(model.AuditClient, id)
+	if update.FirstName != nil {
+		client.FirstName = *update.FirstName
+	}
+	if update.LastName != nil {
+		client.LastName = *update.LastName
+	}
+	if update.Phone != nil {
+		client.Phone = *update.Phone
+	}
+	if update.SocialNetworkUrl != nil {
+		client.SocialNetworkUrl = *update.SocialNetworkUrl
+	}
+	client.UpdatedAt = memoryNow()
+	client.Version++
+
+	s.clients[id] = client
+	s.record(ctx, model.AuditClient, id, model.AuditUpdate, before)
+
+	return &client, nil
+}
+
+// GetDeletedClients returns the clients in the trash, most recently deleted
+// first
+func (repo *memoryClient) GetDeletedClients(ctx context.Context) ([]model.Client, error) {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	clients := make([]model.Client, 0)
+	for _, client := range repo.store.clients {
+		if client.DeletedAt != nil {
+			clients = append(clients, client)
+		}
+	}
+
+	slices.SortFunc(clients, func(a, b model.Client) int {
+		return cmp.Or(b.DeletedAt.Compare(*a.DeletedAt), cmp.Compare(b.Id, a.Id))
+	})
+
+	return clients, nil
+}
+
+// RestoreClient takes the client out of the trash together with the shoots
+// that were deleted with it
+func (repo *memoryClient) RestoreClient(ctx context.Context, id int) error {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	client, ok := s.clients[id]
+	if !ok || client.DeletedAt == nil {
+		return myerrors.New(myerrors.ErrCodeClientNotFound, "client not found in trash")
+	}
+
+	var shoots []int
+	for _, shootId := range slices.Sorted(maps.Keys(s.shoots)) {
+		shoot := s.shoots[shootId]
+		if shoot.ClientId == id && shoot.DeletedAt != nil && shoot.DeletedAt.Equal(*client.DeletedAt) {
+			shoots = append(shoots, shootId)
+		}
+	}
+
+	// check every shoot before changing any, so a conflict leaves the
+	// store as it was
+	restored := make(map[int]model.Shoot, len(shoots))
+	for _, shootId := range shoots {
+		shoot := s.shoots[shootId]
+		shoot.DeletedAt = nil
+		if s.overlaps(shoot) || overlapsAny(shoot, slices.Collect(maps.Values(restored))) {
+			return myerrors.New(myerrors.ErrCodeShootConflict,
+				"client's shoots overlap shoots booked since deletion")
+		}
+		restored[shootId] = shoot
+	}
+
+	before := s.row(model.AuditClient, id)
+	client.DeletedAt = nil
+	s.clients[id] = client
+
+	shootsBefore := make(map[int][]byte, len(shoots))
+	for _, shootId := range shoots {
+		shootsBefore[shootId] = s.row(model.AuditShoot, shootId)
+		s.shoots[shootId] = restored[shootId]
+	}
+
+	s.record(ctx, model.AuditClient, id, model.AuditRestore, before)
+	for _, shootId := range shoots {
+		s.record(ctx, model.AuditShoot, shootId, model.AuditRestore, shootsBefore[shootId])
+	}
+
+	return nil
+}
+
+// overlapsAny reports whether shoot clashes with one of shoots
+func overlapsAny(shoot model.Shoot, shoots []model.Shoot) bool {
+	for _, other := range shoots {
+		if shootsOverlap(shoot, other) {
+			return true
+		}
+	}
+	return false
+}
+
+// PurgeClients permanently removes clients deleted more than olderThan ago
+// with all their shoots and payments. It returns how many were removed.
+func (repo *memoryClient) PurgeClients(ctx context.Context, olderThan time.Duration) (int, error) {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cutoff := memoryNow().Add(-olderThan)
+
+	purged := 0
+	for _, id := range slices.Sorted(maps.Keys(s.clients)) {
+		client := s.clients[id]
+		if client.DeletedAt == nil || !client.DeletedAt.Before(cutoff) {
+			continue
+		}
+
+		before := s.row(model.AuditClient, id)
+		for _, shootId := range slices.Sorted(maps.Keys(s.shoots)) {
+			if s.shoots[shootId].ClientId == id {
+				s.purgeShoot(ctx, shootId)
+			}
+		}
+		delete(s.clients, id)
+		s.record(ctx, model.AuditClient, id, model.AuditPurge, before)
+		purged++
+	}
+
+	return purged, nil
+}
@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+
+	myerrors "github.com/Coiiap5e/photographer/internal/errors"
+	"github.com/Coiiap5e/photographer/internal/model"
+)
+
+type memoryPayment struct {
+	store *MemoryStore
+}
+
+// NewMemoryPayment returns a payment repository keeping its data in store
+func NewMemoryPayment(store *MemoryStore) Payment {
+	return &memoryPayment{store: store}
+}
+
+// LockShootPayments does nothing, MemoryStore.WithTx does not isolate
+// transactions anyway
+func (repo *memoryPayment) LockShootPayments(context.Context, int) error {
+	return nil
+}
+
+func (repo *memoryPayment) AddPayment(ctx context.Context, payment *model.Payment) error {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.shoots[payment.ShootId]; !ok {
+		return myerrors.New(myerrors.ErrCodePaymentCreate, "failed to create payment: shoot does not exist")
+	}
+
+	payment.Id = s.nextId(model.AuditPayment)
+	payment.CreatedAt = memoryNow()
+
+	stored := *payment
+	stored.PaidAt = memoryDate(payment.PaidAt)
+	s.payments[stored.Id] = stored
+	s.record(ctx, model.AuditPayment, stored.Id, model.AuditCreate, nil)
+
+	return nil
+}
+
+func (repo *memoryPayment) GetPaymentsByShoot(ctx context.Context, shootID int) ([]model.Payment, error) {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	payments := make([]model.Payment, 0)
+	for _, payment := range repo.store.payments {
+		if payment.ShootId == shootID {
+			payments = append(payments, payment)
+		}
+	}
+
+	slices.SortFunc(payments, func(a, b model.Payment) int {
+		return cmp.Or(a.PaidAt.Compare(b.PaidAt), cmp.Compare(a.Id, b.Id))
+	})
+
+	return payments, nil
+}
+
+// shootBalance sums the payments of shoot, refunds count as negative. The
+// caller holds s.mu.
+func (s *MemoryStore) shootBalance(shoot model.Shoot) model.ShootBalance {
+	client := s.clients[shoot.ClientId]
+	balance := model.ShootBalance{
+		ShootId:         shoot.Id,
+		ClientId:        shoot.ClientId,
+		ClientFirstName: client.FirstName,
+		ClientLastName:  client.LastName,
+		ShootDate:       shoot.ShootDate,
+		Price:           shoot.ShootPrice,
+		Paid:            model.Money{Currency: shoot.ShootPrice.Currency},
+	}
+
+	for _, payment := range s.payments {
+		if payment.ShootId != shoot.Id {
+			continue
+		}
+		if payment.Kind == model.PaymentRefund {
+			balance.Paid.Amount -= payment.Amount.Amount
+		} else {
+			balance.Paid.Amount += payment.Amount.Amount
+		}
+	}
+
+	return balance
+}
+
+func (repo *memoryPayment) GetShootBalance(ctx context.Context, shootID int) (*model.ShootBalance, error) {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	shoot, ok := s.shoots[shootID]
+	if !ok || shoot.DeletedAt != nil {
+		return nil, myerrors.New(myerrors.ErrCodeShootNotFound, "shoot not found")
+	}
+
+	balance := s.shootBalance(shoot)
+	return &balance, nil
+}
+
+// activeShoots returns the shoots outside the trash by date and start time,
+// the caller holds s.mu
+func (s *MemoryStore) activeShoots() []model.Shoot {
+	var shoots []model.Shoot
+	for _, shoot := range s.shoots {
+		if shoot.DeletedAt == nil {
+			shoots = append(shoots, shoot)
+		}
+	}
+
+	slices.SortFunc(shoots, func(a, b model.Shoot) int {
+		return compareShoots(model.ShootSortDate, a, b)
+	})
+
+	return shoots
+}
+
+func (repo *memoryPayment) GetShootBalances(ctx context.Context) ([]model.ShootBalance, error) {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	balances := make([]model.ShootBalance, 0)
+	for _, shoot := range s.activeShoots() {
+		balances = append(balances, s.shootBalance(shoot))
+	}
+
+	return balances, nil
+}
+
+func (repo *memoryPayment) GetClientBalances(ctx context.Context) ([]model.ClientBalance, error) {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	type key struct {
+		clientId int
+		currency string
+	}
+
+	totals := make(map[key]*model.ClientBalance)
+	balances := make([]*model.ClientBalance, 0)
+	for _, shoot := range s.activeShoots() {
+		shootBalance := s.shootBalance(shoot)
+		k := key{shoot.ClientId, shoot.ShootPrice.Currency}
+
+		total, ok := totals[k]
+		if !ok {
+			total = &model.ClientBalance{
+				ClientId:  shootBalance.ClientId,
+				FirstName: shootBalance.ClientFirstName,
+				LastName:  shootBalance.ClientLastName,
+				Price:     model.Money{Currency: k.currency},
+				Paid:      model.Money{Currency: k.currency},
+			}
+			totals[k] = total
+			balances = append(balances, total)
+		}
+		total.Price.Amount += shootBalance.Price.Amount
+		total.Paid.Amount += shootBalance.Paid.Amount
+	}
+
+	slices.SortFunc(balances, func(a, b *model.ClientBalance) int {
+		return cmp.Or(
+			strings.Compare(a.LastName, b.LastName),
+			strings.Compare(a.FirstName, b.FirstName),
+			cmp.Compare(a.ClientId, b.ClientId),
+			strings.Compare(a.Price.Currency, b.Price.Currency))
+	})
+
+	result := make([]model.ClientBalance, len(balances))
+	for i, balance := range balances {
+		result[i] = *balance
+	}
+
+	return result, nil
+}
@@ -0,0 +1,318 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	myerrors "github.com/Coiiap5e/photographer/internal/errors"
+	"github.com/Coiiap5e/photographer/internal/model"
+)
+
+type memoryShoot struct {
+	store *MemoryStore
+}
+
+// NewMemoryShoot returns a shoot repository keeping its data in store
+func NewMemoryShoot(store *MemoryStore) Shoot {
+	return &memoryShoot{store: store}
+}
+
+// purgeShoot removes the shoot with its payments and records them all in
+// the audit log, the caller holds s.mu
+func (s *MemoryStore) purgeShoot(ctx context.Context, id int) {
+	for _, paymentId := range slices.Sorted(maps.Keys(s.payments)) {
+		if s.payments[paymentId].ShootId == id {
+			before := s.row(model.AuditPayment, paymentId)
+			delete(s.payments, paymentId)
+			s.record(ctx, model.AuditPayment, paymentId, model.AuditPurge, before)
+		}
+	}
+	before := s.row(model.AuditShoot, id)
+	delete(s.shoots, id)
+	s.record(ctx, model.AuditShoot, id, model.AuditPurge, before)
+}
+
+func (repo *memoryShoot) AddShoot(ctx context.Context, shoot *model.Shoot) error {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.clients[shoot.ClientId]; !ok {
+		return myerrors.New(myerrors.ErrCodeShootCreate, "failed to create shoot: client does not exist")
+	}
+
+	stored := *shoot
+	stored.ShootDate = memoryDate(shoot.ShootDate)
+	stored.StartTime = memoryClock(shoot.StartTime)
+	stored.EndTime = memoryClock(shoot.EndTime)
+	stored.DeletedAt = nil
+	stored.Client = model.Client{}
+	if s.overlaps(stored) {
+		return myerrors.New(myerrors.ErrCodeShootConflict, "shoot overlaps another shoot")
+	}
+
+	now := memoryNow()
+	stored.Id = s.nextId(model.AuditShoot)
+	stored.CreatedAt, stored.UpdatedAt = now, now
+	stored.Version = 1
+
+	s.shoots[stored.Id] = stored
+	s.record(ctx, model.AuditShoot, stored.Id, model.AuditCreate, nil)
+
+	shoot.Id, shoot.CreatedAt, shoot.UpdatedAt, shoot.Version =
+		stored.Id, stored.CreatedAt, stored.UpdatedAt, stored.Version
+	return nil
+}
+
+// DeleteShoot moves the shoot to the trash
+func (repo *memoryShoot) DeleteShoot(ctx context.Context, id int) error {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	shoot, ok := s.shoots[id]
+	if !ok || shoot.DeletedAt != nil {
+		return myerrors.New(myerrors.ErrCodeShootNotFound, "shoot not found")
+	}
+
+	before := s.row(model.AuditShoot, id)
+	now := memoryNow()
+	shoot.DeletedAt = &now
+	s.shoots[id] = shoot
+	s.record(ctx, model.AuditShoot, id, model.AuditDelete, before)
+
+	return nil
+}
+
+func (repo *memoryShoot) GetShootByID(ctx context.Context, id int) (*model.Shoot, error) {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	shoot, ok := s.shoots[id]
+	if !ok || shoot.DeletedAt != nil {
+		return nil, myerrors.New(myerrors.ErrCodeShootNotFound, "shoot not found")
+	}
+
+	shoot = s.withClient(shoot)
+	return &shoot, nil
+}
+
+// GetShoots returns the shoots matching filter in its sort order, at most
+// filter.Limit of them if it is set
+func (repo *memoryShoot) GetShoots(ctx context.Context, filter model.ShootFilter) ([]model.Shoot, error) {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	compare := func(a, b model.Shoot) int {
+		return compareShoots(filter.Sort, a, b)
+	}
+	if filter.Desc {
+		compare = func(a, b model.Shoot) int {
+			return compareShoots(filter.Sort, b, a)
+		}
+	}
+
+	shoots := make([]model.Shoot, 0)
+	for _, shoot := range s.shoots {
+		if shoot.DeletedAt != nil || !matchesShoot(shoot, filter) {
+			continue
+		}
+		if filter.After != nil && compare(shoot, *filter.After) <= 0 {
+			continue
+		}
+		shoots = append(shoots, s.withClient(shoot))
+	}
+
+	slices.SortFunc(shoots, compare)
+
+	if filter.Limit > 0 && len(shoots) > filter.Limit {
+		shoots = shoots[:filter.Limit]
+	}
+
+	return shoots, nil
+}
+
+// matchesShoot reports whether shoot passes the conditions of filter
+func matchesShoot(shoot model.Shoot, filter model.ShootFilter) bool {
+	if !filter.From.IsZero() && shoot.ShootDate.Before(memoryDate(filter.From)) {
+		return false
+	}
+	if !filter.To.IsZero() && shoot.ShootDate.After(memoryDate(filter.To)) {
+		return false
+	}
+	if filter.ClientId != 0 && shoot.ClientId != filter.ClientId {
+		return false
+	}
+	if filter.ShootType != "" && !strings.EqualFold(shoot.ShootType, filter.ShootType) {
+		return false
+	}
+	if filter.Location != "" &&
+		!strings.Contains(strings.ToLower(shoot.ShootLocation), strings.ToLower(filter.Location)) {
+		return false
+	}
+	if filter.MinPrice != nil && (shoot.ShootPrice.Currency != filter.MinPrice.Currency ||
+		shoot.ShootPrice.Amount < filter.MinPrice.Amount) {
+		return false
+	}
+	if filter.MaxPrice != nil && (shoot.ShootPrice.Currency != filter.MaxPrice.Currency ||
+		shoot.ShootPrice.Amount > filter.MaxPrice.Amount) {
+		return false
+	}
+	return true
+}
+
+// compareShoots orders shoots by the keys of shootSortKeys
+func compareShoots(sort model.ShootSort, a, b model.Shoot) int {
+	switch sort {
+	case model.ShootSortPrice:
+		return cmp.Or(cmp.Compare(a.ShootPrice.Amount, b.ShootPrice.Amount), cmp.Compare(a.Id, b.Id))
+	case model.ShootSortCreated:
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), cmp.Compare(a.Id, b.Id))
+	default:
+		return cmp.Or(
+			memoryDate(a.ShootDate).Compare(memoryDate(b.ShootDate)),
+			memoryClock(a.StartTime).Compare(memoryClock(b.StartTime)),
+			cmp.Compare(a.Id, b.Id))
+	}
+}
+
+// UpdateShoot changes the non-nil fields of update if the stored version
+// still equals version.
+func (repo *memoryShoot) UpdateShoot(ctx context.Context, id, version int,
+	update *model.ShootUpdate) (*model.Shoot, error) {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	shoot, ok := s.shoots[id]
+	if !ok || shoot.DeletedAt != nil {
+		return nil, myerrors.New(myerrors.ErrCodeShootNotFound, "shoot not found")
+	}
+
+	if shoot.Version != version {
+		return nil, myerrors.New(myerrors.ErrCodeVersionConflict, "shoot was changed by someone else")
+	}
+
+	if update.ClientId != nil {
+		if _, ok := s.clients[*update.ClientId]; !ok {
+			return nil, myerrors.New(myerrors.ErrCodeShootUpdate, "failed to update shoot: client does not exist")
+		}
+		shoot.ClientId = *update.ClientId
+	}
+	if update.ShootDate != nil {
+		shoot.ShootDate = memoryDate(*update.ShootDate)
+	}
+	if update.StartTime != nil {
+		shoot.StartTime = memoryClock(*update.StartTime)
+	}
+	if update.EndTime != nil {
+		shoot.EndTime = memoryClock(*update.EndTime)
+	}
+	if update.ShootPrice != nil {
+		shoot.ShootPrice = *update.ShootPrice
+	}
+	if update.ShootLocation != nil {
+		shoot.ShootLocation = *update.ShootLocation
+	}
+	if update.ShootType != nil {
+		shoot.ShootType = *update.ShootType
+	}
+	if update.Notes != nil {
+		shoot.Notes = *update.Notes
+	}
+	if update.AllowOverlap != nil {
+		shoot.AllowOverlap = *update.AllowOverlap
+	}
+
+	if s.overlaps(shoot) {
+		return nil, myerrors.New(myerrors.ErrCodeShootConflict, "shoot overlaps another shoot")
+	}
+
+	shoot.UpdatedAt = memoryNow()
+	shoot.Version++
+
+	before := s.row(model.AuditShoot, id)
+	s.shoots[id] = shoot
+	s.record(ctx, model.AuditShoot, id, model.AuditUpdate, before)
+
+	shoot = s.withClient(shoot)
+	return &shoot, nil
+}
+
+// GetDeletedShoots returns the shoots in the trash, including those deleted
+// with their client, most recently deleted first
+func (repo *memoryShoot) GetDeletedShoots(ctx context.Context) ([]model.Shoot, error) {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	shoots := make([]model.Shoot, 0)
+	for _, shoot := range s.shoots {
+		if shoot.DeletedAt != nil {
+			shoots = append(shoots, s.withClient(shoot))
+		}
+	}
+
+	slices.SortFunc(shoots, func(a, b model.Shoot) int {
+		return cmp.Or(b.DeletedAt.Compare(*a.DeletedAt), cmp.Compare(b.Id, a.Id))
+	})
+
+	return shoots, nil
+}
+
+// RestoreShoot takes the shoot out of the trash. A shoot of a deleted client
+// is restored with the client instead.
+func (repo *memoryShoot) RestoreShoot(ctx context.Context, id int) error {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	shoot, ok := s.shoots[id]
+	if !ok || shoot.DeletedAt == nil {
+		return myerrors.New(myerrors.ErrCodeShootNotFound, "shoot not found in trash")
+	}
+
+	if s.clients[shoot.ClientId].DeletedAt != nil {
+		return myerrors.New(myerrors.ErrCodeValidation, "shoot's client is deleted, restore the client instead")
+	}
+
+	before := s.row(model.AuditShoot, id)
+	shoot.DeletedAt = nil
+	if s.overlaps(shoot) {
+		return myerrors.New(myerrors.ErrCodeShootConflict, "shoot overlaps another shoot")
+	}
+
+	s.shoots[id] = shoot
+	s.record(ctx, model.AuditShoot, id, model.AuditRestore, before)
+
+	return nil
+}
+
+// PurgeShoots permanently removes shoots deleted more than olderThan ago with
+// their payments. It returns how many were removed.
+func (repo *memoryShoot) PurgeShoots(ctx context.Context, olderThan time.Duration) (int, error) {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cutoff := memoryNow().Add(-olderThan)
+
+	purged := 0
+	for _, id := range slices.Sorted(maps.Keys(s.shoots)) {
+		shoot := s.shoots[id]
+		if shoot.DeletedAt == nil || !shoot.DeletedAt.Before(cutoff) {
+			continue
+		}
+
+		s.purgeShoot(ctx, id)
+		purged++
+	}
+
+	return purged, nil
+}
@@ -0,0 +1,204 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Coiiap5e/photographer/internal/database"
+	myerrors "github.com/Coiiap5e/photographer/internal/errors"
+	"github.com/Coiiap5e/photographer/internal/model"
+	"github.com/jackc/pgx/v5"
+)
+
+type Payment interface {
+	// LockShootPayments keeps other transactions from adding payments to the
+	// shoot until the transaction of ctx ends, so a balance read after it
+	// stays true
+	LockShootPayments(ctx context.Context, shootID int) error
+	AddPayment(ctx context.Context, payment *model.Payment) error
+	GetPaymentsByShoot(ctx context.Context, shootID int) ([]model.Payment, error)
+	GetShootBalance(ctx context.Context, shootID int) (*model.ShootBalance, error)
+	GetShootBalances(ctx context.Context) ([]model.ShootBalance, error)
+	GetClientBalances(ctx context.Context) ([]model.ClientBalance, error)
+}
+
+type postgresPayment struct {
+	db *database.DB
+}
+
+func NewPayment(db *database.DB) Payment {
+	return &postgresPayment{db: db}
+}
+
+// shootBalancesQuery sums payments per shoot, refunds count as negative.
+// Deleted shoots are left out, callers add their own conditions with AND and
+// GROUP BY s.id, c.id
+const shootBalancesQuery = `
+SELECT
+	s.id, s.client_id, c.first_name, c.last_name,
+	s.date, COALESCE(s.shoot_price, 0), s.shoot_currency,
+	COALESCE(SUM(CASE WHEN p.kind = 'refund' THEN -p.amount ELSE p.amount END), 0)
+FROM shoots s
+JOIN clients c ON c.id = s.client_id
+LEFT JOIN payments p ON p.shoot_id = s.id
+WHERE s.deleted_at IS NULL`
+
+// LockShootPayments locks the row of the shoot, payments reference it, so
+// concurrent payments for one shoot wait for each other. It locks nothing
+// outside a transaction.
+func (repo *postgresPayment) LockShootPayments(ctx context.Context, shootID int) error {
+	query := `SELECT id FROM shoots WHERE id = $1 FOR UPDATE`
+
+	if _, err := repo.db.Conn(ctx).Exec(ctx, query, shootID); err != nil {
+		return myerrors.Wrap(err, myerrors.ErrCodeDBQuery, "failed to lock shoot payments")
+	}
+
+	return nil
+}
+
+func (repo *postgresPayment) AddPayment(ctx context.Context, payment *model.Payment) error {
+	query := `
+INSERT INTO payments
+	(shoot_id, kind, amount, currency, paid_at, notes)
+VALUES
+	($1, $2, $3, $4, $5, $6)
+RETURNING
+	id, created_at`
+
+	return repo.db.WithTx(ctx, func(ctx context.Context) error {
+		tx := repo.db.Conn(ctx)
+		err := tx.QueryRow(ctx, query, payment.ShootId, payment.Kind,
+			payment.Amount.Amount, payment.Amount.Currency, payment.PaidAt,
+			payment.Notes).Scan(&payment.Id, &payment.CreatedAt)
+
+		if err != nil {
+			return myerrors.Wrap(err, myerrors.ErrCodePaymentCreate, "failed to create payment")
+		}
+
+		return record(ctx, tx, model.AuditPayment, payment.Id, model.AuditCreate, nil)
+	})
+}
+
+func (repo *postgresPayment) GetPaymentsByShoot(ctx context.Context, shootID int) ([]model.Payment, error) {
+	query := `
+SELECT id, shoot_id, kind, amount, currency, paid_at, COALESCE(notes, ''), created_at
+FROM payments
+WHERE shoot_id = $1
+ORDER BY paid_at, id`
+
+	rows, err := repo.db.Conn(ctx).Query(ctx, query, shootID)
+	if err != nil {
+		return nil, myerrors.Wrap(err, myerrors.ErrCodePaymentList, "failed to get payments")
+	}
+
+	defer rows.Close()
+
+	payments := make([]model.Payment, 0)
+
+	for rows.Next() {
+		var payment model.Payment
+		err := rows.Scan(&payment.Id, &payment.ShootId, &payment.Kind,
+			&payment.Amount.Amount, &payment.Amount.Currency, &payment.PaidAt, &payment.Notes, &payment.CreatedAt)
+		if err != nil {
+			return nil, myerrors.Wrap(err, myerrors.ErrCodePaymentList, "failed to get payment")
+		}
+		payments = append(payments, payment)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, myerrors.Wrap(err, myerrors.ErrCodeDBSelect, "error during rows iteration")
+	}
+
+	return payments, nil
+}
+
+func (repo *postgresPayment) GetShootBalance(ctx context.Context, shootID int) (*model.ShootBalance, error) {
+	query := shootBalancesQuery + `
+  AND s.id = $1
+GROUP BY s.id, c.id`
+
+	var balance model.ShootBalance
+	err := repo.db.Conn(ctx).QueryRow(ctx, query, shootID).Scan(&balance.ShootId, &balance.ClientId,
+		&balance.ClientFirstName, &balance.ClientLastName, &balance.ShootDate,
+		&balance.Price.Amount, &balance.Price.Currency, &balance.Paid.Amount)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, myerrors.New(myerrors.ErrCodeShootNotFound, "shoot not found")
+		}
+		return nil, myerrors.Wrap(err, myerrors.ErrCodePaymentList, "failed to get shoot balance")
+	}
+
+	balance.Paid.Currency = balance.Price.Currency
+
+	return &balance, nil
+}
+
+func (repo *postgresPayment) GetShootBalances(ctx context.Context) ([]model.ShootBalance, error) {
+	query := shootBalancesQuery + `
+GROUP BY s.id, c.id
+ORDER BY s.date, s.start_time`
+
+	rows, err := repo.db.Conn(ctx).Query(ctx, query)
+	if err != nil {
+		return nil, myerrors.Wrap(err, myerrors.ErrCodePaymentList, "failed to get shoot balances")
+	}
+
+	defer rows.Close()
+
+	balances := make([]model.ShootBalance, 0)
+
+	for rows.Next() {
+		var balance model.ShootBalance
+		err := rows.Scan(&balance.ShootId, &balance.ClientId, &balance.ClientFirstName,
+			&balance.ClientLastName, &balance.ShootDate,
+			&balance.Price.Amount, &balance.Price.Currency, &balance.Paid.Amount)
+		if err != nil {
+			return nil, myerrors.Wrap(err, myerrors.ErrCodePaymentList, "failed to get shoot balance")
+		}
+		balance.Paid.Currency = balance.Price.Currency
+		balances = append(balances, balance)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, myerrors.Wrap(err, myerrors.ErrCodeDBSelect, "error during rows iteration")
+	}
+
+	return balances, nil
+}
+
+func (repo *postgresPayment) GetClientBalances(ctx context.Context) ([]model.ClientBalance, error) {
+	query := `
+SELECT c.id, c.first_name, c.last_name, SUM(b.price), b.currency, SUM(b.paid)
+FROM clients c
+JOIN (` + shootBalancesQuery + ` GROUP BY s.id, c.id) AS b (shoot_id, client_id, first_name, last_name, date, price, currency, paid)
+	ON b.client_id = c.id
+GROUP BY c.id, b.currency
+ORDER BY c.last_name, c.first_name, b.currency`
+
+	rows, err := repo.db.Conn(ctx).Query(ctx, query)
+	if err != nil {
+		return nil, myerrors.Wrap(err, myerrors.ErrCodePaymentList, "failed to get client balances")
+	}
+
+	defer rows.Close()
+
+	balances := make([]model.ClientBalance, 0)
+
+	for rows.Next() {
+		var balance model.ClientBalance
+		err := rows.Scan(&balance.ClientId, &balance.FirstName, &balance.LastName,
+			&balance.Price.Amount, &balance.Price.Currency, &balance.Paid.Amount)
+		if err != nil {
+			return nil, myerrors.Wrap(err, myerrors.ErrCodePaymentList, "failed to get client balance")
+		}
+		balance.Paid.Currency = balance.Price.Currency
+		balances = append(balances, balance)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, myerrors.Wrap(err, myerrors.ErrCodeDBSelect, "error during rows iteration")
+	}
+
+	return balances, nil
+}
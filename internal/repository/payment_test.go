@@ -0,0 +1,226 @@
+package repository
+
+import (
+	"context"
+	"log"
+	"testing"
+	"time"
+
+	myerrors "github.com/Coiiap5e/photographer/internal/errors"
+	model2 "github.com/Coiiap5e/photographer/internal/model"
+	"github.com/Coiiap5e/photographer/testutils"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestPaymentRepositoryTestSuit(t *testing.T) {
+	suite.Run(t, new(PaymentRepositoryTestSuit))
+}
+
+type PaymentRepositoryTestSuit struct {
+	suite.Suite
+	ctx        context.Context
+	db         *testutils.TestDB
+	repo       Payment
+	testClient *model2.Client
+	testShoot  *model2.Shoot
+}
+
+func (suite *PaymentRepositoryTestSuit) SetupSuite() {
+	suite.ctx = context.Background()
+
+	var err error
+
+	suite.db, err = testutils.CreateTestDB(suite.ctx)
+	suite.Require().NoError(err, "Failed to setup test database")
+	suite.Require().NotNil(suite.db, "TestDB should not be nil")
+	suite.Require().NotNil(suite.db.GetDB(), "DB connection should not be nil")
+
+	suite.repo = NewPayment(suite.db.GetDB())
+}
+
+func (suite *PaymentRepositoryTestSuit) SetupTest() {
+	err := suite.db.CleanTables(suite.ctx)
+	suite.Require().NoError(err)
+
+	testClient := testutils.CreateTestClient()
+	err = NewClient(suite.db.GetDB()).AddClient(suite.ctx, testClient)
+	suite.Require().NoError(err)
+	suite.testClient = testClient
+
+	testShoot := testutils.CreateTestShoot(testClient.Id)
+	err = NewShoot(suite.db.GetDB()).AddShoot(suite.ctx, testShoot)
+	suite.Require().NoError(err)
+	suite.testShoot = testShoot
+}
+
+func (suite *PaymentRepositoryTestSuit) TearDownSuite() {
+	if suite.db != nil {
+		if err := suite.db.Cleanup(suite.ctx); err != nil {
+			log.Fatalf("failed to cleanup test database: %v", err)
+		}
+	}
+}
+
+func (suite *PaymentRepositoryTestSuit) addPayment(kind model2.PaymentKind, amount int64) *model2.Payment {
+	payment := &model2.Payment{
+		ShootId: suite.testShoot.Id,
+		Kind:    kind,
+		Amount:  model2.NewMoney(amount, "RUB"),
+		PaidAt:  time.Now(),
+	}
+	suite.Require().NoError(suite.repo.AddPayment(suite.ctx, payment), "payment should be created")
+	return payment
+}
+
+func (suite *PaymentRepositoryTestSuit) TestAddPayment() {
+	suite.T().Run("should successfully add a payment", func(t *testing.T) {
+		// Given
+		payment := &model2.Payment{
+			ShootId: suite.testShoot.Id,
+			Kind:    model2.PaymentDeposit,
+			Amount:  model2.NewMoney(30000, "RUB"),
+			PaidAt:  time.Now(),
+			Notes:   "cash",
+		}
+
+		// When
+		err := suite.repo.AddPayment(suite.ctx, payment)
+
+		// Then
+		suite.NoError(err, "should not return error")
+		suite.Greater(payment.Id, 0, "should assign positive ID to payment object")
+		suite.NotZero(payment.CreatedAt, "should set creation timestamp in payment object")
+	})
+
+	suite.T().Run("should fail for unknown shoot", func(t *testing.T) {
+		// Given
+		payment := &model2.Payment{
+			ShootId: 999999,
+			Kind:    model2.PaymentDeposit,
+			Amount:  model2.NewMoney(30000, "RUB"),
+			PaidAt:  time.Now(),
+		}
+
+		// When
+		err := suite.repo.AddPayment(suite.ctx, payment)
+
+		// Then
+		suite.Error(err, "should return error")
+	})
+}
+
+func (suite *PaymentRepositoryTestSuit) TestGetPaymentsByShoot() {
+	// Given
+	deposit := suite.addPayment(model2.PaymentDeposit, 30000)
+	final := suite.addPayment(model2.PaymentFinal, 70000)
+
+	// When
+	payments, err := suite.repo.GetPaymentsByShoot(suite.ctx, suite.testShoot.Id)
+
+	// Then
+	suite.NoError(err, "should not return error")
+	suite.Require().Len(payments, 2, "should return both payments")
+	suite.Equal(deposit.Id, payments[0].Id, "should return deposit first")
+	suite.Equal(model2.PaymentDeposit, payments[0].Kind, "should retrieve payment kind")
+	suite.Equal(model2.NewMoney(30000, "RUB"), payments[0].Amount, "should retrieve payment amount")
+	suite.Equal(final.Id, payments[1].Id, "should return final payment second")
+}
+
+func (suite *PaymentRepositoryTestSuit) TestGetShootBalances() {
+	// Given
+	suite.addPayment(model2.PaymentDeposit, 50000)
+	suite.addPayment(model2.PaymentRefund, 20000)
+
+	// When
+	balances, err := suite.repo.GetShootBalances(suite.ctx)
+
+	// Then
+	suite.NoError(err, "should not return error")
+	suite.Require().Len(balances, 1, "should return one balance per shoot")
+	suite.Equal(suite.testShoot.Id, balances[0].ShootId, "should return balance of the test shoot")
+	suite.Equal(model2.NewMoney(100000, "RUB"), balances[0].Price, "should return shoot price")
+	suite.Equal(model2.NewMoney(30000, "RUB"), balances[0].Paid, "should subtract refunds from paid amount")
+	suite.Equal(model2.NewMoney(70000, "RUB"), balances[0].Due(), "should compute amount due")
+}
+
+func (suite *PaymentRepositoryTestSuit) TestGetClientBalances() {
+	// Given
+	secondShoot := testutils.CreateTestShootWithOptions(suite.testClient.Id, func(shoot *model2.Shoot) {
+		shoot.ShootDate = shoot.ShootDate.AddDate(0, 0, 1)
+	})
+	err := NewShoot(suite.db.GetDB()).AddShoot(suite.ctx, secondShoot)
+	suite.Require().NoError(err)
+
+	otherClient := testutils.CreateTestClientWithOptions(func(client *model2.Client) {
+		client.Phone = "+7(900)000-00-01"
+	})
+	err = NewClient(suite.db.GetDB()).AddClient(suite.ctx, otherClient)
+	suite.Require().NoError(err)
+
+	suite.addPayment(model2.PaymentFinal, 100000)
+
+	// When
+	balances, err := suite.repo.GetClientBalances(suite.ctx)
+
+	// Then
+	suite.NoError(err, "should not return error")
+	suite.Require().Len(balances, 1, "should skip clients without shoots")
+	suite.Equal(suite.testClient.Id, balances[0].ClientId, "should return balance of the test client")
+	suite.Equal(model2.NewMoney(200000, "RUB"), balances[0].Price, "should sum prices of all client shoots")
+	suite.Equal(model2.NewMoney(100000, "RUB"), balances[0].Paid, "should sum payments of all client shoots")
+	suite.Equal(model2.NewMoney(100000, "RUB"), balances[0].Due(), "should compute client amount due")
+}
+
+func (suite *PaymentRepositoryTestSuit) TestGetShootBalance() {
+	suite.T().Run("should return balance of a single shoot", func(t *testing.T) {
+		// Given
+		suite.addPayment(model2.PaymentDeposit, 40000)
+
+		// When
+		balance, err := suite.repo.GetShootBalance(suite.ctx, suite.testShoot.Id)
+
+		// Then
+		suite.NoError(err, "should not return error")
+		suite.Require().NotNil(balance, "should return balance object")
+		suite.Equal(model2.NewMoney(40000, "RUB"), balance.Paid, "should return paid amount")
+		suite.Equal(suite.testClient.Id, balance.ClientId, "should return client of the shoot")
+	})
+
+	suite.T().Run("should return not found for unknown shoot", func(t *testing.T) {
+		// When
+		balance, err := suite.repo.GetShootBalance(suite.ctx, 999999)
+
+		// Then
+		suite.Nil(balance, "should not return balance")
+		suite.True(myerrors.IsErrorCode(err, myerrors.ErrCodeShootNotFound), "should return not found error")
+	})
+}
+
+func (suite *PaymentRepositoryTestSuit) TestLockShootPayments() {
+	suite.T().Run("should make another transaction wait for the lock", func(t *testing.T) {
+		// Given
+		db := suite.db.GetDB()
+		locked, release, done := make(chan struct{}), make(chan struct{}), make(chan error, 1)
+		go func() {
+			done <- db.WithTx(suite.ctx, func(ctx context.Context) error {
+				err := suite.repo.LockShootPayments(ctx, suite.testShoot.Id)
+				close(locked)
+				<-release
+				return err
+			})
+		}()
+		<-locked
+
+		// When
+		ctx, cancel := context.WithTimeout(suite.ctx, 200*time.Millisecond)
+		defer cancel()
+		err := db.WithTx(ctx, func(ctx context.Context) error {
+			return suite.repo.LockShootPayments(ctx, suite.testShoot.Id)
+		})
+		close(release)
+
+		// Then
+		suite.Error(err, "should wait until the first transaction ends")
+		suite.NoError(<-done, "should lock the shoot")
+	})
+}
@@ -3,18 +3,24 @@ package repository
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/Coiiap5e/photographer/internal/database"
 	myerrors "github.com/Coiiap5e/photographer/internal/errors"
 	"github.com/Coiiap5e/photographer/internal/model"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
 type Shoot interface {
 	AddShoot(ctx context.Context, shoot *model.Shoot) error
 	DeleteShoot(ctx context.Context, id int) error
 	GetShootByID(ctx context.Context, id int) (*model.Shoot, error)
-	GetShoots(ctx context.Context) ([]model.Shoot, error)
+	GetShoots(ctx context.Context, filter model.ShootFilter) ([]model.Shoot, error)
+	UpdateShoot(ctx context.Context, id, version int, update *model.ShootUpdate) (*model.Shoot, error)
+	GetDeletedShoots(ctx context.Context) ([]model.Shoot, error)
+	RestoreShoot(ctx context.Context, id int) error
+	PurgeShoots(ctx context.Context, olderThan time.Duration) (int, error)
 }
 
 type postgresShoot struct {
@@ -25,57 +31,85 @@ func NewShoot(db *database.DB) Shoot {
 	return &postgresShoot{db: db}
 }
 
+// shootColumns selects a shoot as s joined with its client as c, in the
+// order scanShoot reads them
+const shootColumns = `
+	s.id, s.client_id, s.date, s.start_time, s.end_time,
+	s.shoot_price, s.shoot_currency, s.location, s.shoot_type,
+	s.notes, s.allow_overlap, s.created_at, s.updated_at, s.version,
+	s.deleted_at, c.first_name, c.last_name, c.phone, c.social_network_url`
+
+func scanShoot(row pgx.Row, shoot *model.Shoot) error {
+	err := row.Scan(
+		&shoot.Id, &shoot.ClientId, &shoot.ShootDate, &shoot.StartTime,
+		&shoot.EndTime, &shoot.ShootPrice.Amount, &shoot.ShootPrice.Currency, &shoot.ShootLocation,
+		&shoot.ShootType, &shoot.Notes, &shoot.AllowOverlap,
+		&shoot.CreatedAt, &shoot.UpdatedAt, &shoot.Version,
+		&shoot.DeletedAt, &shoot.Client.FirstName, &shoot.Client.LastName, &shoot.Client.Phone,
+		&shoot.Client.SocialNetworkUrl)
+	shoot.Client.Id = shoot.ClientId
+	return err
+}
+
 func (repo *postgresShoot) AddShoot(ctx context.Context, shoot *model.Shoot) error {
 	query := `
 INSERT INTO shoots
-	(client_id, date, start_time, end_time, shoot_price, location, client_first_name, client_last_name, shoot_type, notes)
+	(client_id, date, start_time, end_time, shoot_price, shoot_currency, location, shoot_type, notes, allow_overlap)
 VALUES 
     ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 RETURNING
-	id, created_at`
+	id, created_at, updated_at, version`
 
-	err := repo.db.Pool.QueryRow(ctx, query, shoot.ClientId, shoot.ShootDate,
-		shoot.StartTime, shoot.EndTime, shoot.ShootPrice, shoot.ShootLocation,
-		shoot.ClientFirstName, shoot.ClientLastName, shoot.ShootType, shoot.Notes).Scan(&shoot.Id, &shoot.CreatedAt)
+	return repo.db.WithTx(ctx, func(ctx context.Context) error {
+		tx := repo.db.Conn(ctx)
+		err := tx.QueryRow(ctx, query, shoot.ClientId, shoot.ShootDate,
+			shoot.StartTime, shoot.EndTime, shoot.ShootPrice.Amount, shoot.ShootPrice.Currency, shoot.ShootLocation,
+			shoot.ShootType, shoot.Notes, shoot.AllowOverlap).Scan(&shoot.Id, &shoot.CreatedAt, &shoot.UpdatedAt, &shoot.Version)
 
-	if err != nil {
-		return myerrors.Wrap(err, myerrors.ErrCodeShootCreate, "failed to create client")
-	}
+		if err != nil {
+			if isExclusionViolation(err) {
+				return myerrors.Wrap(err, myerrors.ErrCodeShootConflict, "shoot overlaps another shoot")
+			}
+			return myerrors.Wrap(err, myerrors.ErrCodeShootCreate, "failed to create shoot")
+		}
 
-	return nil
+		return record(ctx, tx, model.AuditShoot, shoot.Id, model.AuditCreate, nil)
+	})
 }
 
+// DeleteShoot moves the shoot to the trash
 func (repo *postgresShoot) DeleteShoot(ctx context.Context, id int) error {
-	query := `
-DELETE FROM shoots WHERE id = $1`
+	return repo.db.WithTx(ctx, func(ctx context.Context) error {
+		tx := repo.db.Conn(ctx)
+		before, err := snapshot(ctx, tx, model.AuditShoot, "t.id = $1 AND t.deleted_at IS NULL", id)
+		if err != nil {
+			return err
+		}
 
-	result, err := repo.db.Pool.Exec(ctx, query, id)
-	if err != nil {
-		return myerrors.Wrap(err, myerrors.ErrCodeShootDelete, "failed to delete shoot")
-	}
+		if len(before) == 0 {
+			return myerrors.New(myerrors.ErrCodeShootNotFound, "shoot not found")
+		}
 
-	if result.RowsAffected() == 0 {
-		return myerrors.New(myerrors.ErrCodeShootNotFound, "shoot not found")
-	}
+		_, err = tx.Exec(ctx, `
+UPDATE shoots SET deleted_at = CURRENT_TIMESTAMP
+WHERE id = $1`, id)
+		if err != nil {
+			return myerrors.Wrap(err, myerrors.ErrCodeShootDelete, "failed to delete shoot")
+		}
 
-	return nil
+		return record(ctx, tx, model.AuditShoot, id, model.AuditDelete, before[0].row)
+	})
 }
 
 func (repo *postgresShoot) GetShootByID(ctx context.Context, id int) (*model.Shoot, error) {
 	query := `
-SELECT 
-	id, client_id, date, start_time, end_time, 
-	shoot_price, location, client_first_name, 
-	client_last_name, shoot_type, notes
-FROM shoots
-WHERE id = $1`
+SELECT ` + shootColumns + `
+FROM shoots s
+JOIN clients c ON c.id = s.client_id
+WHERE s.id = $1 AND s.deleted_at IS NULL`
 
 	var shoot model.Shoot
-	err := repo.db.Pool.QueryRow(ctx, query, id).Scan(
-		&shoot.Id, &shoot.ClientId, &shoot.ShootDate, &shoot.StartTime,
-		&shoot.EndTime, &shoot.ShootPrice, &shoot.ShootLocation,
-		&shoot.ClientFirstName, &shoot.ClientLastName, &shoot.ShootType,
-		&shoot.Notes)
+	err := scanShoot(repo.db.Conn(ctx).QueryRow(ctx, query, id), &shoot)
 
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -87,15 +121,44 @@ WHERE id = $1`
 	return &shoot, nil
 }
 
-func (repo *postgresShoot) GetShoots(ctx context.Context) ([]model.Shoot, error) {
+// GetShoots returns the shoots matching filter in its sort order, at most
+// filter.Limit of them if it is set
+func (repo *postgresShoot) GetShoots(ctx context.Context, filter model.ShootFilter) ([]model.Shoot, error) {
+	var b queryBuilder
+	b.where("s.deleted_at IS NULL")
+
+	if !filter.From.IsZero() {
+		b.where("s.date >= " + b.arg(filter.From))
+	}
+	if !filter.To.IsZero() {
+		b.where("s.date <= " + b.arg(filter.To))
+	}
+	if filter.ClientId != 0 {
+		b.where("s.client_id = " + b.arg(filter.ClientId))
+	}
+	if filter.ShootType != "" {
+		b.where("lower(s.shoot_type) = lower(" + b.arg(filter.ShootType) + ")")
+	}
+	if filter.Location != "" {
+		b.where("s.location ILIKE '%' || " + b.arg(escapeLike(filter.Location)) + " || '%'")
+	}
+	if filter.MinPrice != nil {
+		b.where("s.shoot_currency = " + b.arg(filter.MinPrice.Currency) +
+			" AND s.shoot_price >= " + b.arg(filter.MinPrice.Amount))
+	}
+	if filter.MaxPrice != nil {
+		b.where("s.shoot_currency = " + b.arg(filter.MaxPrice.Currency) +
+			" AND s.shoot_price <= " + b.arg(filter.MaxPrice.Amount))
+	}
+
+	page := b.page(shootSortKeys(filter.Sort, filter.After), filter.After != nil, filter.Desc, filter.Limit)
+
 	query := `
-SELECT 
-    id, client_id, date, start_time, end_time, 
-    shoot_price, location, client_first_name, 
-    client_last_name, shoot_type, notes, created_at
-FROM shoots`
+SELECT ` + shootColumns + `
+FROM shoots s
+JOIN clients c ON c.id = s.client_id` + b.whereClause() + page
 
-	rows, err := repo.db.Pool.Query(ctx, query)
+	rows, err := repo.db.Conn(ctx).Query(ctx, query, b.args...)
 	if err != nil {
 		return nil, myerrors.Wrap(err, myerrors.ErrCodeDBSelect, "failed to get shoots")
 	}
@@ -106,12 +169,109 @@ FROM shoots`
 
 	for rows.Next() {
 		var shoot model.Shoot
-		err := rows.Scan(
-			&shoot.Id, &shoot.ClientId, &shoot.ShootDate, &shoot.StartTime,
-			&shoot.EndTime, &shoot.ShootPrice, &shoot.ShootLocation,
-			&shoot.ClientFirstName, &shoot.ClientLastName, &shoot.ShootType,
-			&shoot.Notes, &shoot.CreatedAt)
+		if err := scanShoot(rows, &shoot); err != nil {
+			return nil, myerrors.Wrap(err, myerrors.ErrCodeDBSelect, "failed to get shoot")
+		}
+		shoots = append(shoots, shoot)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, myerrors.Wrap(err, myerrors.ErrCodeDBSelect, "error during rows iteration")
+	}
+
+	return shoots, nil
+}
+
+// UpdateShoot changes the non-nil fields of update if the stored version
+// still equals version.
+func (repo *postgresShoot) UpdateShoot(ctx context.Context, id, version int,
+	update *model.ShootUpdate) (*model.Shoot, error) {
+	query := `
+WITH s AS (
+	UPDATE shoots SET
+		client_id = COALESCE($3, client_id),
+		date = COALESCE($4, date),
+		start_time = COALESCE($5, start_time),
+		end_time = COALESCE($6, end_time),
+		shoot_price = COALESCE($7, shoot_price),
+		location = COALESCE($8, location),
+		shoot_type = COALESCE($9, shoot_type),
+		notes = COALESCE($10, notes),
+		allow_overlap = COALESCE($11, allow_overlap),
+		shoot_currency = COALESCE($12, shoot_currency),
+		updated_at = CURRENT_TIMESTAMP,
+		version = version + 1
+	WHERE id = $1 AND version = $2 AND deleted_at IS NULL
+	RETURNING *
+)
+SELECT ` + shootColumns + `
+FROM s
+JOIN clients c ON c.id = s.client_id`
+
+	var priceAmount *int64
+	var priceCurrency *string
+	if update.ShootPrice != nil {
+		priceAmount, priceCurrency = &update.ShootPrice.Amount, &update.ShootPrice.Currency
+	}
+
+	var shoot model.Shoot
+	err := repo.db.WithTx(ctx, func(ctx context.Context) error {
+		tx := repo.db.Conn(ctx)
+		before, err := snapshot(ctx, tx, model.AuditShoot, "t.id = $1 AND t.deleted_at IS NULL", id)
 		if err != nil {
+			return err
+		}
+
+		if len(before) == 0 {
+			return myerrors.New(myerrors.ErrCodeShootNotFound, "shoot not found")
+		}
+
+		err = scanShoot(tx.QueryRow(ctx, query, id, version, update.ClientId,
+			update.ShootDate, update.StartTime, update.EndTime, priceAmount,
+			update.ShootLocation, update.ShootType, update.Notes, update.AllowOverlap,
+			priceCurrency), &shoot)
+
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return myerrors.New(myerrors.ErrCodeVersionConflict, "shoot was changed by someone else")
+			}
+			if isExclusionViolation(err) {
+				return myerrors.Wrap(err, myerrors.ErrCodeShootConflict, "shoot overlaps another shoot")
+			}
+			return myerrors.Wrap(err, myerrors.ErrCodeShootUpdate, "failed to update shoot")
+		}
+
+		return record(ctx, tx, model.AuditShoot, id, model.AuditUpdate, before[0].row)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &shoot, nil
+}
+
+// GetDeletedShoots returns the shoots in the trash, including those deleted
+// with their client, most recently deleted first
+func (repo *postgresShoot) GetDeletedShoots(ctx context.Context) ([]model.Shoot, error) {
+	query := `
+SELECT ` + shootColumns + `
+FROM shoots s
+JOIN clients c ON c.id = s.client_id
+WHERE s.deleted_at IS NOT NULL
+ORDER BY s.deleted_at DESC, s.id DESC`
+
+	rows, err := repo.db.Conn(ctx).Query(ctx, query)
+	if err != nil {
+		return nil, myerrors.Wrap(err, myerrors.ErrCodeDBSelect, "failed to get deleted shoots")
+	}
+
+	defer rows.Close()
+
+	shoots := make([]model.Shoot, 0)
+
+	for rows.Next() {
+		var shoot model.Shoot
+		if err := scanShoot(rows, &shoot); err != nil {
 			return nil, myerrors.Wrap(err, myerrors.ErrCodeDBSelect, "failed to get shoot")
 		}
 		shoots = append(shoots, shoot)
@@ -123,3 +283,88 @@ FROM shoots`
 
 	return shoots, nil
 }
+
+// RestoreShoot takes the shoot out of the trash. A shoot of a deleted client
+// is restored with the client instead.
+func (repo *postgresShoot) RestoreShoot(ctx context.Context, id int) error {
+	query := `
+UPDATE shoots s SET deleted_at = NULL
+FROM clients c
+WHERE s.id = $1 AND c.id = s.client_id AND c.deleted_at IS NULL`
+
+	return repo.db.WithTx(ctx, func(ctx context.Context) error {
+		tx := repo.db.Conn(ctx)
+		before, err := snapshot(ctx, tx, model.AuditShoot, "t.id = $1 AND t.deleted_at IS NOT NULL", id)
+		if err != nil {
+			return err
+		}
+
+		if len(before) == 0 {
+			return myerrors.New(myerrors.ErrCodeShootNotFound, "shoot not found in trash")
+		}
+
+		result, err := tx.Exec(ctx, query, id)
+		if err != nil {
+			if isExclusionViolation(err) {
+				return myerrors.Wrap(err, myerrors.ErrCodeShootConflict, "shoot overlaps another shoot")
+			}
+			return myerrors.Wrap(err, myerrors.ErrCodeShootUpdate, "failed to restore shoot")
+		}
+
+		if result.RowsAffected() == 0 {
+			return myerrors.New(myerrors.ErrCodeValidation, "shoot's client is deleted, restore the client instead")
+		}
+
+		return record(ctx, tx, model.AuditShoot, id, model.AuditRestore, before[0].row)
+	})
+}
+
+// PurgeShoots permanently removes shoots deleted more than olderThan ago with
+// their payments. It returns how many were removed.
+func (repo *postgresShoot) PurgeShoots(ctx context.Context, olderThan time.Duration) (int, error) {
+	var purged int
+	err := repo.db.WithTx(ctx, func(ctx context.Context) error {
+		tx := repo.db.Conn(ctx)
+		shoots, err := snapshot(ctx, tx, model.AuditShoot,
+			"t.deleted_at < LOCALTIMESTAMP - $1::interval", olderThan)
+		if err != nil {
+			return err
+		}
+
+		ids := make([]int, len(shoots))
+		for i, shoot := range shoots {
+			ids[i] = shoot.id
+		}
+
+		// the payments go with the shoots by ON DELETE CASCADE
+		payments, err := snapshot(ctx, tx, model.AuditPayment, "t.shoot_id = ANY($1)", ids)
+		if err != nil {
+			return err
+		}
+
+		result, err := tx.Exec(ctx, `
+DELETE FROM shoots WHERE id = ANY($1)`, ids)
+		if err != nil {
+			return myerrors.Wrap(err, myerrors.ErrCodeShootDelete, "failed to purge shoots")
+		}
+		purged = int(result.RowsAffected())
+
+		if err := recordAll(ctx, tx, model.AuditPayment, model.AuditPurge, payments); err != nil {
+			return err
+		}
+		return recordAll(ctx, tx, model.AuditShoot, model.AuditPurge, shoots)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return purged, nil
+}
+
+// exclusionViolation is the SQLSTATE raised by the shoots_no_overlap constraint
+const exclusionViolation = "23P01"
+
+func isExclusionViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == exclusionViolation
+}
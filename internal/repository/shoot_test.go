@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	myerrors "github.com/Coiiap5e/photographer/internal/errors"
 	model2 "github.com/Coiiap5e/photographer/internal/model"
 	"github.com/Coiiap5e/photographer/testutils"
 	"github.com/stretchr/testify/suite"
@@ -161,7 +162,7 @@ func (suite *ShootRepositoryTestSuit) TestGetShoots() {
 	suite.NoError(err, "shoot should be created")
 
 	// When
-	allShoots, err := suite.repo.GetShoots(suite.ctx)
+	allShoots, err := suite.repo.GetShoots(suite.ctx, model2.ShootFilter{})
 
 	// Then
 	suite.NoError(err, "should not return error")
@@ -213,3 +214,308 @@ func (suite *ShootRepositoryTestSuit) TestGetShoots() {
 	suite.Equal(testShoot3.Notes, shoot3.Notes, "notes should match")
 
 }
+
+func (suite *ShootRepositoryTestSuit) TestGetShootsFiltered() {
+	// Given
+	dates := []int{10, 20, 30, 40}
+	shoots := make([]*model2.Shoot, len(dates))
+	for i, days := range dates {
+		shoots[i] = testutils.CreateTestShootWithOptions(suite.testClient.Id, func(shoot *model2.Shoot) {
+			shoot.ShootDate = time.Now().AddDate(0, 0, days)
+			shoot.ShootPrice = model2.NewMoney(int64(400000-i*100000), "RUB")
+		})
+		shoots[i].ShootLocation = []string{"Park 100%", "photo studio Aurora", "beacon", "Studio North"}[i]
+		shoots[i].ShootType = []string{"family", "Family", "love story", "family"}[i]
+		err := suite.repo.AddShoot(suite.ctx, shoots[i])
+		suite.Require().NoError(err, "precondition: shoot should be created")
+	}
+
+	ids := func(shoots []model2.Shoot) []int {
+		result := make([]int, len(shoots))
+		for i, shoot := range shoots {
+			result[i] = shoot.Id
+		}
+		return result
+	}
+
+	suite.T().Run("should order by date by default", func(t *testing.T) {
+		// When
+		found, err := suite.repo.GetShoots(suite.ctx, model2.ShootFilter{})
+
+		// Then
+		suite.NoError(err, "should not return error")
+		suite.Equal([]int{shoots[0].Id, shoots[1].Id, shoots[2].Id, shoots[3].Id}, ids(found),
+			"should return shoots by date")
+	})
+
+	suite.T().Run("should filter by date range", func(t *testing.T) {
+		// When
+		found, err := suite.repo.GetShoots(suite.ctx, model2.ShootFilter{
+			From: time.Now().AddDate(0, 0, 15), To: time.Now().AddDate(0, 0, 35)})
+
+		// Then
+		suite.NoError(err, "should not return error")
+		suite.Equal([]int{shoots[1].Id, shoots[2].Id}, ids(found), "should return shoots within the range")
+	})
+
+	suite.T().Run("should filter by type ignoring case and by location substring", func(t *testing.T) {
+		// When
+		found, err := suite.repo.GetShoots(suite.ctx, model2.ShootFilter{ShootType: "FAMILY", Location: "studio"})
+
+		// Then
+		suite.NoError(err, "should not return error")
+		suite.Equal([]int{shoots[1].Id, shoots[3].Id}, ids(found), "should match type and location")
+	})
+
+	suite.T().Run("should not treat percent sign in location as wildcard", func(t *testing.T) {
+		// When
+		found, err := suite.repo.GetShoots(suite.ctx, model2.ShootFilter{Location: "0%"})
+
+		// Then
+		suite.NoError(err, "should not return error")
+		suite.Equal([]int{shoots[0].Id}, ids(found), "should match literal percent sign")
+	})
+
+	suite.T().Run("should filter by price range and client", func(t *testing.T) {
+		// Given
+		minPrice := model2.NewMoney(200000, "RUB")
+		maxPrice := model2.NewMoney(300000, "RUB")
+
+		// When
+		found, err := suite.repo.GetShoots(suite.ctx, model2.ShootFilter{
+			ClientId: suite.testClient.Id, MinPrice: &minPrice, MaxPrice: &maxPrice})
+
+		// Then
+		suite.NoError(err, "should not return error")
+		suite.Equal([]int{shoots[1].Id, shoots[2].Id}, ids(found), "should return shoots within price range")
+	})
+
+	suite.T().Run("should page by price descending", func(t *testing.T) {
+		// Given
+		filter := model2.ShootFilter{Sort: model2.ShootSortPrice, Desc: true, Limit: 3}
+
+		// When
+		first, err := suite.repo.GetShoots(suite.ctx, filter)
+		suite.Require().NoError(err, "should not return error")
+		filter.After = &first[len(first)-1]
+		second, err := suite.repo.GetShoots(suite.ctx, filter)
+
+		// Then
+		suite.NoError(err, "should not return error")
+		suite.Equal([]int{shoots[0].Id, shoots[1].Id, shoots[2].Id}, ids(first), "should return first page")
+		suite.Equal([]int{shoots[3].Id}, ids(second), "should continue after the last shoot")
+	})
+}
+
+func (suite *ShootRepositoryTestSuit) TestShootClient() {
+	// Given
+	testShoot := testutils.CreateTestShoot(suite.testClient.Id)
+	err := suite.repo.AddShoot(suite.ctx, testShoot)
+	suite.Require().NoError(err, "precondition: shoot should be created")
+
+	newLastName := "Petrov"
+	_, err = NewClient(suite.db.GetDB()).UpdateClient(suite.ctx, suite.testClient.Id,
+		suite.testClient.Version, &model2.ClientUpdate{LastName: &newLastName})
+	suite.Require().NoError(err, "precondition: client should be renamed")
+
+	// When
+	shoot, err := suite.repo.GetShootByID(suite.ctx, testShoot.Id)
+
+	// Then
+	suite.NoError(err, "should not return error")
+	suite.Equal(suite.testClient.Id, shoot.Client.Id, "should fill client ID")
+	suite.Equal(suite.testClient.FirstName, shoot.Client.FirstName, "should join client first name")
+	suite.Equal(newLastName, shoot.Client.LastName, "should show the current client name")
+	suite.Equal(suite.testClient.Phone, shoot.Client.Phone, "should join client phone")
+}
+
+func (suite *ShootRepositoryTestSuit) TestUpdateShoot() {
+	suite.T().Run("should update only given fields", func(t *testing.T) {
+		// Given
+		testShoot := testutils.CreateTestShoot(suite.testClient.Id)
+		err := suite.repo.AddShoot(suite.ctx, testShoot)
+		suite.Require().NoError(err, "precondition: shoot should be created")
+
+		newDate := time.Now().AddDate(0, 0, 45)
+		newLocation := "photo studio Aurora"
+
+		// When
+		updatedShoot, err := suite.repo.UpdateShoot(suite.ctx, testShoot.Id, testShoot.Version,
+			&model2.ShootUpdate{ShootDate: &newDate, ShootLocation: &newLocation})
+
+		// Then
+		suite.NoError(err, "should not return error")
+		suite.Equal(newDate.Format("2006-01-02"), updatedShoot.ShootDate.Format("2006-01-02"),
+			"should update shoot date")
+		suite.Equal(newLocation, updatedShoot.ShootLocation, "should update location")
+		suite.Equal(testShoot.ShootType, updatedShoot.ShootType, "should keep shoot type")
+		suite.Equal(testShoot.StartTime.Format("15:04"), updatedShoot.StartTime.Format("15:04"),
+			"should keep start time")
+		suite.Equal(testShoot.Version+1, updatedShoot.Version, "should increment version")
+	})
+	suite.T().Run("should return conflict error for stale version", func(t *testing.T) {
+		// Given
+		testShoot := testutils.CreateTestShoot(suite.testClient.Id)
+		err := suite.repo.AddShoot(suite.ctx, testShoot)
+		suite.Require().NoError(err, "precondition: shoot should be created")
+
+		notes := "bring a reflector"
+		_, err = suite.repo.UpdateShoot(suite.ctx, testShoot.Id, testShoot.Version,
+			&model2.ShootUpdate{Notes: &notes})
+		suite.Require().NoError(err, "precondition: shoot should be updated")
+
+		// When
+		_, err = suite.repo.UpdateShoot(suite.ctx, testShoot.Id, testShoot.Version,
+			&model2.ShootUpdate{Notes: &notes})
+
+		// Then
+		suite.True(myerrors.IsErrorCode(err, myerrors.ErrCodeVersionConflict),
+			"should return version conflict error")
+	})
+}
+
+func (suite *ShootRepositoryTestSuit) TestAddShootOverlap() {
+	suite.T().Run("should reject overlapping shoot", func(t *testing.T) {
+		// Given
+		testShoot := testutils.CreateTestShoot(suite.testClient.Id)
+		err := suite.repo.AddShoot(suite.ctx, testShoot)
+		suite.Require().NoError(err, "precondition: shoot should be created")
+
+		overlapping := testutils.CreateTestShootWithOptions(suite.testClient.Id, func(shoot *model2.Shoot) {
+			shoot.ShootDate = testShoot.ShootDate
+			shoot.StartTime = time.Date(0, 0, 0, 15, 30, 0, 0, time.UTC)
+			shoot.EndTime = time.Date(0, 0, 0, 17, 0, 0, 0, time.UTC)
+		})
+
+		// When
+		err = suite.repo.AddShoot(suite.ctx, overlapping)
+
+		// Then
+		suite.True(myerrors.IsErrorCode(err, myerrors.ErrCodeShootConflict),
+			"should return shoot conflict error")
+	})
+	suite.T().Run("should store overlapping shoot when allowed", func(t *testing.T) {
+		// Given
+		testShoot := testutils.CreateTestShootWithOptions(suite.testClient.Id, func(shoot *model2.Shoot) {
+			shoot.ShootDate = time.Now().AddDate(0, 0, 90)
+		})
+		err := suite.repo.AddShoot(suite.ctx, testShoot)
+		suite.Require().NoError(err, "precondition: shoot should be created")
+
+		overlapping := testutils.CreateTestShootWithOptions(suite.testClient.Id, func(shoot *model2.Shoot) {
+			shoot.ShootDate = testShoot.ShootDate
+			shoot.AllowOverlap = true
+		})
+
+		// When
+		err = suite.repo.AddShoot(suite.ctx, overlapping)
+
+		// Then
+		suite.NoError(err, "should not return error")
+	})
+	suite.T().Run("should not store a shoot without times", func(t *testing.T) {
+		for _, times := range []string{"NULL, '11:00'", "'10:00', NULL"} {
+			// When
+			_, err := suite.db.GetDB().Pool.Exec(suite.ctx,
+				"INSERT INTO shoots (client_id, date, start_time, end_time) VALUES ($1, CURRENT_DATE, "+times+")",
+				suite.testClient.Id)
+
+			// Then
+			suite.ErrorContains(err, "violates not-null constraint",
+				"should reject %s instead of checking an unbounded range", times)
+		}
+	})
+}
+
+func (suite *ShootRepositoryTestSuit) TestRestoreShoot() {
+	suite.T().Run("should bring deleted shoot back", func(t *testing.T) {
+		// Given
+		testShoot := testutils.CreateTestShoot(suite.testClient.Id)
+		err := suite.repo.AddShoot(suite.ctx, testShoot)
+		suite.Require().NoError(err, "precondition: shoot should be created")
+		err = suite.repo.DeleteShoot(suite.ctx, testShoot.Id)
+		suite.Require().NoError(err, "precondition: shoot should be deleted")
+
+		deleted, err := suite.repo.GetDeletedShoots(suite.ctx)
+		suite.Require().NoError(err)
+		suite.Require().Len(deleted, 1, "precondition: shoot should be in trash")
+		suite.NotNil(deleted[0].DeletedAt, "should set deletion time")
+
+		// When
+		err = suite.repo.RestoreShoot(suite.ctx, testShoot.Id)
+
+		// Then
+		suite.NoError(err, "should not return error")
+		_, err = suite.repo.GetShootByID(suite.ctx, testShoot.Id)
+		suite.NoError(err, "should find restored shoot")
+	})
+	suite.T().Run("should free the time of deleted shoot", func(t *testing.T) {
+		// Given
+		testShoot := testutils.CreateTestShootWithOptions(suite.testClient.Id, func(shoot *model2.Shoot) {
+			shoot.ShootDate = time.Now().AddDate(0, 0, 120)
+		})
+		err := suite.repo.AddShoot(suite.ctx, testShoot)
+		suite.Require().NoError(err, "precondition: shoot should be created")
+		err = suite.repo.DeleteShoot(suite.ctx, testShoot.Id)
+		suite.Require().NoError(err, "precondition: shoot should be deleted")
+
+		sameTime := testutils.CreateTestShootWithOptions(suite.testClient.Id, func(shoot *model2.Shoot) {
+			shoot.ShootDate = testShoot.ShootDate
+		})
+
+		// When
+		err = suite.repo.AddShoot(suite.ctx, sameTime)
+
+		// Then
+		suite.NoError(err, "should book the time of a deleted shoot")
+		suite.True(myerrors.IsErrorCode(suite.repo.RestoreShoot(suite.ctx, testShoot.Id),
+			myerrors.ErrCodeShootConflict), "should not restore shoot into a booked time")
+	})
+	suite.T().Run("should not restore shoot of deleted client", func(t *testing.T) {
+		// Given
+		testShoot := testutils.CreateTestShootWithOptions(suite.testClient.Id, func(shoot *model2.Shoot) {
+			shoot.ShootDate = time.Now().AddDate(0, 0, 150)
+		})
+		err := suite.repo.AddShoot(suite.ctx, testShoot)
+		suite.Require().NoError(err, "precondition: shoot should be created")
+		err = NewClient(suite.db.GetDB()).DeleteClient(suite.ctx, suite.testClient.Id)
+		suite.Require().NoError(err, "precondition: client should be deleted")
+
+		// When
+		err = suite.repo.RestoreShoot(suite.ctx, testShoot.Id)
+
+		// Then
+		suite.True(myerrors.IsErrorCode(err, myerrors.ErrCodeValidation),
+			"should ask to restore the client")
+	})
+}
+
+func (suite *ShootRepositoryTestSuit) TestPurgeShoots() {
+	// Given
+	kept := testutils.CreateTestShoot(suite.testClient.Id)
+	err := suite.repo.AddShoot(suite.ctx, kept)
+	suite.Require().NoError(err, "precondition: shoot should be created")
+
+	deleted := testutils.CreateTestShootWithOptions(suite.testClient.Id, func(shoot *model2.Shoot) {
+		shoot.ShootDate = time.Now().AddDate(0, 0, 60)
+	})
+	err = suite.repo.AddShoot(suite.ctx, deleted)
+	suite.Require().NoError(err, "precondition: shoot should be created")
+	err = suite.repo.DeleteShoot(suite.ctx, deleted.Id)
+	suite.Require().NoError(err, "precondition: shoot should be deleted")
+
+	// When
+	recent, err := suite.repo.PurgeShoots(suite.ctx, time.Hour)
+	suite.Require().NoError(err)
+	purged, err := suite.repo.PurgeShoots(suite.ctx, 0)
+
+	// Then
+	suite.NoError(err, "should not return error")
+	suite.Zero(recent, "should keep shoots deleted within retention")
+	suite.Equal(1, purged, "should remove only the deleted shoot")
+	trash, err := suite.repo.GetDeletedShoots(suite.ctx)
+	suite.NoError(err)
+	suite.Empty(trash, "should empty the trash")
+	_, err = suite.repo.GetShootByID(suite.ctx, kept.Id)
+	suite.NoError(err, "should keep shoots not deleted")
+}
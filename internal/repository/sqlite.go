@@ -0,0 +1,230 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Coiiap5e/photographer/internal/audit"
+	"github.com/Coiiap5e/photographer/internal/database"
+	myerrors "github.com/Coiiap5e/photographer/internal/errors"
+	"github.com/Coiiap5e/photographer/internal/model"
+)
+
+// sqliteTimestamp is the layout of the timestamps SQLite stores as text. It
+// has a fixed width, so the text sorts as the time does.
+const sqliteTimestamp = "2006-01-02 15:04:05.000000"
+
+func sqliteNow() string {
+	return memoryNow().Format(sqliteTimestamp)
+}
+
+// sqliteTime scans a text column in layout, NULL leaves the time zero.
+// Times of day are read on 2000-01-01 as pgx reads a TIME column.
+type sqliteTime struct {
+	time   *time.Time
+	layout string
+}
+
+func (t sqliteTime) Scan(value any) error {
+	text, ok, err := sqliteText(value)
+	if err != nil || !ok {
+		return err
+	}
+
+	parsed, err := time.Parse(t.layout, text)
+	if err != nil {
+		return err
+	}
+	if t.layout == time.TimeOnly {
+		parsed = memoryClock(parsed)
+	}
+
+	*t.time = parsed
+	return nil
+}
+
+// sqliteNullTime scans a nullable timestamp column
+type sqliteNullTime struct {
+	time **time.Time
+}
+
+func (t sqliteNullTime) Scan(value any) error {
+	text, ok, err := sqliteText(value)
+	if err != nil || !ok {
+		*t.time = nil
+		return err
+	}
+
+	parsed, err := time.Parse(sqliteTimestamp, text)
+	if err != nil {
+		return err
+	}
+
+	*t.time = &parsed
+	return nil
+}
+
+// sqliteText returns the text of a scanned value, ok is false for NULL
+func sqliteText(value any) (string, bool, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", false, nil
+	case string:
+		return v, true, nil
+	case []byte:
+		return string(v), true, nil
+	default:
+		return "", false, fmt.Errorf("cannot scan %T into time", value)
+	}
+}
+
+// sqliteSortKeys turns the time values of keys into the text SQLite stores
+// them as
+func sqliteSortKeys(keys []sortKey) []sortKey {
+	for i, key := range keys {
+		value, ok := key.value.(time.Time)
+		if !ok {
+			continue
+		}
+
+		switch {
+		case strings.HasSuffix(key.column, "date"):
+			keys[i].value = value.Format(time.DateOnly)
+		case strings.HasSuffix(key.column, "_time"):
+			keys[i].value = value.Format(time.TimeOnly)
+		default:
+			keys[i].value = value.UTC().Format(sqliteTimestamp)
+		}
+	}
+	return keys
+}
+
+// sqliteRow returns the row of entity with id as JSON, nil if there is none
+func sqliteRow(ctx context.Context, tx database.SQLiteQuerier, entity model.AuditEntity,
+	id int) ([]byte, error) {
+	switch entity {
+	case model.AuditClient:
+		var client model.Client
+		err := scanSQLiteClient(tx.QueryRowContext(ctx, `
+SELECT `+sqliteClientColumns+`
+FROM clients
+WHERE id = $1`, id), &client)
+		return sqliteJSON(err, func() []byte { return clientJSON(client) })
+	case model.AuditShoot:
+		var shoot model.Shoot
+		err := scanSQLiteShoot(tx.QueryRowContext(ctx, `
+SELECT `+sqliteShootColumns+`
+FROM shoots s
+JOIN clients c ON c.id = s.client_id
+WHERE s.id = $1`, id), &shoot)
+		return sqliteJSON(err, func() []byte { return shootJSON(shoot) })
+	default:
+		var payment model.Payment
+		err := scanSQLitePayment(tx.QueryRowContext(ctx, `
+SELECT `+sqlitePaymentColumns+`
+FROM payments
+WHERE id = $1`, id), &payment)
+		return sqliteJSON(err, func() []byte { return paymentJSON(payment) })
+	}
+}
+
+func sqliteJSON(err error, row func() []byte) ([]byte, error) {
+	if isNoRows(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, myerrors.Wrap(err, myerrors.ErrCodeDBSelect, "failed to read row for audit")
+	}
+	return row(), nil
+}
+
+// sqliteRecord writes an audit entry for the row of entity with id the same
+// way as record
+func sqliteRecord(ctx context.Context, tx database.SQLiteQuerier, entity model.AuditEntity, id int,
+	action model.AuditAction, before []byte) error {
+	after, err := sqliteRow(ctx, tx, entity, id)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+INSERT INTO audit_log
+	(entity, entity_id, action, actor, before, after, created_at)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7)`,
+		entity, id, action, audit.Actor(ctx), sqliteNullText(before), sqliteNullText(after), sqliteNow())
+	if err != nil {
+		return myerrors.Wrap(err, myerrors.ErrCodeDBInsert, "failed to write audit log")
+	}
+
+	return nil
+}
+
+// sqliteNullText stores JSON as text, nil as NULL
+func sqliteNullText(data []byte) any {
+	if data == nil {
+		return nil
+	}
+	return string(data)
+}
+
+func isNoRows(err error) bool {
+	return errors.Is(err, sql.ErrNoRows)
+}
+
+// sqliteIds returns the ids of the rows query selects. The rows are read to
+// the end, so the only connection is free again for the next query.
+func sqliteIds(ctx context.Context, tx database.SQLiteQuerier, query string, args ...any) ([]int, error) {
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, myerrors.Wrap(err, myerrors.ErrCodeDBSelect, "failed to read rows")
+	}
+
+	defer rows.Close()
+
+	var ids []int
+
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, myerrors.Wrap(err, myerrors.ErrCodeDBSelect, "failed to read row")
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, myerrors.Wrap(err, myerrors.ErrCodeDBSelect, "error during rows iteration")
+	}
+
+	return ids, nil
+}
+
+// sqliteSnapshot returns the rows of entity with ids as they are before a
+// change
+func sqliteSnapshot(ctx context.Context, tx database.SQLiteQuerier, entity model.AuditEntity,
+	ids []int) ([]rowSnapshot, error) {
+	snapshots := make([]rowSnapshot, 0, len(ids))
+	for _, id := range ids {
+		row, err := sqliteRow(ctx, tx, entity, id)
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, rowSnapshot{id: id, row: row})
+	}
+	return snapshots, nil
+}
+
+// sqliteRecordAll writes an audit entry for every row in before
+func sqliteRecordAll(ctx context.Context, tx database.SQLiteQuerier, entity model.AuditEntity,
+	action model.AuditAction, before []rowSnapshot) error {
+	for _, s := range before {
+		if err := sqliteRecord(ctx, tx, entity, s.id, action, s.row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
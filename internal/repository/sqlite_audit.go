@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Coiiap5e/photographer/internal/database"
+	myerrors "github.com/Coiiap5e/photographer/internal/errors"
+	"github.com/Coiiap5e/photographer/internal/model"
+)
+
+type sqliteAudit struct {
+	db *database.SQLite
+}
+
+// NewSQLiteAudit returns the audit log kept in SQLite
+func NewSQLiteAudit(db *database.SQLite) Audit {
+	return &sqliteAudit{db: db}
+}
+
+// GetHistory returns the changes of a client or shoot, oldest first. The
+// history of a client includes its shoots and that of a shoot its payments.
+func (repo *sqliteAudit) GetHistory(ctx context.Context, entity model.AuditEntity,
+	id int) ([]model.AuditEntry, error) {
+	condition := "(entity = $1 AND entity_id = $2)"
+	switch entity {
+	case model.AuditClient:
+		condition += ` OR (entity = 'shoot'
+	AND $2 IN (json_extract(before, '$.client_id'), json_extract(after, '$.client_id')))`
+	case model.AuditShoot:
+		condition += ` OR (entity = 'payment'
+	AND $2 IN (json_extract(before, '$.shoot_id'), json_extract(after, '$.shoot_id')))`
+	}
+
+	query := `
+SELECT id, entity, entity_id, action, before, after, actor, created_at
+FROM audit_log
+WHERE ` + condition + `
+ORDER BY id`
+
+	rows, err := repo.db.Conn(ctx).QueryContext(ctx, query, entity, id)
+	if err != nil {
+		return nil, myerrors.Wrap(err, myerrors.ErrCodeDBSelect, "failed to get audit log")
+	}
+
+	defer rows.Close()
+
+	entries := make([]model.AuditEntry, 0)
+
+	for rows.Next() {
+		var entry model.AuditEntry
+		var before, after []byte
+		err := rows.Scan(&entry.Id, &entry.Entity, &entry.EntityId, &entry.Action,
+			&before, &after, &entry.Actor, sqliteTime{&entry.CreatedAt, sqliteTimestamp})
+		if err != nil {
+			return nil, myerrors.Wrap(err, myerrors.ErrCodeDBSelect, "failed to get audit entry")
+		}
+		entry.Before, entry.After = before, after
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, myerrors.Wrap(err, myerrors.ErrCodeDBSelect, "error during rows iteration")
+	}
+
+	return entries, nil
+}
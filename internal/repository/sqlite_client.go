@@ -0,0 +1,389 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Coiiap5e/photographer/internal/database"
+	myerrors "github.com/Coiiap5e/photographer/internal/errors"
+	"github.com/Coiiap5e/photographer/internal/model"
+)
+
+type sqliteClient struct {
+	db *database.SQLite
+}
+
+// NewSQLiteClient returns a client repository keeping its data in SQLite
+func NewSQLiteClient(db *database.SQLite) Client {
+	return &sqliteClient{db: db}
+}
+
+// sqliteScanner is a row or rows of database/sql
+type sqliteScanner interface {
+	Scan(dest ...any) error
+}
+
+// sqliteClientColumns selects a client in the order scanSQLiteClient reads
+// them
+const sqliteClientColumns = `id, first_name, last_name, COALESCE(phone, ''),
+	COALESCE(social_network_url, ''), created_at, updated_at, version, deleted_at`
+
+func scanSQLiteClient(row sqliteScanner, client *model.Client) error {
+	return row.Scan(
+		&client.Id, &client.FirstName, &client.LastName,
+		&client.Phone, &client.SocialNetworkUrl,
+		sqliteTime{&client.CreatedAt, sqliteTimestamp}, sqliteTime{&client.UpdatedAt, sqliteTimestamp},
+		&client.Version, sqliteNullTime{&client.DeletedAt})
+}
+
+// sqliteClients reads the clients query selects
+func (repo *sqliteClient) sqliteClients(ctx context.Context, query string, args ...any) ([]model.Client, error) {
+	rows, err := repo.db.Conn(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, myerrors.Wrap(err, myerrors.ErrCodeDBSelect, "failed to get clients")
+	}
+
+	defer rows.Close()
+
+	clients := make([]model.Client, 0)
+
+	for rows.Next() {
+		var client model.Client
+		if err := scanSQLiteClient(rows, &client); err != nil {
+			return nil, myerrors.Wrap(err, myerrors.ErrCodeDBSelect, "failed to get client")
+		}
+		clients = append(clients, client)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, myerrors.Wrap(err, myerrors.ErrCodeDBSelect, "error during rows iteration")
+	}
+
+	return clients, nil
+}
+
+func insertSQLiteClient(ctx context.Context, tx database.SQLiteQuerier, client *model.Client) error {
+	now := sqliteNow()
+	err := tx.QueryRowContext(ctx, `
+INSERT INTO clients
+    (first_name, last_name, phone, social_network_url, created_at, updated_at)
+VALUES
+    ($1, $2, $3, $4, $5, $5)
+RETURNING
+    id, created_at, updated_at, version`,
+		client.FirstName, client.LastName, client.Phone, client.SocialNetworkUrl, now).
+		Scan(&client.Id, sqliteTime{&client.CreatedAt, sqliteTimestamp},
+			sqliteTime{&client.UpdatedAt, sqliteTimestamp}, &client.Version)
+
+	if err != nil {
+		return myerrors.Wrap(err, myerrors.ErrCodeClientCreate, "failed to create client")
+	}
+
+	return sqliteRecord(ctx, tx, model.AuditClient, client.Id, model.AuditCreate, nil)
+}
+
+func (repo *sqliteClient) AddClient(ctx context.Context, client *model.Client) error {
+	return repo.db.WithTx(ctx, func(ctx context.Context) error {
+		return insertSQLiteClient(ctx, repo.db.Conn(ctx), client)
+	})
+}
+
+// AddClients inserts all clients in one transaction, either every client is
+// added or none is
+func (repo *sqliteClient) AddClients(ctx context.Context, clients []*model.Client) error {
+	return repo.db.WithTx(ctx, func(ctx context.Context) error {
+		tx := repo.db.Conn(ctx)
+		for _, client := range clients {
+			if err := insertSQLiteClient(ctx, tx, client); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteClient moves the client and its shoots to the trash with the same
+// deletion time
+func (repo *sqliteClient) DeleteClient(ctx context.Context, id int) error {
+	return repo.db.WithTx(ctx, func(ctx context.Context) error {
+		tx := repo.db.Conn(ctx)
+		clientIds, err := sqliteIds(ctx, tx, `
+SELECT id FROM clients WHERE id = $1 AND deleted_at IS NULL`, id)
+		if err != nil {
+			return err
+		}
+
+		if len(clientIds) == 0 {
+			return myerrors.New(myerrors.ErrCodeClientNotFound, "client not found")
+		}
+
+		shootIds, err := sqliteIds(ctx, tx, `
+SELECT id FROM shoots WHERE client_id = $1 AND deleted_at IS NULL ORDER BY id`, id)
+		if err != nil {
+			return err
+		}
+
+		client, err := sqliteSnapshot(ctx, tx, model.AuditClient, clientIds)
+		if err != nil {
+			return err
+		}
+
+		shoots, err := sqliteSnapshot(ctx, tx, model.AuditShoot, shootIds)
+		if err != nil {
+			return err
+		}
+
+		now := sqliteNow()
+		_, err = tx.ExecContext(ctx, `
+UPDATE clients SET deleted_at = $2
+WHERE id = $1`, id, now)
+		if err != nil {
+			return myerrors.Wrap(err, myerrors.ErrCodeClientDelete, "failed to delete client")
+		}
+
+		_, err = tx.ExecContext(ctx, `
+UPDATE shoots SET deleted_at = $2
+WHERE client_id = $1 AND deleted_at IS NULL`, id, now)
+		if err != nil {
+			return myerrors.Wrap(err, myerrors.ErrCodeClientDelete, "failed to delete client shoots")
+		}
+
+		if err := sqliteRecordAll(ctx, tx, model.AuditClient, model.AuditDelete, client); err != nil {
+			return err
+		}
+		return sqliteRecordAll(ctx, tx, model.AuditShoot, model.AuditDelete, shoots)
+	})
+}
+
+func (repo *sqliteClient) GetClientByID(ctx context.Context, id int) (*model.Client, error) {
+	query := `
+SELECT ` + sqliteClientColumns + `
+FROM clients
+WHERE id = $1 AND deleted_at IS NULL`
+
+	var client model.Client
+	err := scanSQLiteClient(repo.db.Conn(ctx).QueryRowContext(ctx, query, id), &client)
+
+	if err != nil {
+		if isNoRows(err) {
+			return nil, myerrors.New(myerrors.ErrCodeClientNotFound, "client not found")
+		}
+		return nil, myerrors.Wrap(err, myerrors.ErrCodeDBSelect, "failed to get client")
+	}
+
+	return &client, nil
+}
+
+// GetClients returns clients in filter's sort order, at most filter.Limit
+// of them if it is set
+func (repo *sqliteClient) GetClients(ctx context.Context, filter model.ClientFilter) ([]model.Client, error) {
+	var b queryBuilder
+	b.where("deleted_at IS NULL")
+
+	keys := sqliteSortKeys(clientSortKeys(filter.Sort, filter.After))
+	page := b.page(keys, filter.After != nil, filter.Desc, filter.Limit)
+
+	query := `
+SELECT ` + sqliteClientColumns + `
+FROM clients` + b.whereClause() + page
+
+	return repo.sqliteClients(ctx, query, b.args...)
+}
+
+// SearchClients returns up to limit clients matching every word of query.
+// SQLite has neither trigrams nor regular expressions, so the clients are
+// matched and ranked as in the memory repository.
+func (repo *sqliteClient) SearchClients(ctx context.Context, query string, limit int) ([]model.Client, error) {
+	clients, err := repo.sqliteClients(ctx, `
+SELECT `+sqliteClientColumns+`
+FROM clients
+WHERE deleted_at IS NULL`)
+	if err != nil {
+		return nil, err
+	}
+
+	return searchClients(clients, query, limit), nil
+}
+
+// UpdateClient changes the non-nil fields of update if the stored version
+// still equals version.
+func (repo *sqliteClient) UpdateClient(ctx context.Context, id, version int,
+	update *model.ClientUpdate) (*model.Client, error) {
+	query := `
+UPDATE clients SET
+	first_name = COALESCE($3, first_name),
+	last_name = COALESCE($4, last_name),
+	phone = COALESCE($5, phone),
+	social_network_url = COALESCE($6, social_network_url),
+	updated_at = $7,
+	version = version + 1
+WHERE id = $1 AND version = $2
+RETURNING ` + sqliteClientColumns
+
+	var client model.Client
+	err := repo.db.WithTx(ctx, func(ctx context.Context) error {
+		tx := repo.db.Conn(ctx)
+		ids, err := sqliteIds(ctx, tx, `
+SELECT id FROM clients WHERE id = $1 AND deleted_at IS NULL`, id)
+		if err != nil {
+			return err
+		}
+
+		if len(ids) == 0 {
+			return myerrors.New(myerrors.ErrCodeClientNotFound, "client not found")
+		}
+
+		before, err := sqliteRow(ctx, tx, model.AuditClient, id)
+		if err != nil {
+			return err
+		}
+
+		err = scanSQLiteClient(tx.QueryRowContext(ctx, query, id, version, update.FirstName,
+			update.LastName, update.Phone, update.SocialNetworkUrl, sqliteNow()), &client)
+
+		if err != nil {
+			if isNoRows(err) {
+				return myerrors.New(myerrors.ErrCodeVersionConflict, "client was changed by someone else")
+			}
+			return myerrors.Wrap(err, myerrors.ErrCodeClientUpdate, "failed to update client")
+		}
+
+		return sqliteRecord(ctx, tx, model.AuditClient, id, model.AuditUpdate, before)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &client, nil
+}
+
+// GetDeletedClients returns the clients in the trash, most recently deleted
+// first
+func (repo *sqliteClient) GetDeletedClients(ctx context.Context) ([]model.Client, error) {
+	return repo.sqliteClients(ctx, `
+SELECT `+sqliteClientColumns+`
+FROM clients
+WHERE deleted_at IS NOT NULL
+ORDER BY deleted_at DESC, id DESC`)
+}
+
+// RestoreClient takes the client out of the trash together with the shoots
+// that were deleted with it. Shoots deleted on their own stay in the trash.
+func (repo *sqliteClient) RestoreClient(ctx context.Context, id int) error {
+	return repo.db.WithTx(ctx, func(ctx context.Context) error {
+		tx := repo.db.Conn(ctx)
+		clientIds, err := sqliteIds(ctx, tx, `
+SELECT id FROM clients WHERE id = $1 AND deleted_at IS NOT NULL`, id)
+		if err != nil {
+			return err
+		}
+
+		if len(clientIds) == 0 {
+			return myerrors.New(myerrors.ErrCodeClientNotFound, "client not found in trash")
+		}
+
+		shootIds, err := sqliteIds(ctx, tx, `
+SELECT id FROM shoots
+WHERE client_id = $1 AND deleted_at = (SELECT deleted_at FROM clients WHERE id = $1)
+ORDER BY id`, id)
+		if err != nil {
+			return err
+		}
+
+		client, err := sqliteSnapshot(ctx, tx, model.AuditClient, clientIds)
+		if err != nil {
+			return err
+		}
+
+		shoots, err := sqliteSnapshot(ctx, tx, model.AuditShoot, shootIds)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `
+UPDATE shoots SET deleted_at = NULL
+WHERE id IN (SELECT value FROM json_each($1))`, sqliteIdList(shootIds))
+		if err != nil {
+			return myerrors.Wrap(err, myerrors.ErrCodeClientUpdate, "failed to restore client shoots")
+		}
+
+		for _, shootId := range shootIds {
+			overlap, err := sqliteOverlaps(ctx, tx, shootId)
+			if err != nil {
+				return err
+			}
+			if overlap {
+				return myerrors.New(myerrors.ErrCodeShootConflict,
+					"client's shoots overlap shoots booked since deletion")
+			}
+		}
+
+		_, err = tx.ExecContext(ctx, `
+UPDATE clients SET deleted_at = NULL
+WHERE id = $1`, id)
+		if err != nil {
+			return myerrors.Wrap(err, myerrors.ErrCodeClientUpdate, "failed to restore client")
+		}
+
+		if err := sqliteRecordAll(ctx, tx, model.AuditClient, model.AuditRestore, client); err != nil {
+			return err
+		}
+		return sqliteRecordAll(ctx, tx, model.AuditShoot, model.AuditRestore, shoots)
+	})
+}
+
+// PurgeClients permanently removes clients deleted more than olderThan ago
+// with all their shoots and payments. It returns how many were removed.
+func (repo *sqliteClient) PurgeClients(ctx context.Context, olderThan time.Duration) (int, error) {
+	var purged int
+	err := repo.db.WithTx(ctx, func(ctx context.Context) error {
+		tx := repo.db.Conn(ctx)
+		cutoff := memoryNow().Add(-olderThan).Format(sqliteTimestamp)
+		ids, err := sqliteIds(ctx, tx, `
+SELECT id FROM clients WHERE deleted_at < $1 ORDER BY id`, cutoff)
+		if err != nil {
+			return err
+		}
+
+		clients, err := sqliteSnapshot(ctx, tx, model.AuditClient, ids)
+		if err != nil {
+			return err
+		}
+
+		// the shoots and payments go with the clients by ON DELETE CASCADE
+		shootIds, err := sqliteIds(ctx, tx, `
+SELECT id FROM shoots WHERE client_id IN (SELECT value FROM json_each($1)) ORDER BY id`, sqliteIdList(ids))
+		if err != nil {
+			return err
+		}
+		shoots, payments, err := sqliteShootsWithPayments(ctx, tx, shootIds)
+		if err != nil {
+			return err
+		}
+
+		result, err := tx.ExecContext(ctx, `
+DELETE FROM clients WHERE id IN (SELECT value FROM json_each($1))`, sqliteIdList(ids))
+		if err != nil {
+			return myerrors.Wrap(err, myerrors.ErrCodeClientDelete, "failed to purge clients")
+		}
+
+		removed, err := result.RowsAffected()
+		if err != nil {
+			return myerrors.Wrap(err, myerrors.ErrCodeClientDelete, "failed to purge clients")
+		}
+		purged = int(removed)
+
+		if err := sqliteRecordAll(ctx, tx, model.AuditPayment, model.AuditPurge, payments); err != nil {
+			return err
+		}
+		if err := sqliteRecordAll(ctx, tx, model.AuditShoot, model.AuditPurge, shoots); err != nil {
+			return err
+		}
+		return sqliteRecordAll(ctx, tx, model.AuditClient, model.AuditPurge, clients)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return purged, nil
+}
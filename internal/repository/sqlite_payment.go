@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Coiiap5e/photographer/internal/database"
+	myerrors "github.com/Coiiap5e/photographer/internal/errors"
+	"github.com/Coiiap5e/photographer/internal/model"
+)
+
+type sqlitePayment struct {
+	db *database.SQLite
+}
+
+// NewSQLitePayment returns a payment repository keeping its data in SQLite
+func NewSQLitePayment(db *database.SQLite) Payment {
+	return &sqlitePayment{db: db}
+}
+
+// sqlitePaymentColumns selects a payment in the order scanSQLitePayment
+// reads them
+const sqlitePaymentColumns = `id, shoot_id, kind, amount, currency, paid_at, COALESCE(notes, ''), created_at`
+
+func scanSQLitePayment(row sqliteScanner, payment *model.Payment) error {
+	return row.Scan(&payment.Id, &payment.ShootId, &payment.Kind,
+		&payment.Amount.Amount, &payment.Amount.Currency, sqliteTime{&payment.PaidAt, time.DateOnly},
+		&payment.Notes, sqliteTime{&payment.CreatedAt, sqliteTimestamp})
+}
+
+func scanSQLiteShootBalance(row sqliteScanner, balance *model.ShootBalance) error {
+	err := row.Scan(&balance.ShootId, &balance.ClientId, &balance.ClientFirstName,
+		&balance.ClientLastName, sqliteTime{&balance.ShootDate, time.DateOnly},
+		&balance.Price.Amount, &balance.Price.Currency, &balance.Paid.Amount)
+	balance.Paid.Currency = balance.Price.Currency
+	return err
+}
+
+// LockShootPayments does nothing, the database has a single connection, so
+// its transactions already run one at a time
+func (repo *sqlitePayment) LockShootPayments(context.Context, int) error {
+	return nil
+}
+
+func (repo *sqlitePayment) AddPayment(ctx context.Context, payment *model.Payment) error {
+	query := `
+INSERT INTO payments
+	(shoot_id, kind, amount, currency, paid_at, notes, created_at)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7)
+RETURNING
+	id, created_at`
+
+	return repo.db.WithTx(ctx, func(ctx context.Context) error {
+		tx := repo.db.Conn(ctx)
+		err := tx.QueryRowContext(ctx, query, payment.ShootId, payment.Kind,
+			payment.Amount.Amount, payment.Amount.Currency, payment.PaidAt.Format(time.DateOnly),
+			payment.Notes, sqliteNow()).Scan(&payment.Id, sqliteTime{&payment.CreatedAt, sqliteTimestamp})
+
+		if err != nil {
+			return myerrors.Wrap(err, myerrors.ErrCodePaymentCreate, "failed to create payment")
+		}
+
+		return sqliteRecord(ctx, tx, model.AuditPayment, payment.Id, model.AuditCreate, nil)
+	})
+}
+
+func (repo *sqlitePayment) GetPaymentsByShoot(ctx context.Context, shootID int) ([]model.Payment, error) {
+	query := `
+SELECT ` + sqlitePaymentColumns + `
+FROM payments
+WHERE shoot_id = $1
+ORDER BY paid_at, id`
+
+	rows, err := repo.db.Conn(ctx).QueryContext(ctx, query, shootID)
+	if err != nil {
+		return nil, myerrors.Wrap(err, myerrors.ErrCodePaymentList, "failed to get payments")
+	}
+
+	defer rows.Close()
+
+	payments := make([]model.Payment, 0)
+
+	for rows.Next() {
+		var payment model.Payment
+		if err := scanSQLitePayment(rows, &payment); err != nil {
+			return nil, myerrors.Wrap(err, myerrors.ErrCodePaymentList, "failed to get payment")
+		}
+		payments = append(payments, payment)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, myerrors.Wrap(err, myerrors.ErrCodeDBSelect, "error during rows iteration")
+	}
+
+	return payments, nil
+}
+
+func (repo *sqlitePayment) GetShootBalance(ctx context.Context, shootID int) (*model.ShootBalance, error) {
+	query := shootBalancesQuery + `
+  AND s.id = $1
+GROUP BY s.id, c.id`
+
+	var balance model.ShootBalance
+	err := scanSQLiteShootBalance(repo.db.Conn(ctx).QueryRowContext(ctx, query, shootID), &balance)
+
+	if err != nil {
+		if isNoRows(err) {
+			return nil, myerrors.New(myerrors.ErrCodeShootNotFound, "shoot not found")
+		}
+		return nil, myerrors.Wrap(err, myerrors.ErrCodePaymentList, "failed to get shoot balance")
+	}
+
+	return &balance, nil
+}
+
+func (repo *sqlitePayment) GetShootBalances(ctx context.Context) ([]model.ShootBalance, error) {
+	query := shootBalancesQuery + `
+GROUP BY s.id, c.id
+ORDER BY s.date, s.start_time`
+
+	rows, err := repo.db.Conn(ctx).QueryContext(ctx, query)
+	if err != nil {
+		return nil, myerrors.Wrap(err, myerrors.ErrCodePaymentList, "failed to get shoot balances")
+	}
+
+	defer rows.Close()
+
+	balances := make([]model.ShootBalance, 0)
+
+	for rows.Next() {
+		var balance model.ShootBalance
+		if err := scanSQLiteShootBalance(rows, &balance); err != nil {
+			return nil, myerrors.Wrap(err, myerrors.ErrCodePaymentList, "failed to get shoot balance")
+		}
+		balances = append(balances, balance)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, myerrors.Wrap(err, myerrors.ErrCodeDBSelect, "error during rows iteration")
+	}
+
+	return balances, nil
+}
+
+func (repo *sqlitePayment) GetClientBalances(ctx context.Context) ([]model.ClientBalance, error) {
+	// SQLite names the columns of a subquery only in a WITH clause
+	query := `
+WITH b (shoot_id, client_id, first_name, last_name, date, price, currency, paid) AS (` +
+		shootBalancesQuery + ` GROUP BY s.id, c.id)
+SELECT c.id, c.first_name, c.last_name, SUM(b.price), b.currency, SUM(b.paid)
+FROM clients c
+JOIN b ON b.client_id = c.id
+GROUP BY c.id, b.currency
+ORDER BY c.last_name, c.first_name, c.id, b.currency`
+
+	rows, err := repo.db.Conn(ctx).QueryContext(ctx, query)
+	if err != nil {
+		return nil, myerrors.Wrap(err, myerrors.ErrCodePaymentList, "failed to get client balances")
+	}
+
+	defer rows.Close()
+
+	balances := make([]model.ClientBalance, 0)
+
+	for rows.Next() {
+		var balance model.ClientBalance
+		err := rows.Scan(&balance.ClientId, &balance.FirstName, &balance.LastName,
+			&balance.Price.Amount, &balance.Price.Currency, &balance.Paid.Amount)
+		if err != nil {
+			return nil, myerrors.Wrap(err, myerrors.ErrCodePaymentList, "failed to get client balance")
+		}
+		balance.Paid.Currency = balance.Price.Currency
+		balances = append(balances, balance)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, myerrors.Wrap(err, myerrors.ErrCodeDBSelect, "error during rows iteration")
+	}
+
+	return balances, nil
+}
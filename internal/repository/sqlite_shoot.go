@@ -0,0 +1,443 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/Coiiap5e/photographer/internal/database"
+	myerrors "github.com/Coiiap5e/photographer/internal/errors"
+	"github.com/Coiiap5e/photographer/internal/model"
+)
+
+type sqliteShoot struct {
+	db *database.SQLite
+}
+
+// NewSQLiteShoot returns a shoot repository keeping its data in SQLite
+func NewSQLiteShoot(db *database.SQLite) Shoot {
+	return &sqliteShoot{db: db}
+}
+
+// sqliteShootColumns selects a shoot as s joined with its client as c, in
+// the order scanSQLiteShoot reads them
+const sqliteShootColumns = `
+	s.id, s.client_id, s.date, s.start_time, s.end_time,
+	COALESCE(s.shoot_price, 0), s.shoot_currency, COALESCE(s.location, ''), COALESCE(s.shoot_type, ''),
+	COALESCE(s.notes, ''), s.allow_overlap, s.created_at, s.updated_at, s.version,
+	s.deleted_at, c.first_name, c.last_name, COALESCE(c.phone, ''), COALESCE(c.social_network_url, '')`
+
+func scanSQLiteShoot(row sqliteScanner, shoot *model.Shoot) error {
+	err := row.Scan(
+		&shoot.Id, &shoot.ClientId, sqliteTime{&shoot.ShootDate, time.DateOnly},
+		sqliteTime{&shoot.StartTime, time.TimeOnly}, sqliteTime{&shoot.EndTime, time.TimeOnly},
+		&shoot.ShootPrice.Amount, &shoot.ShootPrice.Currency, &shoot.ShootLocation,
+		&shoot.ShootType, &shoot.Notes, &shoot.AllowOverlap,
+		sqliteTime{&shoot.CreatedAt, sqliteTimestamp}, sqliteTime{&shoot.UpdatedAt, sqliteTimestamp},
+		&shoot.Version, sqliteNullTime{&shoot.DeletedAt}, &shoot.Client.FirstName, &shoot.Client.LastName,
+		&shoot.Client.Phone, &shoot.Client.SocialNetworkUrl)
+	shoot.Client.Id = shoot.ClientId
+	return err
+}
+
+// sqliteOptional formats t in layout, nil stays NULL
+func sqliteOptional(t *time.Time, layout string) any {
+	if t == nil {
+		return nil
+	}
+	return t.Format(layout)
+}
+
+// sqliteShootsWithPayments snapshots the shoots with ids and their payments
+// before they are purged, the payments go with the shoots by ON DELETE
+// CASCADE
+func sqliteShootsWithPayments(ctx context.Context, tx database.SQLiteQuerier,
+	ids []int) ([]rowSnapshot, []rowSnapshot, error) {
+	shoots, err := sqliteSnapshot(ctx, tx, model.AuditShoot, ids)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	paymentIds, err := sqliteIds(ctx, tx, `
+SELECT id FROM payments WHERE shoot_id IN (SELECT value FROM json_each($1)) ORDER BY id`, sqliteIdList(ids))
+	if err != nil {
+		return nil, nil, err
+	}
+	payments, err := sqliteSnapshot(ctx, tx, model.AuditPayment, paymentIds)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return shoots, payments, nil
+}
+
+// sqliteIdList passes ids to json_each, SQLite has no arrays
+func sqliteIdList(ids []int) string {
+	if ids == nil {
+		ids = []int{}
+	}
+	list, _ := json.Marshal(ids)
+	return string(list)
+}
+
+// sqliteShoots reads the shoots query selects
+func sqliteShoots(ctx context.Context, tx database.SQLiteQuerier, query string, args ...any) ([]model.Shoot, error) {
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, myerrors.Wrap(err, myerrors.ErrCodeDBSelect, "failed to get shoots")
+	}
+
+	defer rows.Close()
+
+	shoots := make([]model.Shoot, 0)
+
+	for rows.Next() {
+		var shoot model.Shoot
+		if err := scanSQLiteShoot(rows, &shoot); err != nil {
+			return nil, myerrors.Wrap(err, myerrors.ErrCodeDBSelect, "failed to get shoot")
+		}
+		shoots = append(shoots, shoot)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, myerrors.Wrap(err, myerrors.ErrCodeDBSelect, "error during rows iteration")
+	}
+
+	return shoots, nil
+}
+
+// sqliteOverlaps reports whether the stored shoot with id clashes with
+// another one the way the shoots_no_overlap constraint of Postgres sees it.
+// A shoot may run past midnight, so the days around it are checked too.
+func sqliteOverlaps(ctx context.Context, tx database.SQLiteQuerier, id int) (bool, error) {
+	shoots, err := sqliteShoots(ctx, tx, `
+SELECT `+sqliteShootColumns+`
+FROM shoots s
+JOIN clients c ON c.id = s.client_id
+WHERE s.id = $1`, id)
+	if err != nil || len(shoots) == 0 {
+		return false, err
+	}
+
+	shoot := shoots[0]
+	others, err := sqliteShoots(ctx, tx, `
+SELECT `+sqliteShootColumns+`
+FROM shoots s
+JOIN clients c ON c.id = s.client_id
+WHERE s.id <> $1 AND s.deleted_at IS NULL AND s.allow_overlap = 0
+  AND s.date BETWEEN $2 AND $3`, id,
+		shoot.ShootDate.AddDate(0, 0, -1).Format(time.DateOnly),
+		shoot.ShootDate.AddDate(0, 0, 1).Format(time.DateOnly))
+	if err != nil {
+		return false, err
+	}
+
+	return overlapsAny(shoot, others), nil
+}
+
+func (repo *sqliteShoot) AddShoot(ctx context.Context, shoot *model.Shoot) error {
+	query := `
+INSERT INTO shoots
+	(client_id, date, start_time, end_time, shoot_price, shoot_currency, location, shoot_type, notes,
+	 allow_overlap, created_at, updated_at)
+VALUES
+    ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $11)
+RETURNING
+	id, created_at, updated_at, version`
+
+	return repo.db.WithTx(ctx, func(ctx context.Context) error {
+		tx := repo.db.Conn(ctx)
+		err := tx.QueryRowContext(ctx, query, shoot.ClientId, shoot.ShootDate.Format(time.DateOnly),
+			shoot.StartTime.Format(time.TimeOnly), shoot.EndTime.Format(time.TimeOnly),
+			shoot.ShootPrice.Amount, shoot.ShootPrice.Currency, shoot.ShootLocation,
+			shoot.ShootType, shoot.Notes, shoot.AllowOverlap, sqliteNow()).
+			Scan(&shoot.Id, sqliteTime{&shoot.CreatedAt, sqliteTimestamp},
+				sqliteTime{&shoot.UpdatedAt, sqliteTimestamp}, &shoot.Version)
+
+		if err != nil {
+			return myerrors.Wrap(err, myerrors.ErrCodeShootCreate, "failed to create shoot")
+		}
+
+		overlap, err := sqliteOverlaps(ctx, tx, shoot.Id)
+		if err != nil {
+			return err
+		}
+		if overlap {
+			return myerrors.New(myerrors.ErrCodeShootConflict, "shoot overlaps another shoot")
+		}
+
+		return sqliteRecord(ctx, tx, model.AuditShoot, shoot.Id, model.AuditCreate, nil)
+	})
+}
+
+// DeleteShoot moves the shoot to the trash
+func (repo *sqliteShoot) DeleteShoot(ctx context.Context, id int) error {
+	return repo.db.WithTx(ctx, func(ctx context.Context) error {
+		tx := repo.db.Conn(ctx)
+		ids, err := sqliteIds(ctx, tx, `
+SELECT id FROM shoots WHERE id = $1 AND deleted_at IS NULL`, id)
+		if err != nil {
+			return err
+		}
+
+		if len(ids) == 0 {
+			return myerrors.New(myerrors.ErrCodeShootNotFound, "shoot not found")
+		}
+
+		before, err := sqliteRow(ctx, tx, model.AuditShoot, id)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `
+UPDATE shoots SET deleted_at = $2
+WHERE id = $1`, id, sqliteNow())
+		if err != nil {
+			return myerrors.Wrap(err, myerrors.ErrCodeShootDelete, "failed to delete shoot")
+		}
+
+		return sqliteRecord(ctx, tx, model.AuditShoot, id, model.AuditDelete, before)
+	})
+}
+
+func (repo *sqliteShoot) GetShootByID(ctx context.Context, id int) (*model.Shoot, error) {
+	query := `
+SELECT ` + sqliteShootColumns + `
+FROM shoots s
+JOIN clients c ON c.id = s.client_id
+WHERE s.id = $1 AND s.deleted_at IS NULL`
+
+	var shoot model.Shoot
+	err := scanSQLiteShoot(repo.db.Conn(ctx).QueryRowContext(ctx, query, id), &shoot)
+
+	if err != nil {
+		if isNoRows(err) {
+			return nil, myerrors.New(myerrors.ErrCodeShootNotFound, "shoot not found")
+		}
+		return nil, myerrors.Wrap(err, myerrors.ErrCodeDBSelect, "failed to get shoot")
+	}
+
+	return &shoot, nil
+}
+
+// GetShoots returns the shoots matching filter in its sort order, at most
+// filter.Limit of them if it is set
+func (repo *sqliteShoot) GetShoots(ctx context.Context, filter model.ShootFilter) ([]model.Shoot, error) {
+	var b queryBuilder
+	b.where("s.deleted_at IS NULL")
+
+	if !filter.From.IsZero() {
+		b.where("s.date >= " + b.arg(filter.From.Format(time.DateOnly)))
+	}
+	if !filter.To.IsZero() {
+		b.where("s.date <= " + b.arg(filter.To.Format(time.DateOnly)))
+	}
+	if filter.ClientId != 0 {
+		b.where("s.client_id = " + b.arg(filter.ClientId))
+	}
+	if filter.ShootType != "" {
+		b.where("unicode_lower(s.shoot_type) = unicode_lower(" + b.arg(filter.ShootType) + ")")
+	}
+	if filter.Location != "" {
+		b.where("instr(unicode_lower(s.location), unicode_lower(" + b.arg(filter.Location) + ")) > 0")
+	}
+	if filter.MinPrice != nil {
+		b.where("s.shoot_currency = " + b.arg(filter.MinPrice.Currency) +
+			" AND s.shoot_price >= " + b.arg(filter.MinPrice.Amount))
+	}
+	if filter.MaxPrice != nil {
+		b.where("s.shoot_currency = " + b.arg(filter.MaxPrice.Currency) +
+			" AND s.shoot_price <= " + b.arg(filter.MaxPrice.Amount))
+	}
+
+	keys := sqliteSortKeys(shootSortKeys(filter.Sort, filter.After))
+	page := b.page(keys, filter.After != nil, filter.Desc, filter.Limit)
+
+	query := `
+SELECT ` + sqliteShootColumns + `
+FROM shoots s
+JOIN clients c ON c.id = s.client_id` + b.whereClause() + page
+
+	return sqliteShoots(ctx, repo.db.Conn(ctx), query, b.args...)
+}
+
+// UpdateShoot changes the non-nil fields of update if the stored version
+// still equals version.
+func (repo *sqliteShoot) UpdateShoot(ctx context.Context, id, version int,
+	update *model.ShootUpdate) (*model.Shoot, error) {
+	query := `
+UPDATE shoots SET
+	client_id = COALESCE($3, client_id),
+	date = COALESCE($4, date),
+	start_time = COALESCE($5, start_time),
+	end_time = COALESCE($6, end_time),
+	shoot_price = COALESCE($7, shoot_price),
+	location = COALESCE($8, location),
+	shoot_type = COALESCE($9, shoot_type),
+	notes = COALESCE($10, notes),
+	allow_overlap = COALESCE($11, allow_overlap),
+	shoot_currency = COALESCE($12, shoot_currency),
+	updated_at = $13,
+	version = version + 1
+WHERE id = $1 AND version = $2 AND deleted_at IS NULL
+RETURNING id`
+
+	var priceAmount *int64
+	var priceCurrency *string
+	if update.ShootPrice != nil {
+		priceAmount, priceCurrency = &update.ShootPrice.Amount, &update.ShootPrice.Currency
+	}
+
+	var shoot *model.Shoot
+	err := repo.db.WithTx(ctx, func(ctx context.Context) error {
+		tx := repo.db.Conn(ctx)
+		ids, err := sqliteIds(ctx, tx, `
+SELECT id FROM shoots WHERE id = $1 AND deleted_at IS NULL`, id)
+		if err != nil {
+			return err
+		}
+
+		if len(ids) == 0 {
+			return myerrors.New(myerrors.ErrCodeShootNotFound, "shoot not found")
+		}
+
+		before, err := sqliteRow(ctx, tx, model.AuditShoot, id)
+		if err != nil {
+			return err
+		}
+
+		err = tx.QueryRowContext(ctx, query, id, version, update.ClientId,
+			sqliteOptional(update.ShootDate, time.DateOnly), sqliteOptional(update.StartTime, time.TimeOnly),
+			sqliteOptional(update.EndTime, time.TimeOnly), priceAmount, update.ShootLocation,
+			update.ShootType, update.Notes, update.AllowOverlap, priceCurrency, sqliteNow()).Scan(&id)
+
+		if err != nil {
+			if isNoRows(err) {
+				return myerrors.New(myerrors.ErrCodeVersionConflict, "shoot was changed by someone else")
+			}
+			return myerrors.Wrap(err, myerrors.ErrCodeShootUpdate, "failed to update shoot")
+		}
+
+		overlap, err := sqliteOverlaps(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if overlap {
+			return myerrors.New(myerrors.ErrCodeShootConflict, "shoot overlaps another shoot")
+		}
+
+		if shoot, err = repo.GetShootByID(ctx, id); err != nil {
+			return err
+		}
+
+		return sqliteRecord(ctx, tx, model.AuditShoot, id, model.AuditUpdate, before)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return shoot, nil
+}
+
+// GetDeletedShoots returns the shoots in the trash, including those deleted
+// with their client, most recently deleted first
+func (repo *sqliteShoot) GetDeletedShoots(ctx context.Context) ([]model.Shoot, error) {
+	return sqliteShoots(ctx, repo.db.Conn(ctx), `
+SELECT `+sqliteShootColumns+`
+FROM shoots s
+JOIN clients c ON c.id = s.client_id
+WHERE s.deleted_at IS NOT NULL
+ORDER BY s.deleted_at DESC, s.id DESC`)
+}
+
+// RestoreShoot takes the shoot out of the trash. A shoot of a deleted client
+// is restored with the client instead.
+func (repo *sqliteShoot) RestoreShoot(ctx context.Context, id int) error {
+	query := `
+UPDATE shoots SET deleted_at = NULL
+WHERE id = $1 AND client_id IN (SELECT id FROM clients WHERE deleted_at IS NULL)`
+
+	return repo.db.WithTx(ctx, func(ctx context.Context) error {
+		tx := repo.db.Conn(ctx)
+		ids, err := sqliteIds(ctx, tx, `
+SELECT id FROM shoots WHERE id = $1 AND deleted_at IS NOT NULL`, id)
+		if err != nil {
+			return err
+		}
+
+		if len(ids) == 0 {
+			return myerrors.New(myerrors.ErrCodeShootNotFound, "shoot not found in trash")
+		}
+
+		before, err := sqliteRow(ctx, tx, model.AuditShoot, id)
+		if err != nil {
+			return err
+		}
+
+		result, err := tx.ExecContext(ctx, query, id)
+		if err != nil {
+			return myerrors.Wrap(err, myerrors.ErrCodeShootUpdate, "failed to restore shoot")
+		}
+
+		restored, err := result.RowsAffected()
+		if err != nil {
+			return myerrors.Wrap(err, myerrors.ErrCodeShootUpdate, "failed to restore shoot")
+		}
+
+		if restored == 0 {
+			return myerrors.New(myerrors.ErrCodeValidation, "shoot's client is deleted, restore the client instead")
+		}
+
+		overlap, err := sqliteOverlaps(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if overlap {
+			return myerrors.New(myerrors.ErrCodeShootConflict, "shoot overlaps another shoot")
+		}
+
+		return sqliteRecord(ctx, tx, model.AuditShoot, id, model.AuditRestore, before)
+	})
+}
+
+// PurgeShoots permanently removes shoots deleted more than olderThan ago with
+// their payments. It returns how many were removed.
+func (repo *sqliteShoot) PurgeShoots(ctx context.Context, olderThan time.Duration) (int, error) {
+	var purged int
+	err := repo.db.WithTx(ctx, func(ctx context.Context) error {
+		tx := repo.db.Conn(ctx)
+		cutoff := memoryNow().Add(-olderThan).Format(sqliteTimestamp)
+		ids, err := sqliteIds(ctx, tx, `
+SELECT id FROM shoots WHERE deleted_at < $1 ORDER BY id`, cutoff)
+		if err != nil {
+			return err
+		}
+
+		shoots, payments, err := sqliteShootsWithPayments(ctx, tx, ids)
+		if err != nil {
+			return err
+		}
+
+		result, err := tx.ExecContext(ctx, `
+DELETE FROM shoots WHERE id IN (SELECT value FROM json_each($1))`, sqliteIdList(ids))
+		if err != nil {
+			return myerrors.Wrap(err, myerrors.ErrCodeShootDelete, "failed to purge shoots")
+		}
+
+		removed, err := result.RowsAffected()
+		if err != nil {
+			return myerrors.Wrap(err, myerrors.ErrCodeShootDelete, "failed to purge shoots")
+		}
+		purged = int(removed)
+
+		if err := sqliteRecordAll(ctx, tx, model.AuditPayment, model.AuditPurge, payments); err != nil {
+			return err
+		}
+		return sqliteRecordAll(ctx, tx, model.AuditShoot, model.AuditPurge, shoots)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return purged, nil
+}
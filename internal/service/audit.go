@@ -0,0 +1,37 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	myerrors "github.com/Coiiap5e/photographer/internal/errors"
+	"github.com/Coiiap5e/photographer/internal/model"
+	"github.com/Coiiap5e/photographer/internal/repository"
+)
+
+type Audit interface {
+	History(ctx context.Context, entity model.AuditEntity, id int) ([]model.AuditEntry, error)
+}
+
+type postgresAudit struct {
+	auditRepo repository.Audit
+}
+
+func NewAudit(auditRepo repository.Audit) Audit {
+	return &postgresAudit{auditRepo: auditRepo}
+}
+
+// History returns the changes of a client with its shoots, or of a shoot
+// with its payments, oldest first. Deleted and purged items keep their
+// history.
+func (a *postgresAudit) History(ctx context.Context, entity model.AuditEntity,
+	id int) ([]model.AuditEntry, error) {
+	if entity != model.AuditClient && entity != model.AuditShoot {
+		return nil, myerrors.New(myerrors.ErrCodeValidation, fmt.Sprintf("no history for %q", entity))
+	}
+	if id <= 0 {
+		return nil, myerrors.New(myerrors.ErrCodeValidation, "ID must be a number > 0")
+	}
+
+	return a.auditRepo.GetHistory(ctx, entity, id)
+}
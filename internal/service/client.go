@@ -3,19 +3,25 @@ package service
 import (
 	"context"
 	"fmt"
+	"io"
 	"strings"
 
 	"github.com/Coiiap5e/photographer/internal/errors"
+	"github.com/Coiiap5e/photographer/internal/importer"
 	"github.com/Coiiap5e/photographer/internal/model"
 	"github.com/Coiiap5e/photographer/internal/repository"
-	"github.com/Coiiap5e/photographer/internal/utils"
+	"github.com/Coiiap5e/photographer/internal/validation"
 )
 
 type Client interface {
 	CreateClient(ctx context.Context, client *model.Client) error
 	DeleteClient(ctx context.Context, id int) error
-	GetClients(ctx context.Context) error
+	FindClients(ctx context.Context, filter model.ClientFilter) (*model.ClientPage, error)
+	SearchClients(ctx context.Context, query string) ([]model.Client, error)
+	ListClients(ctx context.Context) ([]model.Client, error)
 	GetClientByID(ctx context.Context, id int) (*model.Client, error)
+	UpdateClient(ctx context.Context, id, version int, update *model.ClientUpdate) (*model.Client, error)
+	ImportClients(ctx context.Context, r io.Reader, opts importer.Options, dryRun bool) (*importer.Report, error)
 }
 
 type postgresClient struct {
@@ -27,6 +33,10 @@ func NewClient(clientRepo repository.Client) Client {
 }
 
 func (c *postgresClient) CreateClient(ctx context.Context, client *model.Client) error {
+	if err := validation.Client(client); err != nil {
+		return err
+	}
+
 	err := c.clientRepo.AddClient(ctx, client)
 	if err != nil {
 		return err
@@ -47,18 +57,47 @@ func (c *postgresClient) GetClientByID(ctx context.Context, id int) (*model.Clie
 	return client, nil
 }
 
-func (c *postgresClient) DeleteClient(ctx context.Context, id int) error {
-	for {
-		confirm := utils.InputStringRequired("Are you sure you want to delete the client? (y/n)")
-		if confirm == "n" || confirm == "N" {
-			return errors.New(errors.ErrCodeValidation, "deletion cancelled")
-		} else if confirm == "y" || confirm == "Y" {
-			break
-		} else {
-			fmt.Println("Press wrong button: enter (y/n)")
-		}
+func (c *postgresClient) UpdateClient(ctx context.Context, id, version int,
+	update *model.ClientUpdate) (*model.Client, error) {
+	if err := validation.ClientUpdate(update); err != nil {
+		return nil, err
+	}
+
+	return c.clientRepo.UpdateClient(ctx, id, version, update)
+}
+
+// ImportClients adds the new clients from a CSV file in one transaction and
+// skips duplicates and invalid rows. With dryRun it only reports what would
+// be imported.
+func (c *postgresClient) ImportClients(ctx context.Context, r io.Reader, opts importer.Options,
+	dryRun bool) (*importer.Report, error) {
+	existing, err := c.clientRepo.GetClients(ctx, model.ClientFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := importer.PlanClients(r, opts, existing)
+	if err != nil {
+		return nil, err
+	}
+
+	if dryRun || len(report.New) == 0 {
+		return report, nil
+	}
+
+	clients := make([]*model.Client, len(report.New))
+	for i := range report.New {
+		clients[i] = &report.New[i].Client
 	}
 
+	if err := c.clientRepo.AddClients(ctx, clients); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+func (c *postgresClient) DeleteClient(ctx context.Context, id int) error {
 	err := c.clientRepo.DeleteClient(ctx, id)
 	if err != nil {
 		return err
@@ -67,35 +106,52 @@ func (c *postgresClient) DeleteClient(ctx context.Context, id int) error {
 	return nil
 }
 
-func (c *postgresClient) GetClients(ctx context.Context) error {
-	clients, err := c.clientRepo.GetClients(ctx)
+// FindClients returns a page of clients in filter's sort order
+func (c *postgresClient) FindClients(ctx context.Context, filter model.ClientFilter) (*model.ClientPage, error) {
+	if !filter.Sort.Valid() {
+		return nil, errors.New(errors.ErrCodeValidation, fmt.Sprintf("unknown sort %q", filter.Sort))
+	}
+	if filter.Limit < 0 {
+		return nil, errors.New(errors.ErrCodeValidation, "page size must not be negative")
+	}
+
+	query := filter
+	if filter.Limit > 0 {
+		query.Limit = filter.Limit + 1
+	}
+
+	clients, err := c.clientRepo.GetClients(ctx, query)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	showClients(clients)
+	page := &model.ClientPage{Clients: clients}
+	if filter.Limit > 0 && len(clients) > filter.Limit {
+		page.Clients = clients[:filter.Limit]
+		next := filter
+		next.After = &page.Clients[filter.Limit-1]
+		page.Next = &next
+	}
 
-	return nil
+	return page, nil
 }
 
-func showClients(clients []model.Client) {
-	if len(clients) == 0 {
-		fmt.Println("No clients found")
-		return
+// searchLimit caps the clients returned by a search, a longer list is
+// better narrowed down by a more specific query
+const searchLimit = 20
+
+// SearchClients finds clients by name, phone or social URL, best matches
+// first
+func (c *postgresClient) SearchClients(ctx context.Context, query string) ([]model.Client, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, errors.New(errors.ErrCodeValidation, "search query must not be empty")
 	}
 
-	fmt.Printf("%-4s %-15s %-15s %-20s %-25s %-12s\n",
-		"ID", "First Name", "Last Name", "Phone", "Social Network", "Created")
-	fmt.Println(strings.Repeat("-", 95))
+	return c.clientRepo.SearchClients(ctx, query, searchLimit)
+}
 
-	for _, client := range clients {
-		fmt.Printf("%-4d %-15s %-15s %-20s %-25s %-12s\n",
-			client.Id,
-			client.FirstName,
-			client.LastName,
-			client.Phone,
-			client.SocialNetworkUrl,
-			client.CreatedAt.Format("02.01.2006"),
-		)
-	}
+// ListClients returns all clients ordered by name
+func (c *postgresClient) ListClients(ctx context.Context) ([]model.Client, error) {
+	return c.clientRepo.GetClients(ctx, model.ClientFilter{})
 }
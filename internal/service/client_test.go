@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Coiiap5e/photographer/internal/errors"
+	"github.com/Coiiap5e/photographer/internal/model"
+	"github.com/Coiiap5e/photographer/internal/validation"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestClientTestSuit(t *testing.T) {
+	suite.Run(t, new(ClientTestSuit))
+}
+
+type ClientTestSuit struct {
+	suite.Suite
+	ctx  context.Context
+	repo *mockClientRepo
+	svc  Client
+}
+
+func (suite *ClientTestSuit) SetupTest() {
+	suite.ctx = context.Background()
+	suite.repo = &mockClientRepo{}
+	suite.svc = NewClient(suite.repo)
+}
+
+func (suite *ClientTestSuit) clients(n int) []model.Client {
+	clients := make([]model.Client, n)
+	for i := range clients {
+		clients[i] = model.Client{Id: i + 1, LastName: "Client"}
+	}
+	return clients
+}
+
+func (suite *ClientTestSuit) TestFindClients() {
+	suite.T().Run("should ask for one more client and return next page filter", func(t *testing.T) {
+		// Given
+		var asked model.ClientFilter
+		suite.repo.getClients = func(_ context.Context, filter model.ClientFilter) ([]model.Client, error) {
+			asked = filter
+			return suite.clients(filter.Limit), nil
+		}
+
+		// When
+		page, err := suite.svc.FindClients(suite.ctx, model.ClientFilter{Limit: 2})
+
+		// Then
+		suite.Require().NoError(err, "should find clients")
+		suite.Equal(3, asked.Limit, "should ask the repository for one more client")
+		suite.Len(page.Clients, 2, "should return one page")
+		suite.Require().NotNil(page.Next, "should have next page")
+		suite.Equal(2, page.Next.After.Id, "next page should start after the last client")
+	})
+	suite.T().Run("should return last page without next filter", func(t *testing.T) {
+		// Given
+		suite.repo.getClients = func(context.Context, model.ClientFilter) ([]model.Client, error) {
+			return suite.clients(2), nil
+		}
+
+		// When
+		page, err := suite.svc.FindClients(suite.ctx, model.ClientFilter{Limit: 2})
+
+		// Then
+		suite.Require().NoError(err, "should find clients")
+		suite.Len(page.Clients, 2, "should return all clients")
+		suite.Nil(page.Next, "should not have next page")
+	})
+	suite.T().Run("should reject unknown sort without asking the repository", func(t *testing.T) {
+		// Given
+		suite.repo.getClients = nil
+
+		// When
+		_, err := suite.svc.FindClients(suite.ctx, model.ClientFilter{Sort: "phone"})
+
+		// Then
+		suite.True(errors.IsErrorCode(err, errors.ErrCodeValidation), "should return validation error")
+	})
+}
+
+func (suite *ClientTestSuit) TestSearchClients() {
+	suite.T().Run("should trim query and limit results", func(t *testing.T) {
+		// Given
+		var query string
+		var limit int
+		suite.repo.searchClients = func(_ context.Context, q string, l int) ([]model.Client, error) {
+			query, limit = q, l
+			return suite.clients(1), nil
+		}
+
+		// When
+		clients, err := suite.svc.SearchClients(suite.ctx, "  Иван ")
+
+		// Then
+		suite.Require().NoError(err, "should search clients")
+		suite.Len(clients, 1, "should return found clients")
+		suite.Equal("Иван", query, "should pass trimmed query")
+		suite.Equal(searchLimit, limit, "should cap the results")
+	})
+	suite.T().Run("should reject empty query", func(t *testing.T) {
+		// Given
+		suite.repo.searchClients = nil
+
+		// When
+		_, err := suite.svc.SearchClients(suite.ctx, "   ")
+
+		// Then
+		suite.True(errors.IsErrorCode(err, errors.ErrCodeValidation), "should return validation error")
+	})
+}
+
+func (suite *ClientTestSuit) TestCreateClient() {
+	suite.T().Run("should reject invalid fields without asking the repository", func(t *testing.T) {
+		// Given
+		client := &model.Client{FirstName: "Анна", Phone: "call me", SocialNetworkUrl: "vk"}
+
+		// When
+		err := suite.svc.CreateClient(suite.ctx, client)
+
+		// Then
+		suite.True(errors.IsErrorCode(err, errors.ErrCodeValidation), "should return validation error")
+		fields := validation.Fields(err)
+		suite.NotEmpty(fields.Message(validation.FieldLastName), "should require the last name")
+		suite.NotEmpty(fields.Message(validation.FieldPhone), "should reject the phone")
+		suite.NotEmpty(fields.Message(validation.FieldSocialNetworkUrl), "should reject the url")
+		suite.Empty(fields.Message(validation.FieldFirstName), "should accept the first name")
+	})
+}
+
+func (suite *ClientTestSuit) TestUpdateClient() {
+	suite.T().Run("should check only the changed fields", func(t *testing.T) {
+		// Given
+		phone := "12345"
+
+		// When
+		_, err := suite.svc.UpdateClient(suite.ctx, 1, 1, &model.ClientUpdate{Phone: &phone})
+
+		// Then
+		fields := validation.Fields(err)
+		suite.Require().Len(fields, 1, "should report one field")
+		suite.Equal(validation.FieldPhone, fields[0].Field, "should report the phone")
+	})
+}
+
+func (suite *ClientTestSuit) TestGetClientByID() {
+	suite.T().Run("should keep not found code", func(t *testing.T) {
+		// Given
+		suite.repo.getClientByID = func(context.Context, int) (*model.Client, error) {
+			return nil, errors.New(errors.ErrCodeClientNotFound, "client not found")
+		}
+
+		// When
+		_, err := suite.svc.GetClientByID(suite.ctx, 7)
+
+		// Then
+		suite.True(errors.IsErrorCode(err, errors.ErrCodeClientNotFound), "should return not found error")
+	})
+}
+
+func (suite *ClientTestSuit) TestDeleteClient() {
+	suite.T().Run("should delete the client without asking for confirmation", func(t *testing.T) {
+		// Given
+		var deleted int
+		suite.repo.deleteClient = func(_ context.Context, id int) error {
+			deleted = id
+			return nil
+		}
+
+		// When
+		err := suite.svc.DeleteClient(suite.ctx, 7)
+
+		// Then
+		suite.Require().NoError(err, "should delete client")
+		suite.Equal(7, deleted, "should delete the given client")
+	})
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/Coiiap5e/photographer/internal/model"
+	"github.com/Coiiap5e/photographer/internal/repository"
+)
+
+// The mocks embed the repository interface, so a test sets only the
+// methods it expects to be called and any other call panics
+
+type mockClientRepo struct {
+	repository.Client
+	getClientByID func(ctx context.Context, id int) (*model.Client, error)
+	getClients    func(ctx context.Context, filter model.ClientFilter) ([]model.Client, error)
+	searchClients func(ctx context.Context, query string, limit int) ([]model.Client, error)
+	deleteClient  func(ctx context.Context, id int) error
+	purgeClients  func(ctx context.Context, olderThan time.Duration) (int, error)
+}
+
+func (m *mockClientRepo) GetClientByID(ctx context.Context, id int) (*model.Client, error) {
+	return m.getClientByID(ctx, id)
+}
+
+func (m *mockClientRepo) GetClients(ctx context.Context, filter model.ClientFilter) ([]model.Client, error) {
+	return m.getClients(ctx, filter)
+}
+
+func (m *mockClientRepo) SearchClients(ctx context.Context, query string, limit int) ([]model.Client, error) {
+	return m.searchClients(ctx, query, limit)
+}
+
+func (m *mockClientRepo) DeleteClient(ctx context.Context, id int) error {
+	return m.deleteClient(ctx, id)
+}
+
+func (m *mockClientRepo) PurgeClients(ctx context.Context, olderThan time.Duration) (int, error) {
+	return m.purgeClients(ctx, olderThan)
+}
+
+type mockShootRepo struct {
+	repository.Shoot
+	addShoot     func(ctx context.Context, shoot *model.Shoot) error
+	getShootByID func(ctx context.Context, id int) (*model.Shoot, error)
+	getShoots    func(ctx context.Context, filter model.ShootFilter) ([]model.Shoot, error)
+	updateShoot  func(ctx context.Context, id, version int, update *model.ShootUpdate) (*model.Shoot, error)
+	purgeShoots  func(ctx context.Context, olderThan time.Duration) (int, error)
+}
+
+func (m *mockShootRepo) AddShoot(ctx context.Context, shoot *model.Shoot) error {
+	return m.addShoot(ctx, shoot)
+}
+
+func (m *mockShootRepo) GetShootByID(ctx context.Context, id int) (*model.Shoot, error) {
+	return m.getShootByID(ctx, id)
+}
+
+func (m *mockShootRepo) GetShoots(ctx context.Context, filter model.ShootFilter) ([]model.Shoot, error) {
+	return m.getShoots(ctx, filter)
+}
+
+func (m *mockShootRepo) UpdateShoot(ctx context.Context, id, version int,
+	update *model.ShootUpdate) (*model.Shoot, error) {
+	return m.updateShoot(ctx, id, version, update)
+}
+
+func (m *mockShootRepo) PurgeShoots(ctx context.Context, olderThan time.Duration) (int, error) {
+	return m.purgeShoots(ctx, olderThan)
+}
+
+type mockPaymentRepo struct {
+	repository.Payment
+	lockShootPayments func(ctx context.Context, shootID int) error
+	addPayment        func(ctx context.Context, payment *model.Payment) error
+	getShootBalance   func(ctx context.Context, shootID int) (*model.ShootBalance, error)
+}
+
+func (m *mockPaymentRepo) LockShootPayments(ctx context.Context, shootID int) error {
+	return m.lockShootPayments(ctx, shootID)
+}
+
+func (m *mockPaymentRepo) AddPayment(ctx context.Context, payment *model.Payment) error {
+	return m.addPayment(ctx, payment)
+}
+
+func (m *mockPaymentRepo) GetShootBalance(ctx context.Context, shootID int) (*model.ShootBalance, error) {
+	return m.getShootBalance(ctx, shootID)
+}
+
+// mockTransactor runs fn directly and counts the transactions, rolledBack
+// counts those fn failed
+type mockTransactor struct {
+	started    int
+	rolledBack int
+}
+
+func (m *mockTransactor) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	m.started++
+	if err := fn(ctx); err != nil {
+		m.rolledBack++
+		return err
+	}
+	return nil
+}
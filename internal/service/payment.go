@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Coiiap5e/photographer/internal/database"
+	"github.com/Coiiap5e/photographer/internal/errors"
+	"github.com/Coiiap5e/photographer/internal/model"
+	"github.com/Coiiap5e/photographer/internal/repository"
+)
+
+type Payment interface {
+	RecordPayment(ctx context.Context, payment *model.Payment) error
+	ListPayments(ctx context.Context, shootID int) ([]model.Payment, error)
+	GetShootBalance(ctx context.Context, shootID int) (*model.ShootBalance, error)
+	OutstandingBalances(ctx context.Context) ([]model.ShootBalance, error)
+	ClientBalances(ctx context.Context) ([]model.ClientBalance, error)
+}
+
+type postgresPayment struct {
+	transactor  database.Transactor
+	paymentRepo repository.Payment
+}
+
+func NewPayment(transactor database.Transactor, paymentRepo repository.Payment) Payment {
+	return &postgresPayment{transactor: transactor, paymentRepo: paymentRepo}
+}
+
+// RecordPayment checks the payment against the shoot it belongs to. The
+// payment is in the currency of the shoot price and a refund can not return
+// more than has been paid so far. The balance is read and the payment added
+// in one transaction with the payments of the shoot locked, so concurrent
+// refunds can not together return more than was paid.
+func (p *postgresPayment) RecordPayment(ctx context.Context, payment *model.Payment) error {
+	if !payment.Kind.Valid() {
+		return errors.New(errors.ErrCodeValidation,
+			fmt.Sprintf("unknown payment kind %q", payment.Kind))
+	}
+	if payment.Amount.Amount <= 0 {
+		return errors.New(errors.ErrCodeValidation, "payment amount must be > 0")
+	}
+
+	return p.transactor.WithTx(ctx, func(ctx context.Context) error {
+		if err := p.paymentRepo.LockShootPayments(ctx, payment.ShootId); err != nil {
+			return err
+		}
+
+		balance, err := p.paymentRepo.GetShootBalance(ctx, payment.ShootId)
+		if err != nil {
+			return err
+		}
+
+		if payment.Amount.Currency == "" {
+			payment.Amount.Currency = balance.Price.Currency
+		}
+		if payment.Amount.Currency != balance.Price.Currency {
+			return errors.New(errors.ErrCodeValidation,
+				fmt.Sprintf("payment must be in %s like the shoot price", balance.Price.Currency))
+		}
+
+		if payment.Kind == model.PaymentRefund && payment.Amount.Amount > balance.Paid.Amount {
+			return errors.New(errors.ErrCodeValidation,
+				fmt.Sprintf("refund %s is more than paid %s", payment.Amount, balance.Paid))
+		}
+
+		return p.paymentRepo.AddPayment(ctx, payment)
+	})
+}
+
+func (p *postgresPayment) ListPayments(ctx context.Context, shootID int) ([]model.Payment, error) {
+	return p.paymentRepo.GetPaymentsByShoot(ctx, shootID)
+}
+
+func (p *postgresPayment) GetShootBalance(ctx context.Context, shootID int) (*model.ShootBalance, error) {
+	return p.paymentRepo.GetShootBalance(ctx, shootID)
+}
+
+// OutstandingBalances returns the shoots that are not fully paid yet
+func (p *postgresPayment) OutstandingBalances(ctx context.Context) ([]model.ShootBalance, error) {
+	balances, err := p.paymentRepo.GetShootBalances(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	outstanding := make([]model.ShootBalance, 0, len(balances))
+	for _, balance := range balances {
+		if balance.Due().Amount > 0 {
+			outstanding = append(outstanding, balance)
+		}
+	}
+
+	return outstanding, nil
+}
+
+func (p *postgresPayment) ClientBalances(ctx context.Context) ([]model.ClientBalance, error) {
+	return p.paymentRepo.GetClientBalances(ctx)
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Coiiap5e/photographer/internal/errors"
+	"github.com/Coiiap5e/photographer/internal/model"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestPaymentTestSuit(t *testing.T) {
+	suite.Run(t, new(PaymentTestSuit))
+}
+
+type PaymentTestSuit struct {
+	suite.Suite
+	ctx        context.Context
+	transactor *mockTransactor
+	repo       *mockPaymentRepo
+	svc        Payment
+	added      []*model.Payment
+	calls      []string
+}
+
+func (suite *PaymentTestSuit) SetupTest() {
+	suite.ctx = context.Background()
+	suite.added, suite.calls = nil, nil
+	suite.transactor = &mockTransactor{}
+	suite.repo = &mockPaymentRepo{
+		lockShootPayments: func(context.Context, int) error {
+			suite.calls = append(suite.calls, "lock")
+			return nil
+		},
+		getShootBalance: func(_ context.Context, shootID int) (*model.ShootBalance, error) {
+			suite.calls = append(suite.calls, "balance")
+			return &model.ShootBalance{
+				ShootId: shootID,
+				Price:   model.NewMoney(1000000, "RUB"),
+				Paid:    model.NewMoney(300000, "RUB"),
+			}, nil
+		},
+		addPayment: func(_ context.Context, payment *model.Payment) error {
+			suite.calls = append(suite.calls, "add")
+			suite.added = append(suite.added, payment)
+			return nil
+		},
+	}
+	suite.svc = NewPayment(suite.transactor, suite.repo)
+}
+
+func (suite *PaymentTestSuit) TestRecordPayment() {
+	suite.T().Run("should take currency of the shoot price", func(t *testing.T) {
+		// Given
+		payment := &model.Payment{ShootId: 1, Kind: model.PaymentFinal, Amount: model.Money{Amount: 700000}}
+
+		// When
+		err := suite.svc.RecordPayment(suite.ctx, payment)
+
+		// Then
+		suite.Require().NoError(err, "should record payment")
+		suite.Require().Len(suite.added, 1, "should add the payment")
+		suite.Equal("RUB", suite.added[0].Amount.Currency, "should use currency of the price")
+	})
+	suite.T().Run("should read the balance and add in one transaction with the shoot locked", func(t *testing.T) {
+		// Given
+		suite.calls = nil
+		started := suite.transactor.started
+		payment := &model.Payment{ShootId: 1, Kind: model.PaymentRefund, Amount: model.NewMoney(300000, "RUB")}
+
+		// When
+		err := suite.svc.RecordPayment(suite.ctx, payment)
+
+		// Then
+		suite.Require().NoError(err, "should record refund of what was paid")
+		suite.Equal(started+1, suite.transactor.started, "should run in one transaction")
+		suite.Equal([]string{"lock", "balance", "add"}, suite.calls,
+			"should lock the payments before reading the balance")
+	})
+	suite.T().Run("should reject refund of more than paid", func(t *testing.T) {
+		// Given
+		suite.added = nil
+		payment := &model.Payment{ShootId: 1, Kind: model.PaymentRefund, Amount: model.NewMoney(300001, "RUB")}
+
+		// When
+		err := suite.svc.RecordPayment(suite.ctx, payment)
+
+		// Then
+		suite.True(errors.IsErrorCode(err, errors.ErrCodeValidation), "should return validation error")
+		suite.Empty(suite.added, "should not add the payment")
+	})
+	suite.T().Run("should reject payment in another currency", func(t *testing.T) {
+		// Given
+		suite.added = nil
+		payment := &model.Payment{ShootId: 1, Kind: model.PaymentDeposit, Amount: model.NewMoney(100, "EUR")}
+
+		// When
+		err := suite.svc.RecordPayment(suite.ctx, payment)
+
+		// Then
+		suite.True(errors.IsErrorCode(err, errors.ErrCodeValidation), "should return validation error")
+		suite.Empty(suite.added, "should not add the payment")
+	})
+}
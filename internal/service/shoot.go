@@ -4,34 +4,99 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
+	"github.com/Coiiap5e/photographer/internal/config"
 	myerrors "github.com/Coiiap5e/photographer/internal/errors"
 	"github.com/Coiiap5e/photographer/internal/model"
 	"github.com/Coiiap5e/photographer/internal/repository"
-	"github.com/Coiiap5e/photographer/internal/utils"
+	"github.com/Coiiap5e/photographer/internal/validation"
 )
 
 type Shoot interface {
 	CreateShoot(ctx context.Context, shoot *model.Shoot) error
 	DeleteShoot(ctx context.Context, id int) error
-	GetShoots(ctx context.Context) error
+	FindShoots(ctx context.Context, filter model.ShootFilter) (*model.ShootPage, error)
+	ListShoots(ctx context.Context, from, to time.Time) ([]model.Shoot, error)
 	GetShootByID(ctx context.Context, id int) (*model.Shoot, error)
+	UpdateShoot(ctx context.Context, id, version int, update *model.ShootUpdate) (*model.Shoot, error)
+	DefaultSlotQuery(from, to time.Time) model.SlotQuery
+	DefaultCurrency() string
+	FindFreeSlots(ctx context.Context, query model.SlotQuery) ([]model.Slot, error)
+}
+
+// ConflictError lists the shoots a new or moved shoot overlaps with. It is
+// wrapped into an AppError with ErrCodeShootConflict.
+type ConflictError struct {
+	Shoots []model.Shoot
+}
+
+func (e *ConflictError) Error() string {
+	parts := make([]string, 0, len(e.Shoots))
+	for _, shoot := range e.Shoots {
+		parts = append(parts, fmt.Sprintf("#%d %s %s-%s %s %s", shoot.Id,
+			shoot.ShootDate.Format("02.01.2006"), shoot.StartTime.Format("15:04"),
+			shoot.EndTime.Format("15:04"), shoot.Client.FirstName, shoot.Client.LastName))
+	}
+	return strings.Join(parts, "; ")
 }
 
 type postgresShoot struct {
 	shootRepo  repository.Shoot
 	clientRepo repository.Client
+	schedule   config.ScheduleConfig
+	money      config.MoneyConfig
+	// now is the clock shoot dates are validated against
+	now func() time.Time
 }
 
-func NewShoot(shootRepo repository.Shoot, clientRepo repository.Client) Shoot {
+// NewShoot returns the shoot service. schedule.ShootBuffer is the gap
+// required between two shoots, shoots closer than that are reported as
+// conflicts. Prices without a currency get money.DefaultCurrency.
+func NewShoot(shootRepo repository.Shoot, clientRepo repository.Client,
+	schedule config.ScheduleConfig, money config.MoneyConfig) Shoot {
 	return &postgresShoot{
 		shootRepo:  shootRepo,
 		clientRepo: clientRepo,
+		schedule:   schedule,
+		money:      money,
+		now:        time.Now,
 	}
 }
 
+func (s *postgresShoot) DefaultCurrency() string {
+	return s.money.DefaultCurrency
+}
+
+// setCurrency sets the default currency on a price without one
+func (s *postgresShoot) setCurrency(price *model.Money) {
+	if price.Currency == "" {
+		price.Currency = s.money.DefaultCurrency
+	}
+}
+
+// CreateShoot validates the shoot and stores it unless it overlaps another
+// one. Set AllowOverlap on the shoot to book it anyway.
 func (s *postgresShoot) CreateShoot(ctx context.Context, shoot *model.Shoot) error {
-	err := s.shootRepo.AddShoot(ctx, shoot)
+	s.setCurrency(&shoot.ShootPrice)
+	if err := validation.Shoot(shoot, s.now()); err != nil {
+		return err
+	}
+
+	client, err := s.clientRepo.GetClientByID(ctx, shoot.ClientId)
+	if err != nil {
+		return err
+	}
+
+	shoot.Client = *client
+
+	if !shoot.AllowOverlap {
+		if err := s.checkConflicts(ctx, shoot); err != nil {
+			return err
+		}
+	}
+
+	err = s.shootRepo.AddShoot(ctx, shoot)
 	if err != nil {
 		return err
 	}
@@ -51,63 +116,151 @@ func (s *postgresShoot) GetShootByID(ctx context.Context, id int) (*model.Shoot,
 	return shoot, nil
 }
 
-func (s *postgresShoot) DeleteShoot(ctx context.Context, id int) error {
-	for {
-		confirm := utils.InputStringRequired("Are you sure you want to delete the shoot? (y/n)")
-		if confirm == "n" || confirm == "N" {
-			return myerrors.New(myerrors.ErrCodeValidation, "deletion cancelled")
-		} else if confirm == "y" || confirm == "Y" {
-			break
-		} else {
-			fmt.Println("Press wrong button: enter (y/n)")
+// UpdateShoot validates and applies a partial update. A new client must
+// exist. Moving the shoot in time checks it for conflicts again unless
+// AllowOverlap is set.
+func (s *postgresShoot) UpdateShoot(ctx context.Context, id, version int,
+	update *model.ShootUpdate) (*model.Shoot, error) {
+	if update.ShootPrice != nil {
+		s.setCurrency(update.ShootPrice)
+	}
+
+	retimed := update.StartTime != nil || update.EndTime != nil
+	moved := retimed || update.ShootDate != nil
+	recheck := moved && (update.AllowOverlap == nil || !*update.AllowOverlap)
+
+	var current *model.Shoot
+	if retimed || recheck {
+		var err error
+		current, err = s.shootRepo.GetShootByID(ctx, id)
+		if err != nil {
+			return nil, err
 		}
 	}
 
-	err := s.shootRepo.DeleteShoot(ctx, id)
+	if err := validation.ShootUpdate(update, current, s.now()); err != nil {
+		return nil, err
+	}
+
+	if update.ClientId != nil {
+		if _, err := s.clientRepo.GetClientByID(ctx, *update.ClientId); err != nil {
+			return nil, err
+		}
+	}
+
+	if recheck {
+		if update.ShootDate != nil {
+			current.ShootDate = *update.ShootDate
+		}
+		if update.StartTime != nil {
+			current.StartTime = *update.StartTime
+		}
+		if update.EndTime != nil {
+			current.EndTime = *update.EndTime
+		}
+
+		if err := s.checkConflicts(ctx, current); err != nil {
+			return nil, err
+		}
+
+		allowOverlap := false
+		update.AllowOverlap = &allowOverlap
+	}
+
+	return s.shootRepo.UpdateShoot(ctx, id, version, update)
+}
+
+// checkConflicts returns an ErrCodeShootConflict error if shoot, widened by
+// the buffer on both sides, overlaps any other stored shoot.
+func (s *postgresShoot) checkConflicts(ctx context.Context, shoot *model.Shoot) error {
+	margin := s.marginDays()
+	shoots, err := s.shootRepo.GetShoots(ctx, model.ShootFilter{
+		From: dateOnly(shoot.ShootDate).AddDate(0, 0, -margin),
+		To:   dateOnly(shoot.ShootDate).AddDate(0, 0, margin),
+	})
 	if err != nil {
 		return err
 	}
 
+	start, end := shoot.Interval()
+	start, end = start.Add(-s.schedule.ShootBuffer), end.Add(s.schedule.ShootBuffer)
+
+	var conflicts []model.Shoot
+	for _, other := range shoots {
+		if other.Id == shoot.Id {
+			continue
+		}
+		otherStart, otherEnd := other.Interval()
+		if start.Before(otherEnd) && otherStart.Before(end) {
+			conflicts = append(conflicts, other)
+		}
+	}
+
+	if len(conflicts) > 0 {
+		return myerrors.Wrap(&ConflictError{Shoots: conflicts},
+			myerrors.ErrCodeShootConflict, "shoot overlaps other shoots")
+	}
+
 	return nil
 }
 
-func (s *postgresShoot) GetShoots(ctx context.Context) error {
-	shoots, err := s.shootRepo.GetShoots(ctx)
+func (s *postgresShoot) DeleteShoot(ctx context.Context, id int) error {
+	err := s.shootRepo.DeleteShoot(ctx, id)
 	if err != nil {
 		return err
 	}
 
-	showShoots(shoots)
-
 	return nil
 }
 
-func showShoots(shoots []model.Shoot) {
-	if len(shoots) == 0 {
-		fmt.Println("No shoots found")
-		return
-	}
-
-	fmt.Printf("%-3s %-9s %-10s %-8s %-8s %-6s %-25s %-12s %-12s %-10s %-25s %-10s\n",
-		"ID", "Client ID", "Date", "Start", "End", "Price", "Location",
-		"First name", "Last name", "Type", "Notes", "Created")
-
-	fmt.Println(strings.Repeat("-", 148))
-
-	for _, shoot := range shoots {
-		fmt.Printf("%-3d %-9d %-10s %-8s %-8s %-6d %-25s %-12s %-12s %-10s %-25s %-10s\n",
-			shoot.Id,
-			shoot.ClientId,
-			shoot.ShootDate.Format("02.01.2006"),
-			shoot.StartTime.Format("15:04"),
-			shoot.EndTime.Format("15:04"),
-			shoot.ShootPrice,
-			shoot.ShootLocation,
-			shoot.ClientFirstName,
-			shoot.ClientLastName,
-			shoot.ShootType,
-			shoot.Notes,
-			shoot.CreatedAt,
-		)
+// FindShoots returns a page of shoots matching filter. Pages are keyset
+// based, so shoots added or deleted meanwhile do not shift the next page.
+func (s *postgresShoot) FindShoots(ctx context.Context, filter model.ShootFilter) (*model.ShootPage, error) {
+	if !filter.Sort.Valid() {
+		return nil, myerrors.New(myerrors.ErrCodeValidation, fmt.Sprintf("unknown sort %q", filter.Sort))
 	}
+	if filter.Limit < 0 {
+		return nil, myerrors.New(myerrors.ErrCodeValidation, "page size must not be negative")
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && filter.To.Before(filter.From) {
+		return nil, myerrors.New(myerrors.ErrCodeValidation, "end of the range is before its start")
+	}
+	for _, price := range []*model.Money{filter.MinPrice, filter.MaxPrice} {
+		if price != nil && price.Currency == "" {
+			price.Currency = s.money.DefaultCurrency
+		}
+	}
+
+	query := filter
+	if filter.Limit > 0 {
+		// one more shoot tells whether there is a next page
+		query.Limit = filter.Limit + 1
+	}
+
+	shoots, err := s.shootRepo.GetShoots(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &model.ShootPage{Shoots: shoots}
+	if filter.Limit > 0 && len(shoots) > filter.Limit {
+		page.Shoots = shoots[:filter.Limit]
+		next := filter
+		next.After = &page.Shoots[filter.Limit-1]
+		page.Next = &next
+	}
+
+	return page, nil
+}
+
+// ListShoots returns shoots with ShootDate between from and to inclusive,
+// ordered by date. A zero from or to leaves that side of the range open.
+func (s *postgresShoot) ListShoots(ctx context.Context, from, to time.Time) ([]model.Shoot, error) {
+	return s.shootRepo.GetShoots(ctx, model.ShootFilter{From: from, To: to})
+}
+
+// marginDays is how many days around a date shoots can reach into it,
+// counting shoots that end after midnight and the buffer between shoots
+func (s *postgresShoot) marginDays() int {
+	return 1 + int(s.schedule.ShootBuffer/(24*time.Hour))
 }
@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+	"time"
+
+	"github.com/Coiiap5e/photographer/internal/config"
+	"github.com/Coiiap5e/photographer/internal/errors"
+	"github.com/Coiiap5e/photographer/internal/model"
+	"github.com/Coiiap5e/photographer/internal/validation"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestShootTestSuit(t *testing.T) {
+	suite.Run(t, new(ShootTestSuit))
+}
+
+type ShootTestSuit struct {
+	suite.Suite
+	ctx        context.Context
+	shootRepo  *mockShootRepo
+	clientRepo *mockClientRepo
+	svc        Shoot
+	day        time.Time
+}
+
+func (suite *ShootTestSuit) SetupTest() {
+	suite.ctx = context.Background()
+	suite.day = time.Date(2025, 1, 20, 0, 0, 0, 0, time.UTC)
+	suite.shootRepo = &mockShootRepo{}
+	suite.clientRepo = &mockClientRepo{
+		getClientByID: func(_ context.Context, id int) (*model.Client, error) {
+			return &model.Client{Id: id, FirstName: "Анна"}, nil
+		},
+	}
+	suite.svc = NewShoot(suite.shootRepo, suite.clientRepo,
+		config.ScheduleConfig{ShootBuffer: 30 * time.Minute}, config.MoneyConfig{DefaultCurrency: "RUB"})
+	suite.svc.(*postgresShoot).now = func() time.Time { return suite.day }
+}
+
+func (suite *ShootTestSuit) shoot(id int, start, end string) model.Shoot {
+	startTime, _ := time.Parse("15:04", start)
+	endTime, _ := time.Parse("15:04", end)
+	return model.Shoot{Id: id, ClientId: 1, ShootDate: suite.day, StartTime: startTime, EndTime: endTime,
+		ShootLocation: "Park", ShootType: "portrait"}
+}
+
+// stored makes the shoot repository hold shoots and remember added ones
+func (suite *ShootTestSuit) stored(shoots ...model.Shoot) *[]*model.Shoot {
+	added := &[]*model.Shoot{}
+	suite.shootRepo.getShoots = func(context.Context, model.ShootFilter) ([]model.Shoot, error) {
+		return shoots, nil
+	}
+	suite.shootRepo.addShoot = func(_ context.Context, shoot *model.Shoot) error {
+		*added = append(*added, shoot)
+		return nil
+	}
+	return added
+}
+
+func (suite *ShootTestSuit) TestCreateShoot() {
+	suite.T().Run("should add shoot with default currency", func(t *testing.T) {
+		// Given
+		added := suite.stored()
+		shoot := suite.shoot(0, "10:00", "12:00")
+		shoot.ShootPrice = model.Money{Amount: 500000}
+
+		// When
+		err := suite.svc.CreateShoot(suite.ctx, &shoot)
+
+		// Then
+		suite.Require().NoError(err, "should create shoot")
+		suite.Require().Len(*added, 1, "should add the shoot")
+		suite.Equal("RUB", (*added)[0].ShootPrice.Currency, "should set default currency")
+		suite.Equal("Анна", (*added)[0].Client.FirstName, "should fill in the client")
+	})
+	suite.T().Run("should report shoots within the buffer as conflicts", func(t *testing.T) {
+		// Given
+		added := suite.stored(suite.shoot(5, "12:15", "13:00"))
+		shoot := suite.shoot(0, "10:00", "12:00")
+
+		// When
+		err := suite.svc.CreateShoot(suite.ctx, &shoot)
+
+		// Then
+		suite.True(errors.IsErrorCode(err, errors.ErrCodeShootConflict), "should return conflict error")
+		var conflictErr *ConflictError
+		suite.Require().True(stderrors.As(err, &conflictErr), "should list conflicting shoots")
+		suite.Equal(5, conflictErr.Shoots[0].Id, "should name the conflicting shoot")
+		suite.Empty(*added, "should not add the shoot")
+	})
+	suite.T().Run("should book overlapping shoot when allowed", func(t *testing.T) {
+		// Given
+		added := suite.stored()
+		suite.shootRepo.getShoots = nil
+		shoot := suite.shoot(0, "10:00", "12:00")
+		shoot.AllowOverlap = true
+
+		// When
+		err := suite.svc.CreateShoot(suite.ctx, &shoot)
+
+		// Then
+		suite.Require().NoError(err, "should create shoot without checking conflicts")
+		suite.Len(*added, 1, "should add the shoot")
+	})
+	suite.T().Run("should reject invalid currency", func(t *testing.T) {
+		// Given
+		added := suite.stored()
+		shoot := suite.shoot(0, "10:00", "12:00")
+		shoot.ShootPrice = model.NewMoney(100, "rubles")
+
+		// When
+		err := suite.svc.CreateShoot(suite.ctx, &shoot)
+
+		// Then
+		suite.True(errors.IsErrorCode(err, errors.ErrCodeValidation), "should return validation error")
+		suite.Empty(*added, "should not add the shoot")
+	})
+	suite.T().Run("should report every invalid field", func(t *testing.T) {
+		// Given
+		added := suite.stored()
+		shoot := suite.shoot(0, "10:00", "10:00")
+		shoot.ShootDate = suite.day.AddDate(-2, 0, 0)
+		shoot.ShootPrice = model.Money{Amount: -100}
+
+		// When
+		err := suite.svc.CreateShoot(suite.ctx, &shoot)
+
+		// Then
+		suite.True(errors.IsErrorCode(err, errors.ErrCodeValidation), "should return validation error")
+		fields := validation.Fields(err)
+		suite.Len(fields, 3, "should report all fields at once")
+		suite.NotEmpty(fields.Message(validation.FieldDate), "should reject the old date")
+		suite.NotEmpty(fields.Message(validation.FieldEndTime), "should reject the end time")
+		suite.NotEmpty(fields.Message(validation.FieldShootPrice), "should reject the negative price")
+		suite.Empty(*added, "should not add the shoot")
+	})
+}
+
+func (suite *ShootTestSuit) TestUpdateShoot() {
+	suite.T().Run("should check conflicts when the shoot moves", func(t *testing.T) {
+		// Given
+		current := suite.shoot(1, "10:00", "11:00")
+		suite.stored(current, suite.shoot(2, "14:00", "15:00"))
+		suite.shootRepo.getShootByID = func(context.Context, int) (*model.Shoot, error) {
+			return &current, nil
+		}
+		suite.shootRepo.updateShoot = nil
+		start, _ := time.Parse("15:04", "14:30")
+
+		// When
+		_, err := suite.svc.UpdateShoot(suite.ctx, 1, 1, &model.ShootUpdate{StartTime: &start})
+
+		// Then
+		suite.True(errors.IsErrorCode(err, errors.ErrCodeShootConflict), "should return conflict error")
+	})
+	suite.T().Run("should clear allow overlap of a moved shoot without conflicts", func(t *testing.T) {
+		// Given
+		current := suite.shoot(1, "10:00", "11:00")
+		current.AllowOverlap = true
+		suite.stored(current)
+		suite.shootRepo.getShootByID = func(context.Context, int) (*model.Shoot, error) {
+			return &current, nil
+		}
+		var update *model.ShootUpdate
+		suite.shootRepo.updateShoot = func(_ context.Context, id, version int,
+			u *model.ShootUpdate) (*model.Shoot, error) {
+			update = u
+			return &current, nil
+		}
+		date := suite.day.AddDate(0, 0, 1)
+
+		// When
+		_, err := suite.svc.UpdateShoot(suite.ctx, 1, 1, &model.ShootUpdate{ShootDate: &date})
+
+		// Then
+		suite.Require().NoError(err, "should update shoot")
+		suite.Require().NotNil(update.AllowOverlap, "should set allow overlap")
+		suite.False(*update.AllowOverlap, "should no longer allow overlap")
+	})
+	suite.T().Run("should check a new end time against the current start", func(t *testing.T) {
+		// Given
+		current := suite.shoot(1, "10:00", "11:00")
+		suite.shootRepo.getShootByID = func(context.Context, int) (*model.Shoot, error) {
+			return &current, nil
+		}
+		suite.shootRepo.updateShoot = nil
+		allowOverlap := true
+		end, _ := time.Parse("15:04", "10:00")
+
+		// When
+		_, err := suite.svc.UpdateShoot(suite.ctx, 1, 1,
+			&model.ShootUpdate{EndTime: &end, AllowOverlap: &allowOverlap})
+
+		// Then
+		suite.Equal(validation.FieldEndTime, validation.Fields(err)[0].Field, "should reject the end time")
+	})
+}
+
+func (suite *ShootTestSuit) TestFindShoots() {
+	suite.T().Run("should reject range ending before it starts", func(t *testing.T) {
+		// Given
+		suite.shootRepo.getShoots = nil
+
+		// When
+		_, err := suite.svc.FindShoots(suite.ctx, model.ShootFilter{From: suite.day, To: suite.day.AddDate(0, 0, -1)})
+
+		// Then
+		suite.True(errors.IsErrorCode(err, errors.ErrCodeValidation), "should return validation error")
+	})
+	suite.T().Run("should price filter in default currency", func(t *testing.T) {
+		// Given
+		var asked model.ShootFilter
+		suite.shootRepo.getShoots = func(_ context.Context, filter model.ShootFilter) ([]model.Shoot, error) {
+			asked = filter
+			return nil, nil
+		}
+
+		// When
+		_, err := suite.svc.FindShoots(suite.ctx, model.ShootFilter{MinPrice: &model.Money{Amount: 100}})
+
+		// Then
+		suite.Require().NoError(err, "should find shoots")
+		suite.Equal("RUB", asked.MinPrice.Currency, "should use default currency")
+	})
+}
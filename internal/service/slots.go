@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"time"
+
+	myerrors "github.com/Coiiap5e/photographer/internal/errors"
+	"github.com/Coiiap5e/photographer/internal/model"
+)
+
+// maxSlotSearchDays keeps a mistyped year from producing a huge result
+const maxSlotSearchDays = 366
+
+// DefaultSlotQuery returns a query for the given days filled in with the
+// configured working hours, days off and buffer. Duration is left empty.
+func (s *postgresShoot) DefaultSlotQuery(from, to time.Time) model.SlotQuery {
+	return model.SlotQuery{
+		From:     from,
+		To:       to,
+		DayStart: s.schedule.WorkDayStart,
+		DayEnd:   s.schedule.WorkDayEnd,
+		Buffer:   s.schedule.ShootBuffer,
+		DaysOff:  s.schedule.DaysOff,
+	}
+}
+
+// FindFreeSlots returns the gaps within working hours that are long enough
+// for a shoot of query.Duration, keeping query.Buffer around existing shoots.
+func (s *postgresShoot) FindFreeSlots(ctx context.Context, query model.SlotQuery) ([]model.Slot, error) {
+	if query.Duration <= 0 {
+		return nil, myerrors.New(myerrors.ErrCodeValidation, "duration must be positive")
+	}
+	if query.To.Before(query.From) {
+		return nil, myerrors.New(myerrors.ErrCodeValidation, "end of the range is before its start")
+	}
+	if query.To.Sub(query.From) > maxSlotSearchDays*24*time.Hour {
+		return nil, myerrors.New(myerrors.ErrCodeValidation, "range must not exceed a year")
+	}
+
+	margin := max(s.marginDays(), 1+int(query.Buffer/(24*time.Hour)))
+	shoots, err := s.shootRepo.GetShoots(ctx, model.ShootFilter{
+		From: dateOnly(query.From).AddDate(0, 0, -margin),
+		To:   dateOnly(query.To).AddDate(0, 0, margin),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return freeSlots(shoots, query), nil
+}
+
+func freeSlots(shoots []model.Shoot, query model.SlotQuery) []model.Slot {
+	busy := make([]model.Slot, 0, len(shoots))
+	for i := range shoots {
+		start, end := shoots[i].Interval()
+		busy = append(busy, model.Slot{Start: start.Add(-query.Buffer), End: end.Add(query.Buffer)})
+	}
+	sort.Slice(busy, func(i, j int) bool {
+		return busy[i].Start.Before(busy[j].Start)
+	})
+
+	slots := make([]model.Slot, 0)
+	from := dateOnly(query.From)
+	to := dateOnly(query.To)
+
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		if isDayOff(day, query) {
+			continue
+		}
+
+		windowStart := day.Add(query.DayStart)
+		windowEnd := day.Add(query.DayEnd)
+		if !windowEnd.After(windowStart) {
+			windowEnd = windowEnd.AddDate(0, 0, 1)
+		}
+
+		cursor := windowStart
+		for _, b := range busy {
+			if !b.End.After(cursor) {
+				continue
+			}
+			if !b.Start.Before(windowEnd) {
+				break
+			}
+			if b.Start.Sub(cursor) >= query.Duration {
+				slots = append(slots, model.Slot{Start: cursor, End: b.Start})
+			}
+			cursor = b.End
+		}
+
+		if windowEnd.Sub(cursor) >= query.Duration {
+			slots = append(slots, model.Slot{Start: cursor, End: windowEnd})
+		}
+	}
+
+	return slots
+}
+
+func isDayOff(day time.Time, query model.SlotQuery) bool {
+	if slices.Contains(query.DaysOff, day.Weekday()) {
+		return true
+	}
+	for _, date := range query.DatesOff {
+		if dateOnly(date).Equal(day) {
+			return true
+		}
+	}
+	return false
+}
+
+func dateOnly(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
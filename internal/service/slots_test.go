@@ -0,0 +1,116 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Coiiap5e/photographer/internal/model"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestSlotsTestSuit(t *testing.T) {
+	suite.Run(t, new(SlotsTestSuit))
+}
+
+type SlotsTestSuit struct {
+	suite.Suite
+	monday time.Time
+}
+
+func (suite *SlotsTestSuit) SetupTest() {
+	suite.monday = time.Date(2025, 1, 20, 0, 0, 0, 0, time.UTC)
+}
+
+func (suite *SlotsTestSuit) shoot(day time.Time, start, end string) model.Shoot {
+	startTime, _ := time.Parse("15:04", start)
+	endTime, _ := time.Parse("15:04", end)
+	return model.Shoot{ShootDate: day, StartTime: startTime, EndTime: endTime}
+}
+
+func (suite *SlotsTestSuit) query(from, to time.Time) model.SlotQuery {
+	return model.SlotQuery{
+		From:     from,
+		To:       to,
+		DayStart: 10 * time.Hour,
+		DayEnd:   20 * time.Hour,
+		Duration: 2 * time.Hour,
+	}
+}
+
+func (suite *SlotsTestSuit) TestFreeSlots() {
+	suite.T().Run("should return whole working day when there are no shoots", func(t *testing.T) {
+		// When
+		slots := freeSlots(nil, suite.query(suite.monday, suite.monday))
+
+		// Then
+		suite.Require().Len(slots, 1, "should return one slot")
+		suite.Equal("Mon 20.01.2025 10:00-20:00", slots[0].String(), "should cover working hours")
+	})
+	suite.T().Run("should split the day around shoots with buffer", func(t *testing.T) {
+		// Given
+		shoots := []model.Shoot{suite.shoot(suite.monday, "13:00", "14:00")}
+		query := suite.query(suite.monday, suite.monday)
+		query.Buffer = 30 * time.Minute
+
+		// When
+		slots := freeSlots(shoots, query)
+
+		// Then
+		suite.Require().Len(slots, 2, "should return two slots")
+		suite.Equal("Mon 20.01.2025 10:00-12:30", slots[0].String(), "should end before buffer")
+		suite.Equal("Mon 20.01.2025 14:30-20:00", slots[1].String(), "should start after buffer")
+	})
+	suite.T().Run("should skip gaps shorter than duration", func(t *testing.T) {
+		// Given
+		shoots := []model.Shoot{
+			suite.shoot(suite.monday, "11:00", "12:00"),
+			suite.shoot(suite.monday, "13:00", "19:00"),
+		}
+
+		// When
+		slots := freeSlots(shoots, suite.query(suite.monday, suite.monday))
+
+		// Then
+		suite.Empty(slots, "should not return short gaps")
+	})
+	suite.T().Run("should respect days off", func(t *testing.T) {
+		// Given
+		query := suite.query(suite.monday, suite.monday.AddDate(0, 0, 6))
+		query.DaysOff = []time.Weekday{time.Saturday, time.Sunday}
+		query.DatesOff = []time.Time{suite.monday.AddDate(0, 0, 2)}
+
+		// When
+		slots := freeSlots(nil, query)
+
+		// Then
+		suite.Len(slots, 4, "should skip weekend and the day off")
+		for _, slot := range slots {
+			suite.NotEqual(time.Wednesday, slot.Start.Weekday(), "should skip date off")
+		}
+	})
+	suite.T().Run("should account for shoots crossing midnight", func(t *testing.T) {
+		// Given
+		sunday := suite.monday.AddDate(0, 0, -1)
+		shoots := []model.Shoot{suite.shoot(sunday, "22:00", "11:00")}
+
+		// When
+		slots := freeSlots(shoots, suite.query(suite.monday, suite.monday))
+
+		// Then
+		suite.Require().Len(slots, 1, "should return one slot")
+		suite.Equal("Mon 20.01.2025 11:00-20:00", slots[0].String(), "should start after night shoot")
+	})
+	suite.T().Run("should support working day ending after midnight", func(t *testing.T) {
+		// Given
+		query := suite.query(suite.monday, suite.monday)
+		query.DayStart = 18 * time.Hour
+		query.DayEnd = 2 * time.Hour
+
+		// When
+		slots := freeSlots(nil, query)
+
+		// Then
+		suite.Require().Len(slots, 1, "should return one slot")
+		suite.Equal("Mon 20.01.2025 18:00-02:00 (+1)", slots[0].String(), "should end next day")
+	})
+}
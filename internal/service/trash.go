@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/Coiiap5e/photographer/internal/config"
+	"github.com/Coiiap5e/photographer/internal/database"
+	myerrors "github.com/Coiiap5e/photographer/internal/errors"
+	"github.com/Coiiap5e/photographer/internal/model"
+	"github.com/Coiiap5e/photographer/internal/repository"
+)
+
+type Trash interface {
+	List(ctx context.Context) (*model.Trash, error)
+	RestoreClient(ctx context.Context, id int) error
+	RestoreShoot(ctx context.Context, id int) error
+	Purge(ctx context.Context, olderThan time.Duration) (clients, shoots int, err error)
+	Retention() time.Duration
+}
+
+type postgresTrash struct {
+	transactor database.Transactor
+	clientRepo repository.Client
+	shootRepo  repository.Shoot
+	trash      config.TrashConfig
+}
+
+// NewTrash returns the service for deleted clients and shoots. Purging
+// without an explicit age keeps items deleted within trash.Retention.
+func NewTrash(transactor database.Transactor, clientRepo repository.Client,
+	shootRepo repository.Shoot, trash config.TrashConfig) Trash {
+	return &postgresTrash{
+		transactor: transactor,
+		clientRepo: clientRepo,
+		shootRepo:  shootRepo,
+		trash:      trash,
+	}
+}
+
+func (t *postgresTrash) Retention() time.Duration {
+	return t.trash.Retention
+}
+
+// List returns everything in the trash, most recently deleted first
+func (t *postgresTrash) List(ctx context.Context) (*model.Trash, error) {
+	clients, err := t.clientRepo.GetDeletedClients(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	shoots, err := t.shootRepo.GetDeletedShoots(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.Trash{Clients: clients, Shoots: shoots}, nil
+}
+
+// RestoreClient brings back the client with the shoots deleted together
+// with it
+func (t *postgresTrash) RestoreClient(ctx context.Context, id int) error {
+	return t.clientRepo.RestoreClient(ctx, id)
+}
+
+func (t *postgresTrash) RestoreShoot(ctx context.Context, id int) error {
+	return t.shootRepo.RestoreShoot(ctx, id)
+}
+
+// Purge permanently removes clients and shoots deleted more than olderThan
+// ago in one transaction and returns how many of each were removed. Shoots
+// of a purged client are counted as shoots.
+func (t *postgresTrash) Purge(ctx context.Context, olderThan time.Duration) (int, int, error) {
+	if olderThan < 0 {
+		return 0, 0, myerrors.New(myerrors.ErrCodeValidation, "age must not be negative")
+	}
+
+	var clients, shoots int
+	err := t.transactor.WithTx(ctx, func(ctx context.Context) error {
+		var err error
+		if shoots, err = t.shootRepo.PurgeShoots(ctx, olderThan); err != nil {
+			return err
+		}
+		clients, err = t.clientRepo.PurgeClients(ctx, olderThan)
+		return err
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return clients, shoots, nil
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Coiiap5e/photographer/internal/config"
+	"github.com/Coiiap5e/photographer/internal/errors"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestTrashTestSuit(t *testing.T) {
+	suite.Run(t, new(TrashTestSuit))
+}
+
+type TrashTestSuit struct {
+	suite.Suite
+	ctx        context.Context
+	transactor *mockTransactor
+	clientRepo *mockClientRepo
+	shootRepo  *mockShootRepo
+	svc        Trash
+}
+
+func (suite *TrashTestSuit) SetupTest() {
+	suite.ctx = context.Background()
+	suite.transactor = &mockTransactor{}
+	suite.clientRepo = &mockClientRepo{}
+	suite.shootRepo = &mockShootRepo{}
+	suite.svc = NewTrash(suite.transactor, suite.clientRepo, suite.shootRepo,
+		config.TrashConfig{Retention: 30 * 24 * time.Hour})
+}
+
+func (suite *TrashTestSuit) TestPurge() {
+	suite.T().Run("should purge shoots and clients in one transaction", func(t *testing.T) {
+		// Given
+		var ages []time.Duration
+		suite.shootRepo.purgeShoots = func(_ context.Context, olderThan time.Duration) (int, error) {
+			ages = append(ages, olderThan)
+			return 3, nil
+		}
+		suite.clientRepo.purgeClients = func(_ context.Context, olderThan time.Duration) (int, error) {
+			ages = append(ages, olderThan)
+			return 1, nil
+		}
+
+		// When
+		clients, shoots, err := suite.svc.Purge(suite.ctx, time.Hour)
+
+		// Then
+		suite.Require().NoError(err, "should purge trash")
+		suite.Equal(1, clients, "should count purged clients")
+		suite.Equal(3, shoots, "should count purged shoots")
+		suite.Equal([]time.Duration{time.Hour, time.Hour}, ages, "should pass the age to both repositories")
+		suite.Equal(1, suite.transactor.started, "should run in one transaction")
+	})
+	suite.T().Run("should roll back when purging clients fails", func(t *testing.T) {
+		// Given
+		suite.transactor.started, suite.transactor.rolledBack = 0, 0
+		suite.shootRepo.purgeShoots = func(context.Context, time.Duration) (int, error) {
+			return 3, nil
+		}
+		suite.clientRepo.purgeClients = func(context.Context, time.Duration) (int, error) {
+			return 0, errors.New(errors.ErrCodeClientDelete, "failed to purge clients")
+		}
+
+		// When
+		clients, shoots, err := suite.svc.Purge(suite.ctx, time.Hour)
+
+		// Then
+		suite.True(errors.IsErrorCode(err, errors.ErrCodeClientDelete), "should return the repository error")
+		suite.Zero(clients+shoots, "should not report purged items")
+		suite.Equal(1, suite.transactor.rolledBack, "should roll back the transaction")
+	})
+	suite.T().Run("should reject negative age", func(t *testing.T) {
+		// Given
+		suite.transactor.started = 0
+
+		// When
+		_, _, err := suite.svc.Purge(suite.ctx, -time.Hour)
+
+		// Then
+		suite.True(errors.IsErrorCode(err, errors.ErrCodeValidation), "should return validation error")
+		suite.Zero(suite.transactor.started, "should not start a transaction")
+	})
+}
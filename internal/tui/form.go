@@ -0,0 +1,159 @@
+package tui
+
+import (
+	"context"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Coiiap5e/photographer/internal/validation"
+)
+
+// field is one line of a form. check validates the value when the user
+// leaves the field and before the form is saved, name matches the field
+// to the validation errors of the service.
+type field struct {
+	label string
+	name  string
+	value string
+	check func(value string) error
+	err   string
+}
+
+// form edits a client or a shoot inline. save gets the values in field
+// order and returns the status to show once it succeeds; on an error the
+// form stays open with the error below the fields.
+type form struct {
+	title  string
+	fields []field
+	focus  int
+	err    string
+	save   func(ctx context.Context, values []string) (string, error)
+}
+
+type formAction int
+
+const (
+	formKeep formAction = iota
+	formSave
+	formCancel
+)
+
+func (f *form) handle(k key) formAction {
+	current := &f.fields[f.focus]
+
+	switch k.typ {
+	case keyRune:
+		current.value += string(k.rune)
+		current.err = ""
+	case keyBackspace:
+		if _, size := utf8.DecodeLastRuneInString(current.value); size > 0 {
+			current.value = current.value[:len(current.value)-size]
+		}
+		current.err = ""
+	case keyTab, keyDown:
+		f.checkField(f.focus)
+		f.focus = (f.focus + 1) % len(f.fields)
+	case keyShiftTab, keyUp:
+		f.checkField(f.focus)
+		f.focus = (f.focus + len(f.fields) - 1) % len(f.fields)
+	case keyEnter:
+		if f.valid() {
+			return formSave
+		}
+	case keyEsc:
+		return formCancel
+	}
+
+	return formKeep
+}
+
+func (f *form) checkField(i int) bool {
+	current := &f.fields[i]
+	current.err = ""
+	if current.check == nil {
+		return true
+	}
+	if err := current.check(current.value); err != nil {
+		current.err = err.Error()
+		return false
+	}
+	return true
+}
+
+// valid checks every field and moves the focus to the first broken one
+func (f *form) valid() bool {
+	first := -1
+	for i := range f.fields {
+		if !f.checkField(i) && first < 0 {
+			first = i
+		}
+	}
+	if first >= 0 {
+		f.focus = first
+		return false
+	}
+	return true
+}
+
+// showFields puts the service validation errors under their fields and
+// moves the focus to the first one. It returns false when a field is not
+// in the form.
+func (f *form) showFields(errs validation.Errors) bool {
+	first := -1
+	for _, fieldErr := range errs {
+		found := false
+		for i := range f.fields {
+			if f.fields[i].name == fieldErr.Field {
+				found = true
+				f.fields[i].err = fieldErr.Message
+				if first < 0 || i < first {
+					first = i
+				}
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if first < 0 {
+		return false
+	}
+	f.focus = first
+	return true
+}
+
+func (f *form) values() []string {
+	values := make([]string, len(f.fields))
+	for i, field := range f.fields {
+		values[i] = strings.TrimSpace(field.value)
+	}
+	return values
+}
+
+func (f *form) lines(width int) []string {
+	labelWidth := 0
+	for _, field := range f.fields {
+		labelWidth = max(labelWidth, utf8.RuneCountInString(field.label))
+	}
+
+	lines := []string{bold(f.title), ""}
+	for i, field := range f.fields {
+		marker, value := "  ", field.value
+		if i == f.focus {
+			marker, value = "> ", value+"_"
+		}
+		lines = append(lines, fit(marker+pad(field.label, labelWidth)+"  "+value, width))
+		if field.err != "" {
+			lines = append(lines, red(fit(strings.Repeat(" ", labelWidth+4)+field.err, width)))
+		}
+	}
+
+	if f.err != "" {
+		lines = append(lines, "")
+		for _, line := range wrap(f.err, width) {
+			lines = append(lines, red(line))
+		}
+	}
+
+	return lines
+}
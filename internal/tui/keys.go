@@ -0,0 +1,127 @@
+package tui
+
+import (
+	"unicode/utf8"
+)
+
+type keyType int
+
+const (
+	keyRune keyType = iota
+	keyEnter
+	keyTab
+	keyShiftTab
+	keyBackspace
+	keyEsc
+	keyUp
+	keyDown
+	keyLeft
+	keyRight
+	keyHome
+	keyEnd
+	keyPageUp
+	keyPageDown
+	keyCtrlC
+)
+
+// key is one key press, rune is set for keyRune
+type key struct {
+	typ  keyType
+	rune rune
+}
+
+func runeKey(r rune) key {
+	return key{typ: keyRune, rune: r}
+}
+
+// escapeKeys are the sequences terminals send for keys that have no
+// character, without the leading ESC
+var escapeKeys = map[string]keyType{
+	"[A":  keyUp,
+	"[B":  keyDown,
+	"[C":  keyRight,
+	"[D":  keyLeft,
+	"OA":  keyUp,
+	"OB":  keyDown,
+	"OC":  keyRight,
+	"OD":  keyLeft,
+	"[H":  keyHome,
+	"[F":  keyEnd,
+	"OH":  keyHome,
+	"OF":  keyEnd,
+	"[1~": keyHome,
+	"[4~": keyEnd,
+	"[5~": keyPageUp,
+	"[6~": keyPageDown,
+	"[Z":  keyShiftTab,
+}
+
+// parseKeys decodes the bytes of one read from a terminal in raw mode. A
+// terminal writes an escape sequence at once, so an ESC that does not start
+// a known sequence is the Esc key. Unknown sequences are dropped.
+func parseKeys(data []byte) []key {
+	var keys []key
+
+	for len(data) > 0 {
+		switch b := data[0]; b {
+		case 0x1b:
+			typ, n := parseEscape(data[1:])
+			if n < 0 {
+				keys = append(keys, key{typ: keyEsc})
+				data = data[1:]
+				continue
+			}
+			if typ >= 0 {
+				keys = append(keys, key{typ: typ})
+			}
+			data = data[1+n:]
+			continue
+		case '\r', '\n':
+			keys = append(keys, key{typ: keyEnter})
+		case '\t':
+			keys = append(keys, key{typ: keyTab})
+		case 0x7f, 0x08:
+			keys = append(keys, key{typ: keyBackspace})
+		case 0x03:
+			keys = append(keys, key{typ: keyCtrlC})
+		default:
+			if b < 0x20 {
+				break
+			}
+			r, size := utf8.DecodeRune(data)
+			if r != utf8.RuneError {
+				keys = append(keys, runeKey(r))
+			}
+			data = data[size:]
+			continue
+		}
+		data = data[1:]
+	}
+
+	return keys
+}
+
+// parseEscape returns the key of the sequence at the start of data and its
+// length. The type is -1 for a sequence that is not known, the length is
+// -1 when data does not start a sequence at all.
+func parseEscape(data []byte) (keyType, int) {
+	if len(data) < 2 || (data[0] != '[' && data[0] != 'O') {
+		return 0, -1
+	}
+
+	// a sequence ends with a letter or ~ after optional digits and ;
+	end := 1
+	for end < len(data) && (data[end] >= '0' && data[end] <= '9' || data[end] == ';') {
+		end++
+	}
+	if end == len(data) {
+		return 0, -1
+	}
+	end++
+
+	typ, ok := escapeKeys[string(data[:end])]
+	if !ok {
+		return -1, end
+	}
+	return typ, end
+}
@@ -0,0 +1,76 @@
+package tui
+
+import (
+	"context"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/Coiiap5e/photographer/internal/errors"
+	"golang.org/x/term"
+)
+
+const (
+	screenEnter = "\x1b[?1049h\x1b[?25l"
+	screenLeave = "\x1b[?25h\x1b[?1049l"
+	screenHome  = "\x1b[H"
+	clearLine   = "\x1b[K"
+	clearBelow  = "\x1b[J"
+)
+
+// Run shows the UI on the terminal in until the user quits. The terminal
+// is switched to raw mode and the alternate screen, both are restored on
+// return.
+func (u *UI) Run(ctx context.Context, in *os.File, out io.Writer) error {
+	fd := int(in.Fd())
+	if !term.IsTerminal(fd) {
+		return errors.New(errors.ErrCodeInvalidInput, "the terminal UI needs an interactive terminal")
+	}
+
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to switch the terminal to raw mode")
+	}
+	defer term.Restore(fd, state)
+
+	io.WriteString(out, screenEnter)
+	defer io.WriteString(out, screenLeave)
+
+	u.load(ctx, 0)
+
+	buf := make([]byte, 64)
+	for {
+		width, height, err := term.GetSize(fd)
+		if err != nil {
+			width, height = 80, 24
+		}
+		io.WriteString(out, render(u.view(width, height)))
+
+		n, err := in.Read(buf)
+		if err != nil {
+			return nil
+		}
+
+		for _, k := range parseKeys(buf[:n]) {
+			if u.handle(ctx, k) {
+				return nil
+			}
+		}
+	}
+}
+
+// render draws lines from the top left corner, in raw mode a new line
+// needs a carriage return
+func render(lines []string) string {
+	var b strings.Builder
+	b.WriteString(screenHome)
+	for i, line := range lines {
+		if i > 0 {
+			b.WriteString("\r\n")
+		}
+		b.WriteString(line)
+		b.WriteString(clearLine)
+	}
+	b.WriteString(clearBelow)
+	return b.String()
+}
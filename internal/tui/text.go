@@ -0,0 +1,77 @@
+package tui
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	styleReset   = "\x1b[0m"
+	styleBold    = "\x1b[1m"
+	styleReverse = "\x1b[7m"
+	styleRed     = "\x1b[31m"
+	styleDim     = "\x1b[2m"
+)
+
+func bold(s string) string    { return styleBold + s + styleReset }
+func reverse(s string) string { return styleReverse + s + styleReset }
+func red(s string) string     { return styleRed + s + styleReset }
+func dim(s string) string     { return styleDim + s + styleReset }
+
+// fit cuts s to width runes, marking the cut with an ellipsis
+func fit(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	if utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:width-1]) + "…"
+}
+
+// pad fits s to width and fills the rest with spaces
+func pad(s string, width int) string {
+	s = fit(s, width)
+	if n := utf8.RuneCountInString(s); n < width {
+		s += strings.Repeat(" ", width-n)
+	}
+	return s
+}
+
+// wrap breaks s into lines of at most width runes at spaces, words longer
+// than a line are split
+func wrap(s string, width int) []string {
+	if width <= 0 {
+		return nil
+	}
+
+	var lines []string
+	for _, paragraph := range strings.Split(s, "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			for utf8.RuneCountInString(word) > width {
+				if line != "" {
+					lines = append(lines, line)
+					line = ""
+				}
+				runes := []rune(word)
+				lines = append(lines, string(runes[:width]))
+				word = string(runes[width:])
+			}
+
+			switch {
+			case line == "":
+				line = word
+			case utf8.RuneCountInString(line)+1+utf8.RuneCountInString(word) <= width:
+				line += " " + word
+			default:
+				lines = append(lines, line)
+				line = word
+			}
+		}
+		lines = append(lines, line)
+	}
+
+	return lines
+}
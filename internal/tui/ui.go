@@ -0,0 +1,562 @@
+// Package tui is a full-screen terminal interface for the clients and
+// shoots. It draws a client list, the shoots of the selected client and
+// the details of the selected item, and edits them in inline forms.
+package tui
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"strings"
+
+	"github.com/Coiiap5e/photographer/internal/errors"
+	"github.com/Coiiap5e/photographer/internal/model"
+	"github.com/Coiiap5e/photographer/internal/service"
+	"github.com/Coiiap5e/photographer/internal/utils"
+	"github.com/Coiiap5e/photographer/internal/validation"
+)
+
+type pane int
+
+const (
+	paneClients pane = iota
+	paneShoots
+)
+
+// confirm is a yes/no question shown in the status line
+type confirm struct {
+	question string
+	yes      func(ctx context.Context) (string, error)
+}
+
+type UI struct {
+	clientService service.Client
+	shootService  service.Shoot
+
+	focus   pane
+	clients []model.Client
+	shoots  []model.Shoot
+	// client and shoot are the selected rows, the top fields the first
+	// rows shown when a list is longer than its pane
+	client, clientTop int
+	shoot, shootTop   int
+
+	searching bool
+	query     string
+
+	form    *form
+	confirm *confirm
+
+	status    string
+	statusErr bool
+}
+
+func New(clientService service.Client, shootService service.Shoot) *UI {
+	return &UI{clientService: clientService, shootService: shootService}
+}
+
+func (u *UI) selectedClient() *model.Client {
+	if u.client < len(u.clients) {
+		return &u.clients[u.client]
+	}
+	return nil
+}
+
+func (u *UI) selectedShoot() *model.Shoot {
+	if u.shoot < len(u.shoots) {
+		return &u.shoots[u.shoot]
+	}
+	return nil
+}
+
+func (u *UI) setStatus(status string, err error) {
+	u.status, u.statusErr = status, false
+	if err != nil {
+		u.status, u.statusErr = err.Error(), true
+	}
+}
+
+// load reads the clients matching the search and the shoots of the
+// selected client. The client with keepId stays selected if it is still
+// listed.
+func (u *UI) load(ctx context.Context, keepId int) {
+	var clients []model.Client
+	var err error
+
+	if query := strings.TrimSpace(u.query); query != "" {
+		clients, err = u.clientService.SearchClients(ctx, query)
+	} else {
+		var page *model.ClientPage
+		if page, err = u.clientService.FindClients(ctx, model.ClientFilter{}); err == nil {
+			clients = page.Clients
+		}
+	}
+	if err != nil {
+		u.setStatus("", err)
+		return
+	}
+
+	u.clients, u.client = clients, 0
+	for i, client := range clients {
+		if client.Id == keepId {
+			u.client = i
+		}
+	}
+
+	u.loadShoots(ctx, 0)
+}
+
+// loadShoots reads the shoots of the selected client, keeping the shoot
+// with keepId selected
+func (u *UI) loadShoots(ctx context.Context, keepId int) {
+	u.shoots, u.shoot, u.shootTop = nil, 0, 0
+
+	client := u.selectedClient()
+	if client == nil {
+		return
+	}
+
+	page, err := u.shootService.FindShoots(ctx, model.ShootFilter{ClientId: client.Id})
+	if err != nil {
+		u.setStatus("", err)
+		return
+	}
+
+	u.shoots = page.Shoots
+	for i, shoot := range u.shoots {
+		if shoot.Id == keepId {
+			u.shoot = i
+		}
+	}
+}
+
+// handle applies one key press and reports whether the UI should close
+func (u *UI) handle(ctx context.Context, k key) bool {
+	if k.typ == keyCtrlC {
+		return true
+	}
+
+	switch {
+	case u.form != nil:
+		u.handleForm(ctx, k)
+	case u.confirm != nil:
+		u.handleConfirm(ctx, k)
+	case u.searching:
+		u.handleSearch(ctx, k)
+	default:
+		return u.handleList(ctx, k)
+	}
+
+	return false
+}
+
+func (u *UI) handleForm(ctx context.Context, k key) {
+	switch u.form.handle(k) {
+	case formCancel:
+		u.form = nil
+		u.setStatus("", nil)
+	case formSave:
+		status, err := u.form.save(ctx, u.form.values())
+		if err != nil {
+			u.form.err = ""
+			if !u.form.showFields(validation.Fields(err)) {
+				u.form.err = err.Error()
+			}
+			return
+		}
+		u.form = nil
+		u.setStatus(status, nil)
+	}
+}
+
+func (u *UI) handleConfirm(ctx context.Context, k key) {
+	if k.typ != keyRune {
+		if k.typ == keyEsc {
+			u.confirm = nil
+			u.setStatus("Deletion cancelled", nil)
+		}
+		return
+	}
+
+	switch k.rune {
+	case 'y', 'Y':
+		yes := u.confirm.yes
+		u.confirm = nil
+		u.setStatus(yes(ctx))
+	case 'n', 'N':
+		u.confirm = nil
+		u.setStatus("Deletion cancelled", nil)
+	}
+}
+
+// handleSearch edits the search query, the client list follows every key
+func (u *UI) handleSearch(ctx context.Context, k key) {
+	keepId := 0
+	if client := u.selectedClient(); client != nil {
+		keepId = client.Id
+	}
+
+	switch k.typ {
+	case keyRune:
+		u.query += string(k.rune)
+	case keyBackspace:
+		runes := []rune(u.query)
+		if len(runes) == 0 {
+			return
+		}
+		u.query = string(runes[:len(runes)-1])
+	case keyEnter:
+		u.searching = false
+		return
+	case keyEsc:
+		u.searching, u.query = false, ""
+	case keyUp, keyDown:
+		u.move(k)
+		return
+	default:
+		return
+	}
+
+	u.clientTop = 0
+	u.load(ctx, keepId)
+}
+
+func (u *UI) handleList(ctx context.Context, k key) bool {
+	switch k.typ {
+	case keyTab, keyShiftTab, keyLeft, keyRight:
+		if u.focus == paneClients && u.selectedClient() != nil && k.typ != keyLeft {
+			u.focus = paneShoots
+		} else if k.typ != keyRight {
+			u.focus = paneClients
+		}
+		return false
+	case keyUp, keyDown, keyHome, keyEnd, keyPageUp, keyPageDown:
+		u.move(k)
+		if u.focus == paneClients {
+			u.loadShoots(ctx, 0)
+		}
+		return false
+	case keyEsc:
+		if u.query != "" {
+			u.query = ""
+			u.load(ctx, 0)
+		}
+		return false
+	case keyRune:
+	default:
+		return false
+	}
+
+	u.setStatus("", nil)
+
+	switch k.rune {
+	case 'q':
+		return true
+	case 'j':
+		u.handleList(ctx, key{typ: keyDown})
+	case 'k':
+		u.handleList(ctx, key{typ: keyUp})
+	case '/':
+		u.searching = true
+	case 'r':
+		shootId := u.selectedId(paneShoots)
+		u.load(ctx, u.selectedId(paneClients))
+		u.loadShoots(ctx, shootId)
+	case 'a':
+		u.add()
+	case 'e':
+		u.edit()
+	case 'd':
+		u.remove()
+	}
+
+	return false
+}
+
+func (u *UI) selectedId(p pane) int {
+	if p == paneClients {
+		if client := u.selectedClient(); client != nil {
+			return client.Id
+		}
+		return 0
+	}
+	if shoot := u.selectedShoot(); shoot != nil {
+		return shoot.Id
+	}
+	return 0
+}
+
+// move changes the selected row of the focused list
+func (u *UI) move(k key) {
+	selected, count := &u.client, len(u.clients)
+	if u.focus == paneShoots {
+		selected, count = &u.shoot, len(u.shoots)
+	}
+	if count == 0 {
+		return
+	}
+
+	switch k.typ {
+	case keyUp:
+		*selected--
+	case keyDown:
+		*selected++
+	case keyPageUp:
+		*selected -= 10
+	case keyPageDown:
+		*selected += 10
+	case keyHome:
+		*selected = 0
+	case keyEnd:
+		*selected = count - 1
+	}
+	*selected = min(max(*selected, 0), count-1)
+}
+
+func (u *UI) add() {
+	if u.focus == paneClients {
+		u.form = u.clientForm(nil)
+		return
+	}
+	if client := u.selectedClient(); client != nil {
+		u.form = u.shootForm(client, nil)
+	}
+}
+
+func (u *UI) edit() {
+	if u.focus == paneClients {
+		if client := u.selectedClient(); client != nil {
+			u.form = u.clientForm(client)
+		}
+		return
+	}
+	if shoot := u.selectedShoot(); shoot != nil {
+		u.form = u.shootForm(u.selectedClient(), shoot)
+	}
+}
+
+func (u *UI) remove() {
+	if u.focus == paneClients {
+		client := u.selectedClient()
+		if client == nil {
+			return
+		}
+		u.confirm = &confirm{
+			question: fmt.Sprintf("Move client %s %s and all their shoots to the trash?",
+				client.FirstName, client.LastName),
+			yes: func(ctx context.Context) (string, error) {
+				if err := u.clientService.DeleteClient(ctx, client.Id); err != nil {
+					return "", err
+				}
+				u.load(ctx, 0)
+				return fmt.Sprintf("client %s %s moved to trash", client.FirstName, client.LastName), nil
+			},
+		}
+		return
+	}
+
+	shoot := u.selectedShoot()
+	if shoot == nil {
+		return
+	}
+	u.confirm = &confirm{
+		question: fmt.Sprintf("Move shoot %s %s to the trash?",
+			shoot.ShootDate.Format("02.01.2006"), shoot.StartTime.Format("15:04")),
+		yes: func(ctx context.Context) (string, error) {
+			if err := u.shootService.DeleteShoot(ctx, shoot.Id); err != nil {
+				return "", err
+			}
+			u.loadShoots(ctx, 0)
+			if len(u.shoots) == 0 {
+				u.focus = paneClients
+			}
+			return fmt.Sprintf("shoot %s moved to trash", shoot.ShootDate.Format("02.01.2006")), nil
+		},
+	}
+}
+
+func required(value string) error {
+	if strings.TrimSpace(value) == "" {
+		return fmt.Errorf("field cannot be empty")
+	}
+	return nil
+}
+
+func checkDate(value string) error {
+	if _, err := utils.ParseDate(value); err != nil {
+		return fmt.Errorf("use format dd.mm.yyyy (for example: 20.01.2025)")
+	}
+	return nil
+}
+
+func checkTime(value string) error {
+	if _, err := utils.ParseClock(value); err != nil {
+		return fmt.Errorf("use format hh:mm (for example: 15:04)")
+	}
+	return nil
+}
+
+// clientForm adds a client, or edits client when it is not nil
+func (u *UI) clientForm(client *model.Client) *form {
+	title, current := "New client", model.Client{}
+	if client != nil {
+		title, current = fmt.Sprintf("Edit client #%d", client.Id), *client
+	}
+
+	return &form{
+		title: title,
+		fields: []field{
+			{label: "First name", name: validation.FieldFirstName, value: current.FirstName, check: required},
+			{label: "Last name", name: validation.FieldLastName, value: current.LastName, check: required},
+			{label: "Phone number", name: validation.FieldPhone, value: current.Phone, check: required},
+			{label: "Social network url", name: validation.FieldSocialNetworkUrl, value: current.SocialNetworkUrl},
+		},
+		save: func(ctx context.Context, values []string) (string, error) {
+			if client == nil {
+				added := &model.Client{FirstName: values[0], LastName: values[1],
+					Phone: values[2], SocialNetworkUrl: values[3]}
+				if err := u.clientService.CreateClient(ctx, added); err != nil {
+					return "", err
+				}
+				u.focus = paneClients
+				u.load(ctx, added.Id)
+				return fmt.Sprintf("client added with ID: %d", added.Id), nil
+			}
+
+			update := &model.ClientUpdate{}
+			changed := func(value, current string) *string {
+				if value == current {
+					return nil
+				}
+				return &value
+			}
+			update.FirstName = changed(values[0], client.FirstName)
+			update.LastName = changed(values[1], client.LastName)
+			update.Phone = changed(values[2], client.Phone)
+			update.SocialNetworkUrl = changed(values[3], client.SocialNetworkUrl)
+			if *update == (model.ClientUpdate{}) {
+				return "Nothing to change", nil
+			}
+
+			updated, err := u.clientService.UpdateClient(ctx, client.Id, client.Version, update)
+			if err != nil {
+				if errors.IsErrorCode(err, errors.ErrCodeVersionConflict) {
+					return "", fmt.Errorf("client was changed by someone else, press Esc and reload with r")
+				}
+				return "", err
+			}
+			u.load(ctx, updated.Id)
+			return fmt.Sprintf("client %s %s updated", updated.FirstName, updated.LastName), nil
+		},
+	}
+}
+
+// shootForm adds a shoot for client, or edits shoot when it is not nil. A
+// shoot that overlaps another one is saved when Enter is pressed again
+// without changing it.
+func (u *UI) shootForm(client *model.Client, shoot *model.Shoot) *form {
+	currency := u.shootService.DefaultCurrency()
+	title, current := fmt.Sprintf("New shoot for %s %s", client.FirstName, client.LastName), model.Shoot{}
+	var date, start, end, price string
+	if shoot != nil {
+		title, current = fmt.Sprintf("Edit shoot #%d", shoot.Id), *shoot
+		date = shoot.ShootDate.Format("02.01.2006")
+		start, end = shoot.StartTime.Format("15:04"), shoot.EndTime.Format("15:04")
+		price = shoot.ShootPrice.String()
+	}
+
+	checkPrice := func(value string) error {
+		if _, err := model.ParseMoney(value, currency); err != nil {
+			return fmt.Errorf("%v (for example: 1500.50 or 20 USD)", err)
+		}
+		return nil
+	}
+
+	// overlapping holds the values the last conflict was reported for
+	var overlapping string
+
+	return &form{
+		title: title,
+		fields: []field{
+			{label: "Shoot date", name: validation.FieldDate, value: date, check: checkDate},
+			{label: "Start time", name: validation.FieldStartTime, value: start, check: checkTime},
+			{label: "End time", name: validation.FieldEndTime, value: end, check: checkTime},
+			{label: "Price, " + currency, name: validation.FieldShootPrice, value: price, check: checkPrice},
+			{label: "Location", name: validation.FieldLocation, value: current.ShootLocation, check: required},
+			{label: "Shoot type", name: validation.FieldShootType, value: current.ShootType, check: required},
+			{label: "Notes", value: current.Notes},
+		},
+		save: func(ctx context.Context, values []string) (string, error) {
+			shootDate, _ := utils.ParseDate(values[0])
+			startTime, _ := utils.ParseTime(shootDate, values[1])
+			endTime, _ := utils.ParseTime(shootDate, values[2])
+			shootPrice, _ := model.ParseMoney(values[3], currency)
+			allowOverlap := overlapping == strings.Join(values, "\n")
+
+			var saved *model.Shoot
+			var err error
+			if shoot == nil {
+				saved = &model.Shoot{ClientId: client.Id, ShootDate: shootDate, StartTime: startTime,
+					EndTime: endTime, ShootPrice: shootPrice, ShootLocation: values[4],
+					ShootType: values[5], Notes: values[6], AllowOverlap: allowOverlap}
+				err = u.shootService.CreateShoot(ctx, saved)
+			} else {
+				update := &model.ShootUpdate{}
+				if values[0] != date {
+					update.ShootDate = &shootDate
+				}
+				if values[1] != start {
+					update.StartTime = &startTime
+				}
+				if values[2] != end {
+					update.EndTime = &endTime
+				}
+				if shootPrice != shoot.ShootPrice {
+					update.ShootPrice = &shootPrice
+				}
+				if values[4] != shoot.ShootLocation {
+					update.ShootLocation = &values[4]
+				}
+				if values[5] != shoot.ShootType {
+					update.ShootType = &values[5]
+				}
+				if values[6] != shoot.Notes {
+					update.Notes = &values[6]
+				}
+				if *update == (model.ShootUpdate{}) {
+					return "Nothing to change", nil
+				}
+				if allowOverlap {
+					update.AllowOverlap = &allowOverlap
+				}
+				saved, err = u.shootService.UpdateShoot(ctx, shoot.Id, shoot.Version, update)
+			}
+
+			if err != nil {
+				if errors.IsErrorCode(err, errors.ErrCodeShootConflict) {
+					overlapping = strings.Join(values, "\n")
+					return "", fmt.Errorf("the shoot overlaps with %s. Press Enter again to book anyway",
+						conflicts(err))
+				}
+				if errors.IsErrorCode(err, errors.ErrCodeVersionConflict) {
+					return "", fmt.Errorf("shoot was changed by someone else, press Esc and reload with r")
+				}
+				return "", err
+			}
+
+			u.focus = paneShoots
+			u.loadShoots(ctx, saved.Id)
+			return fmt.Sprintf("shoot %s %s saved", saved.ShootDate.Format("02.01.2006"),
+				saved.StartTime.Format("15:04")), nil
+		},
+	}
+}
+
+// conflicts lists the shoots of a conflict error
+func conflicts(err error) string {
+	var conflictErr *service.ConflictError
+	if !stderrors.As(err, &conflictErr) {
+		return "another shoot"
+	}
+	return conflictErr.Error()
+}
@@ -0,0 +1,233 @@
+package tui
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Coiiap5e/photographer/internal/config"
+	"github.com/Coiiap5e/photographer/internal/repository"
+	"github.com/Coiiap5e/photographer/internal/service"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestUITestSuit(t *testing.T) {
+	suite.Run(t, new(UITestSuit))
+}
+
+type UITestSuit struct {
+	suite.Suite
+	ctx           context.Context
+	clientService service.Client
+	shootService  service.Shoot
+	ui            *UI
+}
+
+func (suite *UITestSuit) SetupTest() {
+	suite.ctx = context.Background()
+
+	store := repository.NewMemoryStore()
+	clientRepo := repository.NewMemoryClient(store)
+	suite.clientService = service.NewClient(clientRepo)
+	suite.shootService = service.NewShoot(repository.NewMemoryShoot(store), clientRepo,
+		config.ScheduleConfig{}, config.MoneyConfig{DefaultCurrency: "RUB"})
+	suite.ui = New(suite.clientService, suite.shootService)
+}
+
+// press sends keys to the UI, a string is typed rune by rune and the other
+// keys are sent as they are
+func (suite *UITestSuit) press(keys ...any) {
+	for _, k := range keys {
+		switch k := k.(type) {
+		case string:
+			for _, r := range k {
+				suite.ui.handle(suite.ctx, runeKey(r))
+			}
+		case keyType:
+			suite.ui.handle(suite.ctx, key{typ: k})
+		}
+	}
+}
+
+var escapeCodes = regexp.MustCompile("\x1b\\[[0-9;?]*[a-zA-Z]")
+
+// screen returns the text of the screen without the styles
+func (suite *UITestSuit) screen(width, height int) string {
+	return escapeCodes.ReplaceAllString(strings.Join(suite.ui.view(width, height), "\n"), "")
+}
+
+func (suite *UITestSuit) addClient(firstName, lastName, phone string) {
+	suite.press("a", firstName, keyTab, lastName, keyTab, phone, keyEnter)
+}
+
+func (suite *UITestSuit) TestParseKeys() {
+	suite.T().Run("should decode escape sequences and text", func(t *testing.T) {
+		// When
+		keys := parseKeys([]byte("\x1b[Aя\r\x1b[Z\x1b[5~\x7f"))
+
+		// Then
+		suite.Equal([]key{{typ: keyUp}, runeKey('я'), {typ: keyEnter}, {typ: keyShiftTab},
+			{typ: keyPageUp}, {typ: keyBackspace}}, keys, "should decode every key")
+	})
+	suite.T().Run("should read a lone escape as the Esc key", func(t *testing.T) {
+		// When
+		keys := parseKeys([]byte("\x1bq"))
+
+		// Then
+		suite.Equal([]key{{typ: keyEsc}, runeKey('q')}, keys, "should decode Esc followed by q")
+	})
+	suite.T().Run("should drop unknown sequences", func(t *testing.T) {
+		// When
+		keys := parseKeys([]byte("\x1b[15~x"))
+
+		// Then
+		suite.Equal([]key{runeKey('x')}, keys, "should keep only the known key")
+	})
+}
+
+func (suite *UITestSuit) TestAddClient() {
+	suite.T().Run("should add a client from the form and select it", func(t *testing.T) {
+		// When
+		suite.addClient("Anna", "Ivanova", "+79990001122")
+
+		// Then
+		suite.Nil(suite.ui.form, "should close the form")
+		suite.Equal("client added with ID: 1", suite.ui.status, "should report the new client")
+		suite.Contains(suite.screen(80, 20), "> Ivanova Anna, +79990001122", "should select the new client")
+	})
+	suite.T().Run("should keep the form open on an empty required field", func(t *testing.T) {
+		// When
+		suite.press("a", "Oleg", keyEnter)
+
+		// Then
+		suite.Require().NotNil(suite.ui.form, "should keep the form open")
+		suite.Equal(1, suite.ui.form.focus, "should focus the first empty field")
+		suite.Contains(suite.screen(80, 20), "field cannot be empty", "should show the field error")
+
+		// When
+		suite.press(keyEsc)
+
+		// Then
+		suite.Nil(suite.ui.form, "should close the form on Esc")
+		suite.Len(suite.ui.clients, 1, "should not add a client")
+	})
+	suite.T().Run("should show service validation errors under their fields", func(t *testing.T) {
+		// When
+		suite.press("a", "Oleg", keyTab, "Petrov", keyTab, "12-34", keyTab, "vk", keyEnter)
+
+		// Then
+		suite.Require().NotNil(suite.ui.form, "should keep the form open")
+		suite.Equal(2, suite.ui.form.focus, "should focus the first invalid field")
+		suite.Equal("must have 10 to 15 digits", suite.ui.form.fields[2].err, "should explain the phone")
+		suite.NotEmpty(suite.ui.form.fields[3].err, "should explain the url")
+		suite.Empty(suite.ui.form.err, "should not repeat the errors below the form")
+		suite.Len(suite.ui.clients, 1, "should not add a client")
+		suite.press(keyEsc)
+	})
+}
+
+func (suite *UITestSuit) TestSearch() {
+	// Given
+	suite.addClient("Anna", "Ivanova", "+79990001122")
+	suite.addClient("Oleg", "Petrov", "+79990003344")
+
+	// When
+	suite.press("/", "pet")
+
+	// Then
+	suite.Require().Len(suite.ui.clients, 1, "should filter clients while typing")
+	suite.Equal("Oleg", suite.ui.clients[0].FirstName, "should keep the matching client")
+	suite.Contains(suite.screen(80, 20), "search: pet_", "should show the query")
+
+	// When
+	suite.press(keyEsc)
+
+	// Then
+	suite.Len(suite.ui.clients, 2, "should list all clients after clearing the search")
+}
+
+func (suite *UITestSuit) TestDeleteClient() {
+	// Given
+	suite.addClient("Anna", "Ivanova", "+79990001122")
+
+	// When
+	suite.press("d")
+
+	// Then
+	suite.Contains(suite.screen(80, 20), "Move client Anna Ivanova and all their shoots to the trash? (y/n)",
+		"should ask before deleting")
+
+	// When
+	suite.press("n")
+
+	// Then
+	suite.Len(suite.ui.clients, 1, "should keep the client when not confirmed")
+
+	// When
+	suite.press("d", "y")
+
+	// Then
+	suite.Empty(suite.ui.clients, "should remove the client from the list")
+	suite.Equal("client Anna Ivanova moved to trash", suite.ui.status, "should report the deletion")
+}
+
+func (suite *UITestSuit) TestShootConflict() {
+	// Given
+	suite.addClient("Anna", "Ivanova", "+79990001122")
+	suite.press(keyTab, "a", "20.06.2031", keyTab, "10:00", keyTab, "12:00", keyTab, "5000",
+		keyTab, "Park", keyTab, "portrait", keyEnter)
+	suite.Require().Len(suite.ui.shoots, 1, "should add the first shoot")
+
+	// When
+	suite.press("a", "20.06.2031", keyTab, "11:00", keyTab, "13:00", keyTab, "3000",
+		keyTab, "Studio", keyTab, "family", keyEnter)
+
+	// Then
+	suite.Require().NotNil(suite.ui.form, "should keep the form open on a conflict")
+	suite.Contains(suite.ui.form.err, "#1 20.06.2031 10:00-12:00 Anna Ivanova", "should name the other shoot")
+
+	// When
+	suite.press(keyEnter)
+
+	// Then
+	suite.Nil(suite.ui.form, "should book anyway on the second Enter")
+	suite.Len(suite.ui.shoots, 2, "should list both shoots")
+}
+
+func (suite *UITestSuit) TestEditShoot() {
+	// Given
+	suite.addClient("Anna", "Ivanova", "+79990001122")
+	suite.press(keyTab, "a", "20.06.2031", keyTab, "10:00", keyTab, "12:00", keyTab, "5000",
+		keyTab, "Park", keyTab, "portrait", keyEnter)
+
+	// When
+	suite.press("e", keyUp, "Aquarium", keyEnter)
+
+	// Then
+	shoots, err := suite.shootService.ListShoots(suite.ctx, time.Time{}, time.Time{})
+	suite.NoError(err, "should not return error")
+	suite.Require().Len(shoots, 1, "should keep one shoot")
+	suite.Equal("Aquarium", shoots[0].Notes, "should save the edited notes")
+	suite.Equal("Park", shoots[0].ShootLocation, "should keep the other fields")
+}
+
+func (suite *UITestSuit) TestDetail() {
+	// Given
+	notes := "bring the white backdrop, two reflectors and the spare batteries for the flash"
+	suite.addClient("Anna", "Ivanova", "+79990001122")
+	suite.press(keyTab, "a", "20.06.2031", keyTab, "10:00", keyTab, "12:00", keyTab, "5000",
+		keyTab, "Park", keyTab, "portrait", keyTab, notes, keyEnter)
+
+	// When
+	screen := suite.screen(60, 24)
+
+	// Then
+	for _, word := range strings.Fields(notes) {
+		suite.Contains(screen, word, "should wrap long notes instead of cutting them")
+	}
+	for _, line := range strings.Split(screen, "\n") {
+		suite.LessOrEqual(len([]rune(line)), 60, "should fit every line into the width")
+	}
+}
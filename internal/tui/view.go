@@ -0,0 +1,207 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Coiiap5e/photographer/internal/model"
+)
+
+const (
+	helpList    = "tab pane  ↑↓ move  / search  a add  e edit  d delete  r reload  q quit"
+	helpSearch  = "type to search  enter keep  esc clear"
+	helpForm    = "tab ↑↓ field  enter save  esc cancel"
+	helpConfirm = "y yes  n no"
+)
+
+// view draws the screen as width by height lines. Lines are cut to width
+// before they are styled, so the escape codes do not count.
+func (u *UI) view(width, height int) []string {
+	lines := []string{reverse(pad(u.title(), width))}
+	body := height - 3
+
+	if u.form != nil {
+		lines = append(lines, u.form.lines(width)...)
+	} else {
+		listHeight := max(body*3/5, 3)
+		lines = append(lines, u.panes(width, listHeight)...)
+		lines = append(lines, dim(strings.Repeat("─", width)))
+		lines = append(lines, u.detail(width)...)
+	}
+
+	for len(lines) < height-2 {
+		lines = append(lines, "")
+	}
+	lines = lines[:max(height-2, 1)]
+
+	lines = append(lines, u.statusLine(width), dim(fit(u.help(), width)))
+	return lines
+}
+
+func (u *UI) title() string {
+	title := fmt.Sprintf(" Photographer  clients: %d", len(u.clients))
+	if u.searching || u.query != "" {
+		title += "  search: " + u.query
+		if u.searching {
+			title += "_"
+		}
+	}
+	return title
+}
+
+func (u *UI) help() string {
+	switch {
+	case u.form != nil:
+		return helpForm
+	case u.confirm != nil:
+		return helpConfirm
+	case u.searching:
+		return helpSearch
+	default:
+		return helpList
+	}
+}
+
+func (u *UI) statusLine(width int) string {
+	switch {
+	case u.confirm != nil:
+		return bold(fit(u.confirm.question+" (y/n)", width))
+	case u.statusErr:
+		return red(fit(u.status, width))
+	default:
+		return fit(u.status, width)
+	}
+}
+
+// panes draws the client list and the shoots of the selected client side
+// by side, height lines including the headers
+func (u *UI) panes(width, height int) []string {
+	clientWidth := max(width*2/5, 20)
+	shootWidth := max(width-clientWidth-3, 0)
+
+	clientRows := make([]string, len(u.clients))
+	for i, client := range u.clients {
+		clientRows[i] = fmt.Sprintf("%s %s, %s", client.LastName, client.FirstName, client.Phone)
+	}
+
+	shootRows := make([]string, len(u.shoots))
+	for i, shoot := range u.shoots {
+		shootRows[i] = fmt.Sprintf("%s %s-%s %s · %s", shoot.ShootDate.Format("02.01.2006"),
+			shoot.StartTime.Format("15:04"), shoot.EndTime.Format("15:04"),
+			shoot.ShootType, shoot.ShootLocation)
+	}
+
+	shootsTitle := "Shoots"
+	if client := u.selectedClient(); client != nil {
+		shootsTitle = "Shoots of " + client.FirstName + " " + client.LastName
+	}
+
+	left := list("Clients", clientRows, u.client, &u.clientTop,
+		u.focus == paneClients, clientWidth, height)
+	right := list(shootsTitle, shootRows, u.shoot, &u.shootTop,
+		u.focus == paneShoots, shootWidth, height)
+
+	lines := make([]string, height)
+	for i := range lines {
+		lines[i] = left[i] + dim(" │ ") + right[i]
+	}
+	return lines
+}
+
+// list draws a titled list of rows in width by height. It scrolls top so
+// the selected row is shown, the selected row is highlighted when focused.
+func list(title string, rows []string, selected int, top *int, focused bool,
+	width, height int) []string {
+	lines := []string{bold(pad(title, width))}
+	visible := height - 1
+
+	if len(rows) == 0 {
+		lines = append(lines, dim(pad("  (none)", width)))
+	}
+
+	if selected < *top {
+		*top = selected
+	}
+	if selected >= *top+visible {
+		*top = selected - visible + 1
+	}
+
+	for i := *top; i < len(rows) && len(lines) < height; i++ {
+		if i != selected {
+			lines = append(lines, pad("  "+rows[i], width))
+			continue
+		}
+		row := pad("> "+rows[i], width)
+		if focused {
+			row = reverse(row)
+		}
+		lines = append(lines, row)
+	}
+
+	for len(lines) < height {
+		lines = append(lines, strings.Repeat(" ", width))
+	}
+	return lines
+}
+
+// detail shows every field of the selected client or shoot, long values
+// are wrapped rather than cut
+func (u *UI) detail(width int) []string {
+	if u.focus == paneShoots {
+		if shoot := u.selectedShoot(); shoot != nil {
+			return shootDetail(shoot, width)
+		}
+	}
+	if client := u.selectedClient(); client != nil {
+		return clientDetail(client, len(u.shoots), width)
+	}
+	return []string{dim("No clients yet, press a to add one")}
+}
+
+func clientDetail(client *model.Client, shoots, width int) []string {
+	return details(width,
+		"Client", fmt.Sprintf("#%d %s %s", client.Id, client.FirstName, client.LastName),
+		"Phone", client.Phone,
+		"Social", client.SocialNetworkUrl,
+		"Shoots", fmt.Sprint(shoots),
+		"Created", client.CreatedAt.Format("02.01.2006 15:04"),
+	)
+}
+
+func shootDetail(shoot *model.Shoot, width int) []string {
+	return details(width,
+		"Shoot", fmt.Sprintf("#%d %s %s-%s", shoot.Id, shoot.ShootDate.Format("02.01.2006"),
+			shoot.StartTime.Format("15:04"), shoot.EndTime.Format("15:04")),
+		"Client", shoot.ClientName(),
+		"Type", shoot.ShootType,
+		"Price", shoot.ShootPrice.String(),
+		"Location", shoot.ShootLocation,
+		"Notes", shoot.Notes,
+	)
+}
+
+// details lays out label and value pairs, values wrap under each other
+func details(width int, pairs ...string) []string {
+	labelWidth := 0
+	for i := 0; i < len(pairs); i += 2 {
+		labelWidth = max(labelWidth, utf8.RuneCountInString(pairs[i]))
+	}
+
+	indent := strings.Repeat(" ", labelWidth+2)
+	var lines []string
+	for i := 0; i < len(pairs); i += 2 {
+		label, value := pairs[i], pairs[i+1]
+		if value == "" {
+			continue
+		}
+		for j, line := range wrap(value, width-labelWidth-2) {
+			if j == 0 {
+				lines = append(lines, bold(pad(label, labelWidth))+"  "+line)
+				continue
+			}
+			lines = append(lines, indent+line)
+		}
+	}
+	return lines
+}
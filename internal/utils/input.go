@@ -2,91 +2,458 @@ package utils
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
-	"os"
+	"io"
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/Coiiap5e/photographer/internal/model"
 )
 
-func InputString(prompt string) string {
-	scanner := bufio.NewScanner(os.Stdin)
-	fmt.Printf("%s: ", prompt)
-	scanner.Scan()
-	return scanner.Text()
+// ErrInputClosed is returned by the prompts of a Prompter when the input
+// ends, no answer will ever come
+var ErrInputClosed = errors.New("input closed")
+
+// Prompter asks questions on out and reads the answers line by line from
+// in. All prompts share one reader, so lines piped in ahead of the prompts
+// are not lost.
+type Prompter struct {
+	scanner *bufio.Scanner
+	out     io.Writer
+}
+
+func NewPrompter(in io.Reader, out io.Writer) *Prompter {
+	return &Prompter{scanner: bufio.NewScanner(in), out: out}
+}
+
+func (p *Prompter) Printf(format string, args ...any) {
+	fmt.Fprintf(p.out, format, args...)
+}
+
+func (p *Prompter) Println(args ...any) {
+	fmt.Fprintln(p.out, args...)
+}
+
+// Writer returns where the prompts are written to
+func (p *Prompter) Writer() io.Writer {
+	return p.out
+}
+
+// read returns the next line of input. At the end of the input it returns
+// ErrInputClosed, the retrying prompts would loop forever otherwise.
+func (p *Prompter) read() (string, error) {
+	if !p.scanner.Scan() {
+		p.Println()
+		if err := p.scanner.Err(); err != nil {
+			return "", err
+		}
+		return "", ErrInputClosed
+	}
+	return p.scanner.Text(), nil
+}
+
+// readTrimmed returns the next line of input without surrounding spaces
+func (p *Prompter) readTrimmed() (string, error) {
+	input, err := p.read()
+	return strings.TrimSpace(input), err
+}
+
+func (p *Prompter) Line(prompt string) (string, error) {
+	p.Printf("%s: ", prompt)
+	return p.read()
 }
 
-func InputStringRequired(prompt string) string {
-	scanner := bufio.NewScanner(os.Stdin)
+func (p *Prompter) Required(prompt string) (string, error) {
 	for {
-		fmt.Printf("%s: ", prompt)
-		scanner.Scan()
-		value := strings.TrimSpace(scanner.Text())
+		p.Printf("%s: ", prompt)
+		value, err := p.readTrimmed()
+		if err != nil {
+			return "", err
+		}
 
 		if value != "" {
-			return value
+			return value, nil
 		}
-		fmt.Println("Error: field cannot be empty")
+		p.Println("Error: field cannot be empty")
 	}
 }
 
-func InputInt(prompt string) int {
-	scanner := bufio.NewScanner(os.Stdin)
+// Confirm asks a y/n question until a valid answer is given
+func (p *Prompter) Confirm(prompt string) (bool, error) {
 	for {
-		fmt.Printf("%s: ", prompt)
-		scanner.Scan()
-		value, err := strconv.Atoi(scanner.Text())
-		if err == nil {
-			return value
+		confirm, err := p.Required(prompt + " (y/n)")
+		if err != nil {
+			return false, err
+		}
+		if confirm == "n" || confirm == "N" {
+			return false, nil
+		} else if confirm == "y" || confirm == "Y" {
+			return true, nil
 		}
-		fmt.Println("Error: enter a number")
+		p.Println("Press wrong button: enter (y/n)")
 	}
 }
 
-func InputId(prompt string) int {
-	scanner := bufio.NewScanner(os.Stdin)
+func (p *Prompter) Int(prompt string) (int, error) {
 	for {
-		fmt.Printf("%s: ", prompt)
-		scanner.Scan()
-		value, err := strconv.Atoi(scanner.Text())
-		if err == nil && value > 0 {
-			return value
+		p.Printf("%s: ", prompt)
+		input, err := p.read()
+		if err != nil {
+			return 0, err
 		}
-		fmt.Println("Error: enter number > 0")
+		if value, err := strconv.Atoi(input); err == nil {
+			return value, nil
+		}
+		p.Println("Error: enter a number")
 	}
 }
 
-func InputDate(prompt string) time.Time {
-	scanner := bufio.NewScanner(os.Stdin)
+// Money reads an amount like 1500.50, optionally followed by a
+// currency code. Amounts without one are in currency.
+func (p *Prompter) Money(prompt, currency string) (model.Money, error) {
 	for {
-		fmt.Printf("%s: ", prompt)
-		scanner.Scan()
-		date, err := time.Parse("02.01.2006", scanner.Text())
+		p.Printf("%s, %s: ", prompt, currency)
+		input, err := p.read()
+		if err != nil {
+			return model.Money{}, err
+		}
+		value, err := model.ParseMoney(input, currency)
 		if err == nil {
-			return date
+			return value, nil
 		}
-		fmt.Println("Error: use format dd.mm.yyyy (for example: 20.01.2025)")
+		p.Printf("Error: %v (for example: 1500.50 or 20 USD)\n", err)
 	}
 }
 
-func InputTime(prompt string, date time.Time) time.Time {
-	scanner := bufio.NewScanner(os.Stdin)
+// MoneyOptional reads an amount like Money, empty input returns nil
+func (p *Prompter) MoneyOptional(prompt, currency string) (*model.Money, error) {
 	for {
-		fmt.Printf("%s: ", prompt)
-		scanner.Scan()
-		timeInput := scanner.Text()
-		resultTime, err := time.Parse("02.01.2006 15:04", date.Format("02.01.2006")+" "+timeInput)
+		p.Printf("%s, %s (Enter to skip): ", prompt, currency)
+		input, err := p.readTrimmed()
+		if err != nil || input == "" {
+			return nil, err
+		}
+		value, err := model.ParseMoney(input, currency)
 		if err == nil {
-			return resultTime
+			return &value, nil
+		}
+		p.Printf("Error: %v (for example: 1500.50 or 20 USD)\n", err)
+	}
+}
+
+func (p *Prompter) MoneyDefault(prompt string, current model.Money) (model.Money, error) {
+	for {
+		p.Printf("%s [%s]: ", prompt, current)
+		input, err := p.readTrimmed()
+		if err != nil || input == "" {
+			return current, err
+		}
+		value, err := model.ParseMoney(input, current.Currency)
+		if err == nil {
+			return value, nil
+		}
+		p.Printf("Error: %v (for example: 1500.50 or 20 USD)\n", err)
+	}
+}
+
+func (p *Prompter) Id(prompt string) (int, error) {
+	for {
+		p.Printf("%s: ", prompt)
+		input, err := p.read()
+		if err != nil {
+			return 0, err
+		}
+		if value, err := strconv.Atoi(input); err == nil && value > 0 {
+			return value, nil
+		}
+		p.Println("Error: enter number > 0")
+	}
+}
+
+func (p *Prompter) Date(prompt string) (time.Time, error) {
+	for {
+		p.Printf("%s: ", prompt)
+		input, err := p.read()
+		if err != nil {
+			return time.Time{}, err
+		}
+		if date, err := ParseDate(input); err == nil {
+			return date, nil
+		}
+		p.Println("Error: use format dd.mm.yyyy (for example: 20.01.2025)")
+	}
+}
+
+func (p *Prompter) Time(prompt string, date time.Time) (time.Time, error) {
+	for {
+		p.Printf("%s: ", prompt)
+		input, err := p.read()
+		if err != nil {
+			return time.Time{}, err
+		}
+		if resultTime, err := ParseTime(date, input); err == nil {
+			return resultTime, nil
 		}
-		fmt.Println("Error: use format hh:mm (for example: 15:04)")
+		p.Println("Error: use format hh:mm (for example: 15:04)")
 	}
 }
 
-func InputShootDate() (time.Time, time.Time, time.Time) {
-	date := InputDate("Shoot date")
-	startDate := InputTime("Start time of date", date)
-	endDate := InputTime("End time of date", date)
+// ParseDate parses a date in dd.mm.yyyy format
+func ParseDate(value string) (time.Time, error) {
+	return time.Parse("02.01.2006", strings.TrimSpace(value))
+}
+
+// ParseTime parses a time in hh:mm format on the given date
+func ParseTime(date time.Time, value string) (time.Time, error) {
+	return time.Parse("02.01.2006 15:04", date.Format("02.01.2006")+" "+strings.TrimSpace(value))
+}
+
+// ParseClock parses hh:mm into an offset from midnight
+func ParseClock(value string) (time.Duration, error) {
+	clock, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(clock.Hour())*time.Hour + time.Duration(clock.Minute())*time.Minute, nil
+}
+
+// FormatClock formats an offset from midnight as hh:mm
+func FormatClock(offset time.Duration) string {
+	return time.Time{}.Add(offset).Format("15:04")
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// ParseWeekdays parses a comma separated list of weekdays like "sat,sun"
+func ParseWeekdays(value string) ([]time.Weekday, error) {
+	var days []time.Weekday
+	for _, part := range strings.Split(value, ",") {
+		part = strings.ToLower(strings.TrimSpace(part))
+		if part == "" {
+			continue
+		}
+		if len(part) > 3 {
+			part = part[:3]
+		}
+		day, ok := weekdays[part]
+		if !ok {
+			return nil, fmt.Errorf("unknown weekday %q", part)
+		}
+		days = append(days, day)
+	}
+	return days, nil
+}
+
+// FormatWeekdays is the inverse of ParseWeekdays
+func FormatWeekdays(days []time.Weekday) string {
+	names := make([]string, 0, len(days))
+	for _, day := range days {
+		names = append(names, strings.ToLower(day.String()[:3]))
+	}
+	return strings.Join(names, ",")
+}
+
+// Default shows the current value and keeps it on empty input
+func (p *Prompter) Default(prompt, current string) (string, error) {
+	p.Printf("%s [%s]: ", prompt, current)
+	value, err := p.readTrimmed()
+	if err != nil || value == "" {
+		return current, err
+	}
+	return value, nil
+}
+
+// Clearable works like Default for optional fields, "-" clears the
+// value
+func (p *Prompter) Clearable(prompt, current string) (string, error) {
+	value, err := p.Default(prompt+" (- to clear)", current)
+	if value == "-" {
+		return "", err
+	}
+	return value, err
+}
+
+func (p *Prompter) IntDefault(prompt string, current int) (int, error) {
+	for {
+		p.Printf("%s [%d]: ", prompt, current)
+		input, err := p.readTrimmed()
+		if err != nil || input == "" {
+			return current, err
+		}
+		if value, err := strconv.Atoi(input); err == nil {
+			return value, nil
+		}
+		p.Println("Error: enter a number")
+	}
+}
+
+func (p *Prompter) DateDefault(prompt string, current time.Time) (time.Time, error) {
+	for {
+		p.Printf("%s [%s]: ", prompt, current.Format("02.01.2006"))
+		input, err := p.readTrimmed()
+		if err != nil || input == "" {
+			return current, err
+		}
+		if date, err := ParseDate(input); err == nil {
+			return date, nil
+		}
+		p.Println("Error: use format dd.mm.yyyy (for example: 20.01.2025)")
+	}
+}
+
+// DateOptional reads a date, empty input returns the zero time
+func (p *Prompter) DateOptional(prompt string) (time.Time, error) {
+	for {
+		p.Printf("%s (Enter to skip): ", prompt)
+		input, err := p.readTrimmed()
+		if err != nil || input == "" {
+			return time.Time{}, err
+		}
+		if date, err := ParseDate(input); err == nil {
+			return date, nil
+		}
+		p.Println("Error: use format dd.mm.yyyy (for example: 20.01.2025)")
+	}
+}
+
+// TimeDefault reads a time on date, empty input keeps the clock time
+// of current
+func (p *Prompter) TimeDefault(prompt string, date, current time.Time) (time.Time, error) {
+	for {
+		p.Printf("%s [%s]: ", prompt, current.Format("15:04"))
+		input, err := p.readTrimmed()
+		if err != nil {
+			return current, err
+		}
+		if input == "" {
+			input = current.Format("15:04")
+		}
+		if resultTime, err := ParseTime(date, input); err == nil {
+			return resultTime, nil
+		}
+		p.Println("Error: use format hh:mm (for example: 15:04)")
+	}
+}
+
+// FormatDuration formats d without trailing zero units, e.g. 1h30m
+func FormatDuration(d time.Duration) string {
+	hours := int(d.Hours())
+	minutes := int(d.Minutes()) % 60
+
+	switch {
+	case hours == 0:
+		return fmt.Sprintf("%dm", minutes)
+	case minutes == 0:
+		return fmt.Sprintf("%dh", hours)
+	default:
+		return fmt.Sprintf("%dh%dm", hours, minutes)
+	}
+}
+
+// DurationDefault reads a duration like 1h30m or 90m, empty input
+// keeps current if it is set
+func (p *Prompter) DurationDefault(prompt string, current time.Duration) (time.Duration, error) {
+	for {
+		if current > 0 {
+			p.Printf("%s [%s]: ", prompt, FormatDuration(current))
+		} else {
+			p.Printf("%s: ", prompt)
+		}
+		input, err := p.readTrimmed()
+		if err != nil {
+			return current, err
+		}
+		if input == "" && current > 0 {
+			return current, nil
+		}
+		if value, err := time.ParseDuration(input); err == nil && value >= 0 {
+			return value, nil
+		}
+		p.Println("Error: use format like 1h30m or 90m")
+	}
+}
+
+// ClockDefault reads hh:mm as an offset from midnight
+func (p *Prompter) ClockDefault(prompt string, current time.Duration) (time.Duration, error) {
+	for {
+		p.Printf("%s [%s]: ", prompt, FormatClock(current))
+		input, err := p.readTrimmed()
+		if err != nil || input == "" {
+			return current, err
+		}
+		if value, err := ParseClock(input); err == nil {
+			return value, nil
+		}
+		p.Println("Error: use format hh:mm (for example: 15:04)")
+	}
+}
+
+func (p *Prompter) WeekdaysDefault(prompt string, current []time.Weekday) ([]time.Weekday, error) {
+	for {
+		p.Printf("%s [%s]: ", prompt, FormatWeekdays(current))
+		input, err := p.readTrimmed()
+		if err != nil || input == "" {
+			return current, err
+		}
+		if input == "-" {
+			return nil, nil
+		}
+		if days, err := ParseWeekdays(input); err == nil {
+			return days, nil
+		}
+		p.Println("Error: list weekdays separated by commas (for example: sat,sun), - for none")
+	}
+}
+
+// DateList reads comma separated dates in dd.mm.yyyy format
+func (p *Prompter) DateList(prompt string) ([]time.Time, error) {
+	for {
+		p.Printf("%s: ", prompt)
+		input, err := p.read()
+		if err != nil {
+			return nil, err
+		}
+		if dates, err := ParseDateList(input); err == nil {
+			return dates, nil
+		}
+		p.Println("Error: use format dd.mm.yyyy separated by commas")
+	}
+}
+
+// ParseDateList parses comma separated dates in dd.mm.yyyy format
+func ParseDateList(value string) ([]time.Time, error) {
+	var dates []time.Time
+	for _, part := range strings.Split(value, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		date, err := ParseDate(part)
+		if err != nil {
+			return nil, err
+		}
+		dates = append(dates, date)
+	}
+	return dates, nil
+}
+
+func (p *Prompter) ShootDate() (time.Time, time.Time, time.Time, error) {
+	date, err := p.Date("Shoot date")
+	if err != nil {
+		return time.Time{}, time.Time{}, time.Time{}, err
+	}
+	startDate, err := p.Time("Start time of date", date)
+	if err != nil {
+		return time.Time{}, time.Time{}, time.Time{}, err
+	}
+	endDate, err := p.Time("End time of date", date)
+	if err != nil {
+		return time.Time{}, time.Time{}, time.Time{}, err
+	}
 
-	return date, startDate, endDate
+	return date, startDate, endDate, nil
 }
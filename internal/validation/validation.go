@@ -0,0 +1,257 @@
+// Package validation checks clients and shoots before they are stored. It
+// reports every invalid field at once, so a form can ask again for just
+// the broken ones.
+package validation
+
+import (
+	stderrors "errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Coiiap5e/photographer/internal/errors"
+	"github.com/Coiiap5e/photographer/internal/model"
+)
+
+// Field names, the same as in the HTTP API
+const (
+	FieldFirstName        = "first_name"
+	FieldLastName         = "last_name"
+	FieldPhone            = "phone"
+	FieldSocialNetworkUrl = "social_network_url"
+	FieldClientId         = "client_id"
+	FieldDate             = "date"
+	FieldStartTime        = "start_time"
+	FieldEndTime          = "end_time"
+	FieldShootPrice       = "shoot_price"
+	FieldLocation         = "location"
+	FieldShootType        = "shoot_type"
+)
+
+// MaxPast is how far back a shoot date may be set, older dates are taken
+// for typos
+const MaxPast = 1 // year
+
+// FieldError says what is wrong with one field
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Errors lists the invalid fields of a client or a shoot. It is wrapped
+// into an AppError with ErrCodeValidation.
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	parts := make([]string, len(e))
+	for i, field := range e {
+		parts[i] = field.Field + ": " + field.Message
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Message returns what is wrong with field, empty if it is valid
+func (e Errors) Message(field string) string {
+	for _, fieldErr := range e {
+		if fieldErr.Field == field {
+			return fieldErr.Message
+		}
+	}
+	return ""
+}
+
+// Fields returns the invalid fields err carries, nil if it carries none
+func Fields(err error) Errors {
+	var fields Errors
+	if stderrors.As(err, &fields) {
+		return fields
+	}
+	return nil
+}
+
+type checker struct {
+	errs Errors
+}
+
+func (c *checker) add(field, message string) {
+	if c.errs.Message(field) == "" {
+		c.errs = append(c.errs, FieldError{Field: field, Message: message})
+	}
+}
+
+func (c *checker) result(what string) error {
+	if len(c.errs) == 0 {
+		return nil
+	}
+	return errors.Wrap(c.errs, errors.ErrCodeValidation, "invalid "+what)
+}
+
+// Client checks a new client
+func Client(client *model.Client) error {
+	c := &checker{}
+	c.required(FieldFirstName, client.FirstName, 100)
+	c.required(FieldLastName, client.LastName, 100)
+	c.phone(client.Phone)
+	c.url(client.SocialNetworkUrl)
+	return c.result("client")
+}
+
+// ClientUpdate checks the fields an update changes
+func ClientUpdate(update *model.ClientUpdate) error {
+	c := &checker{}
+	if update.FirstName != nil {
+		c.required(FieldFirstName, *update.FirstName, 100)
+	}
+	if update.LastName != nil {
+		c.required(FieldLastName, *update.LastName, 100)
+	}
+	if update.Phone != nil {
+		c.phone(*update.Phone)
+	}
+	if update.SocialNetworkUrl != nil {
+		c.url(*update.SocialNetworkUrl)
+	}
+	return c.result("client")
+}
+
+// Shoot checks a new shoot, its date is compared with today
+func Shoot(shoot *model.Shoot, today time.Time) error {
+	c := &checker{}
+	c.client(shoot.ClientId)
+	c.date(shoot.ShootDate, today)
+	c.times(shoot.StartTime, shoot.EndTime)
+	c.price(shoot.ShootPrice)
+	c.required(FieldLocation, shoot.ShootLocation, 255)
+	c.required(FieldShootType, shoot.ShootType, 100)
+	return c.result("shoot")
+}
+
+// ShootUpdate checks the fields an update changes. The times are checked
+// together with the one current keeps when only one of them changes.
+func ShootUpdate(update *model.ShootUpdate, current *model.Shoot, today time.Time) error {
+	c := &checker{}
+	if update.ClientId != nil {
+		c.client(*update.ClientId)
+	}
+	if update.ShootDate != nil {
+		c.date(*update.ShootDate, today)
+	}
+	if update.StartTime != nil || update.EndTime != nil {
+		start, end := current.StartTime, current.EndTime
+		if update.StartTime != nil {
+			start = *update.StartTime
+		}
+		if update.EndTime != nil {
+			end = *update.EndTime
+		}
+		c.times(start, end)
+	}
+	if update.ShootPrice != nil {
+		c.price(*update.ShootPrice)
+	}
+	if update.ShootLocation != nil {
+		c.required(FieldLocation, *update.ShootLocation, 255)
+	}
+	if update.ShootType != nil {
+		c.required(FieldShootType, *update.ShootType, 100)
+	}
+	return c.result("shoot")
+}
+
+func (c *checker) required(field, value string, limit int) {
+	if strings.TrimSpace(value) == "" {
+		c.add(field, "must not be empty")
+		return
+	}
+	c.length(field, value, limit)
+}
+
+func (c *checker) length(field, value string, limit int) {
+	if utf8.RuneCountInString(value) > limit {
+		c.add(field, "must be at most "+strconv.Itoa(limit)+" characters")
+	}
+}
+
+// phone accepts digits with an optional leading + and the usual
+// separators, like +7(900)000-00-00 or 8 900 000 00 00
+func (c *checker) phone(value string) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		c.add(FieldPhone, "must not be empty")
+		return
+	}
+
+	digits := 0
+	for i, r := range value {
+		switch {
+		case r >= '0' && r <= '9':
+			digits++
+		case r == '+' && i == 0:
+		case strings.ContainsRune(" ()-.", r):
+		default:
+			c.add(FieldPhone, "may contain only digits, a leading + and ( ) - . separators")
+			return
+		}
+	}
+
+	if digits < 10 || digits > 15 {
+		c.add(FieldPhone, "must have 10 to 15 digits")
+	}
+}
+
+// url accepts an empty value or a web address, the scheme may be left out
+// as in vk.com/name
+func (c *checker) url(value string) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return
+	}
+	c.length(FieldSocialNetworkUrl, value, 500)
+
+	if !strings.Contains(value, "://") {
+		value = "https://" + value
+	}
+	parsed, err := url.Parse(value)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") ||
+		!strings.Contains(parsed.Hostname(), ".") || strings.ContainsAny(value, " \t") {
+		c.add(FieldSocialNetworkUrl, "must be a web address like https://vk.com/name")
+	}
+}
+
+func (c *checker) client(id int) {
+	if id <= 0 {
+		c.add(FieldClientId, "must be set")
+	}
+}
+
+func (c *checker) date(date, today time.Time) {
+	if date.IsZero() {
+		c.add(FieldDate, "must not be empty")
+		return
+	}
+	limit := time.Date(today.Year()-MaxPast, today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	if day.Before(limit) {
+		c.add(FieldDate, "must not be more than a year in the past")
+	}
+}
+
+// times rejects a shoot that ends when it starts. A shoot that ends before
+// its start time ends on the next day, see model.Shoot.Interval.
+func (c *checker) times(start, end time.Time) {
+	if start.Hour() == end.Hour() && start.Minute() == end.Minute() {
+		c.add(FieldEndTime, "must be after the start time")
+	}
+}
+
+func (c *checker) price(price model.Money) {
+	if !model.ValidCurrency(price.Currency) {
+		c.add(FieldShootPrice, "has invalid currency \""+price.Currency+"\"")
+		return
+	}
+	if price.Amount < 0 {
+		c.add(FieldShootPrice, "must be >= 0")
+	}
+}
@@ -0,0 +1,210 @@
+package validation
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Coiiap5e/photographer/internal/errors"
+	"github.com/Coiiap5e/photographer/internal/model"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestValidationTestSuit(t *testing.T) {
+	suite.Run(t, new(ValidationTestSuit))
+}
+
+type ValidationTestSuit struct {
+	suite.Suite
+	today time.Time
+}
+
+func (suite *ValidationTestSuit) SetupTest() {
+	suite.today = time.Date(2025, 1, 20, 15, 0, 0, 0, time.UTC)
+}
+
+func clock(value string) time.Time {
+	t, _ := time.Parse("15:04", value)
+	return t
+}
+
+func (suite *ValidationTestSuit) shoot() *model.Shoot {
+	return &model.Shoot{ClientId: 1, ShootDate: suite.today, StartTime: clock("10:00"), EndTime: clock("12:00"),
+		ShootPrice: model.NewMoney(500000, "RUB"), ShootLocation: "Park", ShootType: "portrait"}
+}
+
+func (suite *ValidationTestSuit) TestClient() {
+	suite.T().Run("should accept a valid client", func(t *testing.T) {
+		for _, client := range []model.Client{
+			{FirstName: "Анна", LastName: "Иванова", Phone: "+7(900)000-00-00"},
+			{FirstName: "Ivan", LastName: "Ivanov", Phone: "8 900 000 00 00", SocialNetworkUrl: "vk.com/ivan"},
+			{FirstName: "Ivan", LastName: "Ivanov", Phone: "89000000000",
+				SocialNetworkUrl: "https://instagram.com/ivan?igsh=1"},
+		} {
+			suite.NoError(Client(&client), "should accept %+v", client)
+		}
+	})
+	suite.T().Run("should report every invalid field", func(t *testing.T) {
+		// Given
+		client := &model.Client{FirstName: " ", LastName: strings.Repeat("я", 101),
+			Phone: "+7 900 CALL ME", SocialNetworkUrl: "ftp://files.example.com"}
+
+		// When
+		err := Client(client)
+
+		// Then
+		suite.True(errors.IsErrorCode(err, errors.ErrCodeValidation), "should return validation error")
+		suite.Equal(Errors{
+			{Field: FieldFirstName, Message: "must not be empty"},
+			{Field: FieldLastName, Message: "must be at most 100 characters"},
+			{Field: FieldPhone, Message: "may contain only digits, a leading + and ( ) - . separators"},
+			{Field: FieldSocialNetworkUrl, Message: "must be a web address like https://vk.com/name"},
+		}, Fields(err), "should list the fields in order")
+	})
+	suite.T().Run("should reject a phone with too few or too many digits", func(t *testing.T) {
+		for _, phone := range []string{"12-34-56", "+1234567890123456", "7+9000000000"} {
+			suite.NotEmpty(Fields(Client(&model.Client{FirstName: "Ivan", LastName: "Ivanov",
+				Phone: phone})).Message(FieldPhone), "should reject %q", phone)
+		}
+	})
+	suite.T().Run("should reject a url without a host", func(t *testing.T) {
+		for _, url := range []string{"vk", "my page", "https://"} {
+			suite.NotEmpty(Fields(Client(&model.Client{FirstName: "Ivan", LastName: "Ivanov",
+				Phone: "89000000000", SocialNetworkUrl: url})).Message(FieldSocialNetworkUrl),
+				"should reject %q", url)
+		}
+	})
+}
+
+func (suite *ValidationTestSuit) TestClientUpdate() {
+	// Given
+	empty, phone := "", "12345"
+
+	// When
+	err := ClientUpdate(&model.ClientUpdate{SocialNetworkUrl: &empty, Phone: &phone})
+
+	// Then
+	suite.Equal(Errors{{Field: FieldPhone, Message: "must have 10 to 15 digits"}}, Fields(err),
+		"should check only the changed fields and allow clearing the url")
+	suite.NoError(ClientUpdate(&model.ClientUpdate{}), "should accept an empty update")
+}
+
+func (suite *ValidationTestSuit) TestShoot() {
+	suite.T().Run("should accept a valid shoot", func(t *testing.T) {
+		suite.NoError(Shoot(suite.shoot(), suite.today), "should accept the shoot")
+	})
+	suite.T().Run("should accept a shoot that ends after midnight", func(t *testing.T) {
+		// Given
+		shoot := suite.shoot()
+		shoot.StartTime, shoot.EndTime = clock("22:00"), clock("01:00")
+
+		// When
+		err := Shoot(shoot, suite.today)
+
+		// Then
+		suite.NoError(err, "should take the end for the next day")
+	})
+	suite.T().Run("should accept a date up to a year ago", func(t *testing.T) {
+		// Given
+		shoot := suite.shoot()
+		shoot.ShootDate = time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC)
+
+		// When
+		err := Shoot(shoot, suite.today)
+
+		// Then
+		suite.NoError(err, "should accept the same day a year ago")
+	})
+	suite.T().Run("should report every invalid field", func(t *testing.T) {
+		// Given
+		shoot := suite.shoot()
+		shoot.ShootDate = time.Date(2024, 1, 19, 0, 0, 0, 0, time.UTC)
+		shoot.EndTime = clock("10:00")
+		shoot.ShootPrice = model.NewMoney(-1, "RUB")
+		shoot.ShootType = strings.Repeat("x", 101)
+
+		// When
+		err := Shoot(shoot, suite.today)
+
+		// Then
+		suite.True(errors.IsErrorCode(err, errors.ErrCodeValidation), "should return validation error")
+		suite.Equal(Errors{
+			{Field: FieldDate, Message: "must not be more than a year in the past"},
+			{Field: FieldEndTime, Message: "must be after the start time"},
+			{Field: FieldShootPrice, Message: "must be >= 0"},
+			{Field: FieldShootType, Message: "must be at most 100 characters"},
+		}, Fields(err), "should list the fields in order")
+		suite.Equal("invalid shoot", err.(*errors.AppError).Message, "should name what is invalid")
+	})
+	suite.T().Run("should report every missing required field", func(t *testing.T) {
+		// Given
+		shoot := suite.shoot()
+		shoot.ClientId, shoot.ShootLocation, shoot.ShootType = 0, " ", ""
+
+		// When
+		err := Shoot(shoot, suite.today)
+
+		// Then
+		suite.Equal(Errors{
+			{Field: FieldClientId, Message: "must be set"},
+			{Field: FieldLocation, Message: "must not be empty"},
+			{Field: FieldShootType, Message: "must not be empty"},
+		}, Fields(err), "should list the missing fields")
+	})
+	suite.T().Run("should reject an unknown currency", func(t *testing.T) {
+		// Given
+		shoot := suite.shoot()
+		shoot.ShootPrice = model.NewMoney(100, "rubles")
+
+		// When
+		err := Shoot(shoot, suite.today)
+
+		// Then
+		suite.Equal(`has invalid currency "rubles"`, Fields(err).Message(FieldShootPrice),
+			"should name the currency")
+	})
+}
+
+func (suite *ValidationTestSuit) TestShootUpdate() {
+	suite.T().Run("should check a changed time against the current one", func(t *testing.T) {
+		// Given
+		current := suite.shoot()
+		start := clock("12:00")
+
+		// When
+		err := ShootUpdate(&model.ShootUpdate{StartTime: &start}, current, suite.today)
+
+		// Then
+		suite.NotEmpty(Fields(err).Message(FieldEndTime), "should reject the shoot ending when it starts")
+	})
+	suite.T().Run("should not clear a required field", func(t *testing.T) {
+		// Given
+		location, clientId := "", 0
+
+		// When
+		err := ShootUpdate(&model.ShootUpdate{ShootLocation: &location, ClientId: &clientId}, nil, suite.today)
+
+		// Then
+		suite.Equal(Errors{
+			{Field: FieldClientId, Message: "must be set"},
+			{Field: FieldLocation, Message: "must not be empty"},
+		}, Fields(err), "should reject the empty fields")
+	})
+	suite.T().Run("should not need the current shoot when the times stay", func(t *testing.T) {
+		// Given
+		date := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		// When
+		err := ShootUpdate(&model.ShootUpdate{ShootDate: &date}, nil, suite.today)
+
+		// Then
+		suite.Equal(Errors{{Field: FieldDate, Message: "must not be more than a year in the past"}},
+			Fields(err), "should check only the date")
+	})
+}
+
+func (suite *ValidationTestSuit) TestFields() {
+	suite.Nil(Fields(nil), "should return nil without an error")
+	suite.Nil(Fields(errors.New(errors.ErrCodeValidation, "unknown sort")),
+		"should return nil for errors without fields")
+}
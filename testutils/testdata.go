@@ -29,7 +29,7 @@ func CreateTestShoot(clientID int) *model2.Shoot {
 		ShootDate:     time.Now().AddDate(0, 0, 30),
 		StartTime:     time.Date(0, 0, 0, 15, 0, 0, 0, time.UTC),
 		EndTime:       time.Date(0, 0, 0, 16, 0, 0, 0, time.UTC),
-		ShootPrice:    1000,
+		ShootPrice:    model2.NewMoney(100000, "RUB"),
 		ShootLocation: "Pushkin blvd",
 		ShootType:     "love story",
 		Notes:         "take an umbrella",
@@ -7,6 +7,7 @@ import (
 
 	"github.com/Coiiap5e/photographer/internal/database"
 	"github.com/Coiiap5e/photographer/internal/errors"
+	"github.com/Coiiap5e/photographer/internal/migrate"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/wait"
@@ -70,43 +71,14 @@ func SetupTestDB(ctx context.Context) (*TestDB, error) {
 	return testDB, nil
 }
 
+// InitSchema applies the same migrations the application ships with.
 func (tdb *TestDB) InitSchema(ctx context.Context) error {
-
-	_, err := tdb.Pool.Exec(ctx, `
-		CREATE TABLE IF NOT EXISTS clients (
-			id SERIAL PRIMARY KEY,
-			first_name VARCHAR(100) NOT NULL,
-			last_name VARCHAR(100) NOT NULL,
-			phone VARCHAR(100),
-			social_network_url VARCHAR(500),
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-		)
-	`)
+	migrator, err := migrate.New(tdb.GetDB())
 	if err != nil {
-		return errors.Wrap(err, errors.ErrCodeClientCreate, "failed to create clients table")
+		return err
 	}
 
-	_, err = tdb.Pool.Exec(ctx, `
-		CREATE TABLE IF NOT EXISTS shoots (
-			id SERIAL PRIMARY KEY,
-			client_id INTEGER NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
-			date DATE NOT NULL,
-			start_time TIME,
-			end_time TIME,
-			shoot_price DECIMAL(10,0),
-			location VARCHAR(255),
-			client_first_name VARCHAR(255),
-			client_last_name VARCHAR(255),
-			shoot_type VARCHAR(100),
-			notes TEXT,
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-		)
-	`)
-	if err != nil {
-		return errors.Wrap(err, errors.ErrCodeShootCreate, "failed to create shoots table")
-	}
-
-	return nil
+	return migrator.Up(ctx)
 }
 
 func (tdb *TestDB) Cleanup(ctx context.Context) error {
@@ -125,7 +97,7 @@ func (tdb *TestDB) Cleanup(ctx context.Context) error {
 }
 
 func (tdb *TestDB) CleanTables(ctx context.Context) error {
-	_, err := tdb.Pool.Exec(ctx, "TRUNCATE TABLE shoots, clients RESTART IDENTITY CASCADE")
+	_, err := tdb.Pool.Exec(ctx, "TRUNCATE TABLE audit_log, payments, shoots, clients RESTART IDENTITY CASCADE")
 	if err != nil {
 		return errors.Wrap(err, errors.ErrCodeDBDelete, "failed to clean tables")
 	}